@@ -15,15 +15,25 @@ func RegisterAlertTools(s *server.MCPServer, client *gobizfly.Client) {
 	// List alarms tool
 	listAlarmsTool := mcp.NewTool("bizflycloud_list_alarms",
 		mcp.WithDescription("List all Bizfly Cloud alarms"),
+		withCommonOptions(),
+		withListOptions(),
 	)
 	s.AddTool(listAlarmsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		alarms, err := client.CloudWatcher.Alarms().List(ctx, nil)
+		services := servicesFromContext(ctx, client)
+		query, err := parseListQuery(request.Params.Arguments)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list alarms: %v", err)), nil
+			return errorResult(ctx, "Failed to list alarms", err), nil
 		}
+		alarms, err := services.Alerts.ListAlarms(ctx, nil)
+		if err != nil {
+			return errorResult(ctx, "Failed to list alarms", err), nil
+		}
+		alarms, page := pageItems(query, alarms, alarmListFields)
 
 		result := "Available alarms:\n\n"
+		items := []AlarmOutput{}
 		for _, alarm := range alarms {
+			items = append(items, newAlarmOutput(alarm))
 			result += fmt.Sprintf("Alarm: %s\n", alarm.Name)
 			result += fmt.Sprintf("  ID: %s\n", alarm.ID)
 			result += fmt.Sprintf("  Resource Type: %s\n", alarm.ResourceType)
@@ -32,25 +42,27 @@ func RegisterAlertTools(s *server.MCPServer, client *gobizfly.Client) {
 			result += fmt.Sprintf("  Created At: %s\n", alarm.Created)
 			result += "\n"
 		}
-		return mcp.NewToolResultText(result), nil
+		return pageResult(request, result, "alarms", items, page), nil
 	})
 
 	// Get alarm tool
 	getAlarmTool := mcp.NewTool("bizflycloud_get_alarm",
 		mcp.WithDescription("Get details of a Bizfly Cloud alarm"),
+		withCommonOptions(),
 		mcp.WithString("alarm_id",
 			mcp.Required(),
 			mcp.Description("ID of the alarm"),
 		),
 	)
 	s.AddTool(getAlarmTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		alarmID, ok := request.Params.Arguments["alarm_id"].(string)
 		if !ok {
 			return nil, errors.New("alarm_id must be a string")
 		}
-		alarm, err := client.CloudWatcher.Alarms().Get(ctx, alarmID)
+		alarm, err := services.Alerts.GetAlarm(ctx, alarmID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get alarm: %v", err)), nil
+			return errorResult(ctx, "Failed to get alarm", err), nil
 		}
 
 		result := fmt.Sprintf("Alarm Details:\n\n")
@@ -66,21 +78,31 @@ func RegisterAlertTools(s *server.MCPServer, client *gobizfly.Client) {
 				result += fmt.Sprintf("  - %s (ID: %s)\n", receiver.Name, receiver.ReceiverID)
 			}
 		}
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newAlarmOutput(alarm)), nil
 	})
 
 	// List receivers tool
 	listReceiversTool := mcp.NewTool("bizflycloud_list_receivers",
 		mcp.WithDescription("List all Bizfly Cloud alert receivers"),
+		withCommonOptions(),
+		withListOptions(),
 	)
 	s.AddTool(listReceiversTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		receivers, err := client.CloudWatcher.Receivers().List(ctx, nil)
+		services := servicesFromContext(ctx, client)
+		query, err := parseListQuery(request.Params.Arguments)
+		if err != nil {
+			return errorResult(ctx, "Failed to list receivers", err), nil
+		}
+		receivers, err := services.Alerts.ListReceivers(ctx, nil)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list receivers: %v", err)), nil
+			return errorResult(ctx, "Failed to list receivers", err), nil
 		}
+		receivers, page := pageItems(query, receivers, receiverListFields)
 
 		result := "Available receivers:\n\n"
+		items := []ReceiverOutput{}
 		for _, receiver := range receivers {
+			items = append(items, newReceiverOutput(receiver))
 			result += fmt.Sprintf("Receiver: %s\n", receiver.Name)
 			result += fmt.Sprintf("  ID: %s\n", receiver.ReceiverID)
 			if receiver.EmailAddress != "" {
@@ -96,25 +118,27 @@ func RegisterAlertTools(s *server.MCPServer, client *gobizfly.Client) {
 			result += fmt.Sprintf("  Created At: %s\n", receiver.Created)
 			result += "\n"
 		}
-		return mcp.NewToolResultText(result), nil
+		return pageResult(request, result, "receivers", items, page), nil
 	})
 
 	// Get receiver tool
 	getReceiverTool := mcp.NewTool("bizflycloud_get_receiver",
 		mcp.WithDescription("Get details of a Bizfly Cloud alert receiver"),
+		withCommonOptions(),
 		mcp.WithString("receiver_id",
 			mcp.Required(),
 			mcp.Description("ID of the receiver"),
 		),
 	)
 	s.AddTool(getReceiverTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		receiverID, ok := request.Params.Arguments["receiver_id"].(string)
 		if !ok {
 			return nil, errors.New("receiver_id must be a string")
 		}
-		receiver, err := client.CloudWatcher.Receivers().Get(ctx, receiverID)
+		receiver, err := services.Alerts.GetReceiver(ctx, receiverID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get receiver: %v", err)), nil
+			return errorResult(ctx, "Failed to get receiver", err), nil
 		}
 
 		result := fmt.Sprintf("Receiver Details:\n\n")
@@ -138,7 +162,21 @@ func RegisterAlertTools(s *server.MCPServer, client *gobizfly.Client) {
 			result += fmt.Sprintf("Verified: %v\n", receiver.VerifiedTelegramChatID)
 		}
 		result += fmt.Sprintf("Created At: %s\n", receiver.Created)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newReceiverOutput(receiver)), nil
 	})
 }
 
+
+// alarmListFields are the fields list_alarms filters and sorts on; an alarm's status is enabled or disabled
+func alarmListFields(alarm *gobizfly.Alarms) listFields {
+	status := "disabled"
+	if alarm.Enable {
+		status = "enabled"
+	}
+	return listFields{Name: alarm.Name, Status: status, CreatedAt: alarm.Created}
+}
+
+// receiverListFields are the fields list_receivers filters and sorts on
+func receiverListFields(receiver *gobizfly.Receivers) listFields {
+	return listFields{Name: receiver.Name, CreatedAt: receiver.Created}
+}
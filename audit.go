@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bizflycloud/gobizfly/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	auditSinkStdout = "stdout"
+	auditSinkStderr = "stderr"
+
+	defaultAuditMaxSizeMB  = 100
+	defaultAuditMaxBackups = 5
+
+	// redactedValue replaces secret arguments in audit records
+	redactedValue = "[REDACTED]"
+
+	// maxAuditErrorLength caps the error message stored in an audit record
+	maxAuditErrorLength = 500
+)
+
+// Audit outcomes
+const (
+	auditOutcomeSuccess              = "success"
+	auditOutcomeError                = "error"
+	auditOutcomeDryRun               = "dry_run"
+	auditOutcomeConfirmationRequired = "confirmation_required"
+)
+
+// secretArgMarkers match argument names whose values never reach the audit log
+var secretArgMarkers = []string{"password", "passphrase_payload", "private_key_payload", "secret", "token", "credential"}
+
+// resultIDPattern finds the "ID: ..." lines a create tool reports in text mode for the resources it made
+var resultIDPattern = regexp.MustCompile(`(?m)^\s*ID: (\S+)\s*$`)
+
+// AuditRecord is one JSON line of the audit log
+type AuditRecord struct {
+	Time        string                 `json:"time"`
+	Tool        string                 `json:"tool"`
+	Session     string                 `json:"session,omitempty"`
+	Profile     string                 `json:"profile"`
+	Region      string                 `json:"region"`
+	Arguments   map[string]interface{} `json:"arguments"`
+	DurationMS  int64                  `json:"duration_ms"`
+	Outcome     string                 `json:"outcome"`
+	Error       string                 `json:"error,omitempty"`
+	ResourceIDs []string               `json:"resource_ids,omitempty"`
+	Retries     int64                  `json:"retries,omitempty"`
+}
+
+// AuditLogger writes an audit record for every tool call
+type AuditLogger struct {
+	mu     sync.Mutex
+	out    io.Writer
+	config *Config
+	now    func() time.Time
+}
+
+// NewAuditLogger creates an audit logger writing JSON lines to out. The config
+// resolves the profile and region a call ran against when it didn't name them.
+func NewAuditLogger(out io.Writer, config *Config) *AuditLogger {
+	return &AuditLogger{out: out, config: config, now: time.Now}
+}
+
+// Middleware records every tool call, including calls refused by the middleware it wraps
+func (a *AuditLogger) Middleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := a.now()
+			ctx, retries := withRetryCounter(ctx)
+			result, err := next(ctx, request)
+			a.record(ctx, request, start, result, err, retries.Load())
+			return result, err
+		}
+	}
+}
+
+// record writes the audit record of a finished tool call
+func (a *AuditLogger) record(ctx context.Context, request mcp.CallToolRequest, start time.Time, result *mcp.CallToolResult, err error, retries int64) {
+	name := request.Params.Name
+	args := request.Params.Arguments
+	profile, region := a.target(args)
+
+	record := AuditRecord{
+		Time:        start.UTC().Format(time.RFC3339Nano),
+		Tool:        name,
+		Profile:     profile,
+		Region:      region,
+		Arguments:   redactArguments(args),
+		DurationMS:  a.now().Sub(start).Milliseconds(),
+		Outcome:     auditOutcome(request, result, err),
+		ResourceIDs: resourceIDs(name, args, result),
+		Retries:     retries,
+	}
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		record.Session = session.SessionID()
+	}
+	if err != nil {
+		record.Error = truncate(err.Error(), maxAuditErrorLength)
+	} else if result != nil && result.IsError {
+		record.Error = truncate(resultText(result), maxAuditErrorLength)
+	}
+
+	data, marshalErr := json.Marshal(record)
+	if marshalErr != nil {
+		log.Printf("[WARN] Failed to encode audit record for %s: %v", name, marshalErr)
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, writeErr := a.out.Write(append(data, '\n')); writeErr != nil {
+		log.Printf("[WARN] Failed to write audit record for %s: %v", name, writeErr)
+	}
+}
+
+// target resolves the profile and region a call ran against, falling back to the defaults
+func (a *AuditLogger) target(args map[string]interface{}) (string, string) {
+	profileName, _ := args["profile"].(string)
+	region, _ := args["region"].(string)
+	if a.config == nil {
+		return profileName, region
+	}
+	profile, err := a.config.Profile(profileName)
+	if err != nil {
+		return profileName, region
+	}
+	if region == "" {
+		region = profile.Region
+	}
+	if parsed, err := utils.ParseRegionName(region); err == nil {
+		region = parsed
+	}
+	return profile.Name, region
+}
+
+// auditOutcome classifies a finished tool call
+func auditOutcome(request mcp.CallToolRequest, result *mcp.CallToolResult, err error) string {
+	switch {
+	case err != nil || (result != nil && result.IsError):
+		return auditOutcomeError
+	case isDryRun(request):
+		return auditOutcomeDryRun
+	case isDestructiveTool(request.Params.Name):
+		if token, _ := request.Params.Arguments[confirmationTokenArg].(string); token == "" {
+			return auditOutcomeConfirmationRequired
+		}
+	}
+	return auditOutcomeSuccess
+}
+
+// resourceIDs lists the resources a call touched: the IDs it was given and,
+// for tools that change infrastructure, the IDs reported in its result. A JSON
+// result reports them as its id or, for an ActionOutput, its resource_id.
+func resourceIDs(tool string, args map[string]interface{}, result *mcp.CallToolResult) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for key, value := range args {
+		if id, ok := value.(string); ok && strings.HasSuffix(key, "_id") {
+			add(id)
+		}
+	}
+	if result == nil || result.IsError || isReadOnlyTool(tool) {
+		return ids
+	}
+	text := resultText(result)
+	var structured map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &structured); err == nil {
+		for _, key := range []string{"id", "resource_id"} {
+			if id, ok := structured[key].(string); ok {
+				add(id)
+			}
+		}
+		return ids
+	}
+	for _, match := range resultIDPattern.FindAllStringSubmatch(text, -1) {
+		add(match[1])
+	}
+	return ids
+}
+
+// redactArguments copies the call arguments with secret values replaced
+func redactArguments(args map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		if isSecretArg(key) {
+			redacted[key] = redactedValue
+			continue
+		}
+		redacted[key] = redactValue(value)
+	}
+	return redacted
+}
+
+// redactValue copies an argument value with the secrets of the objects it holds replaced
+func redactValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return redactArguments(value)
+	case []interface{}:
+		items := make([]interface{}, len(value))
+		for i, item := range value {
+			items[i] = redactValue(item)
+		}
+		return items
+	}
+	return value
+}
+
+// isSecretArg reports whether an argument holds a secret such as a private key or password
+func isSecretArg(key string) bool {
+	key = strings.ToLower(key)
+	for _, marker := range secretArgMarkers {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// resultText joins the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
+
+// OpenAuditSink opens the destination of the audit log: stdout, stderr or a
+// file that is rotated once it grows past maxSizeMB, keeping maxBackups old files
+func OpenAuditSink(target string, maxSizeMB, maxBackups int) (io.WriteCloser, error) {
+	switch target {
+	case auditSinkStdout:
+		return nopWriteCloser{os.Stdout}, nil
+	case auditSinkStderr:
+		return nopWriteCloser{os.Stderr}, nil
+	}
+	if maxSizeMB < 0 || maxBackups < 0 {
+		return nil, fmt.Errorf("audit log size and backups must not be negative")
+	}
+	return openRotatingFile(target, int64(maxSizeMB)*1024*1024, maxBackups)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// rotatingFile is an append-only file that is renamed to path.1, path.2, ...
+// once it would grow past maxBytes. A maxBytes of 0 disables rotation.
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func openRotatingFile(path string, maxBytes int64, maxBackups int) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+	if err := r.open(os.O_APPEND); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open(mode int) error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|mode, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first if p would take the file past its maximum
+// size. When rotation fails p is appended to the current file anyway.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.maxBytes > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			log.Printf("[WARN] %v; appending to %s past its maximum size", err, r.path)
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return r.reopen(fmt.Errorf("failed to rotate audit log: %w", err))
+	}
+	if r.maxBackups > 0 {
+		os.Remove(r.backupPath(r.maxBackups))
+		for i := r.maxBackups - 1; i >= 1; i-- {
+			os.Rename(r.backupPath(i), r.backupPath(i+1))
+		}
+		if err := os.Rename(r.path, r.backupPath(1)); err != nil {
+			return r.reopen(fmt.Errorf("failed to rotate audit log: %w", err))
+		}
+	}
+	if err := r.open(os.O_TRUNC); err != nil {
+		return r.reopen(err)
+	}
+	return nil
+}
+
+// reopen opens the file at path for appending again after a failed rotation,
+// so the file closed for it doesn't stay closed, and returns the rotation error
+func (r *rotatingFile) reopen(rotateErr error) error {
+	if err := r.open(os.O_APPEND); err != nil {
+		return errors.Join(rotateErr, err)
+	}
+	return rotateErr
+}
+
+func (r *rotatingFile) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", r.path, i)
+}
+
+// Close closes the current file
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
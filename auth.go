@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bizflycloud/gobizfly"
+)
+
+// Supported authentication methods
+const (
+	authMethodPassword      = "password"
+	authMethodAppCredential = "application_credential"
+	authMethodTokenFile     = "token_file"
+	authMethodSecretsFile   = "secrets_file"
+)
+
+// Credentials describe how the server authenticates against Bizfly Cloud
+type Credentials struct {
+	Method              string
+	Username            string
+	Password            string
+	AppCredentialID     string
+	AppCredentialSecret string
+	ProjectID           string
+	TokenFile           string
+	// Source names where the credentials were read from, for log messages
+	Source string
+}
+
+// LoadCredentials resolves the authentication method and its credentials.
+//
+// BIZFLY_AUTH_METHOD selects the method explicitly; when it is unset the
+// method is inferred from whichever credentials are present. With
+// BIZFLY_SECRETS_FILE the credentials are read from a mounted secrets file (a
+// KEY=VALUE file or a directory with one file per key) instead of plain
+// environment variables.
+func LoadCredentials(getenv func(string) string) (*Credentials, error) {
+	return loadCredentials(getenv, "environment")
+}
+
+// loadCredentials resolves credentials from any BIZFLY_* lookup, such as a
+// config file profile; source names it in error messages
+func loadCredentials(getenv func(string) string, source string) (*Credentials, error) {
+	lookup := getenv
+
+	method := strings.ToLower(strings.TrimSpace(getenv("BIZFLY_AUTH_METHOD")))
+	secretsFile := getenv("BIZFLY_SECRETS_FILE")
+	if method == authMethodSecretsFile && secretsFile == "" {
+		return nil, errors.New("BIZFLY_AUTH_METHOD=secrets_file requires BIZFLY_SECRETS_FILE to point at the mounted secrets")
+	}
+	if secretsFile != "" {
+		secrets, err := readSecretsFile(secretsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secrets file %s: %w", secretsFile, err)
+		}
+		lookup = func(key string) string {
+			if value, ok := secrets[key]; ok {
+				return value
+			}
+			return getenv(key)
+		}
+		source = secretsFile
+		if method == authMethodSecretsFile {
+			method = strings.ToLower(strings.TrimSpace(secrets["BIZFLY_AUTH_METHOD"]))
+		}
+	}
+
+	creds := &Credentials{
+		Method:              method,
+		Username:            lookup("BIZFLY_USERNAME"),
+		Password:            lookup("BIZFLY_PASSWORD"),
+		AppCredentialID:     lookup("BIZFLY_APP_CREDENTIAL_ID"),
+		AppCredentialSecret: lookup("BIZFLY_APP_CREDENTIAL_SECRET"),
+		ProjectID:           lookup("BIZFLY_PROJECT_ID"),
+		TokenFile:           lookup("BIZFLY_TOKEN_FILE"),
+		Source:              source,
+	}
+	if creds.Method == "" {
+		creds.Method = creds.inferMethod()
+	}
+	if err := creds.Validate(); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+// inferMethod picks the authentication method from the credentials that are set
+func (c *Credentials) inferMethod() string {
+	switch {
+	case c.AppCredentialID != "" || c.AppCredentialSecret != "":
+		return authMethodAppCredential
+	case c.TokenFile != "":
+		return authMethodTokenFile
+	default:
+		return authMethodPassword
+	}
+}
+
+// Validate checks that the chosen method has everything it needs
+func (c *Credentials) Validate() error {
+	switch c.Method {
+	case authMethodPassword:
+		if c.Username == "" || c.Password == "" {
+			return fmt.Errorf("password authentication requires BIZFLY_USERNAME and BIZFLY_PASSWORD (read from %s)", c.Source)
+		}
+	case authMethodAppCredential:
+		if c.AppCredentialID == "" || c.AppCredentialSecret == "" {
+			return fmt.Errorf("application credential authentication requires BIZFLY_APP_CREDENTIAL_ID and BIZFLY_APP_CREDENTIAL_SECRET (read from %s)", c.Source)
+		}
+	case authMethodTokenFile:
+		if c.TokenFile == "" {
+			return fmt.Errorf("token file authentication requires BIZFLY_TOKEN_FILE (read from %s)", c.Source)
+		}
+		if _, err := readTokenFile(c.TokenFile, c.ProjectID); err != nil {
+			return fmt.Errorf("token file authentication: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown BIZFLY_AUTH_METHOD %q (expected password, application_credential, token_file or secrets_file)", c.Method)
+	}
+	return nil
+}
+
+// TokenRequest builds the gobizfly token request for the credentials
+func (c *Credentials) TokenRequest() *gobizfly.TokenCreateRequest {
+	switch c.Method {
+	case authMethodAppCredential:
+		return &gobizfly.TokenCreateRequest{
+			AuthMethod:    authMethodAppCredential,
+			AuthType:      authMethodAppCredential,
+			AppCredID:     c.AppCredentialID,
+			AppCredSecret: c.AppCredentialSecret,
+			ProjectID:     c.ProjectID,
+		}
+	case authMethodTokenFile:
+		// The token endpoint is answered from the file by the TokenManager
+		return &gobizfly.TokenCreateRequest{
+			AuthMethod: "token",
+			ProjectID:  c.ProjectID,
+		}
+	default:
+		return &gobizfly.TokenCreateRequest{
+			AuthMethod: authMethodPassword,
+			Username:   c.Username,
+			Password:   c.Password,
+			ProjectID:  c.ProjectID,
+		}
+	}
+}
+
+// readTokenFile reads a pre-issued token. The file holds either the bare
+// token or the JSON returned by the Bizfly token endpoint.
+func readTokenFile(path, projectID string) (*gobizfly.Token, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.TrimSpace(string(data))
+	if content == "" {
+		return nil, fmt.Errorf("token file %s is empty", path)
+	}
+
+	token := &gobizfly.Token{}
+	if strings.HasPrefix(content, "{") {
+		if err := json.Unmarshal([]byte(content), token); err != nil {
+			return nil, fmt.Errorf("token file %s is not valid JSON: %w", path, err)
+		}
+		if token.KeystoneToken == "" {
+			return nil, fmt.Errorf("token file %s has no \"token\" field", path)
+		}
+	} else {
+		token.KeystoneToken = content
+	}
+	if token.ProjectID == "" {
+		token.ProjectID = projectID
+	}
+	return token, nil
+}
+
+// readSecretsFile reads secrets from a KEY=VALUE file or from a directory
+// holding one file per key (as Kubernetes and Docker mount them). Keys
+// without the BIZFLY_ prefix, such as "password", are normalised to it.
+func readSecretsFile(path string) (map[string]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make(map[string]string)
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			// Skip Kubernetes' ..data symlinks and other hidden entries
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(path, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			secrets[secretKey(entry.Name())] = strings.TrimRight(string(data), "\r\n")
+		}
+		return secrets, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNo)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		secrets[secretKey(key)] = value
+	}
+	return secrets, scanner.Err()
+}
+
+func secretKey(name string) string {
+	key := strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(key, "BIZFLY_") {
+		key = "BIZFLY_" + key
+	}
+	return key
+}
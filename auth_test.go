@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bizflycloud/gobizfly"
+)
+
+func envMap(values map[string]string) func(string) string {
+	return func(key string) string {
+		return values[key]
+	}
+}
+
+func TestLoadCredentials(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("pre-issued-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		env        map[string]string
+		wantMethod string
+		wantErr    bool
+	}{
+		{
+			name:       "password from environment",
+			env:        map[string]string{"BIZFLY_USERNAME": "user", "BIZFLY_PASSWORD": "secret"},
+			wantMethod: authMethodPassword,
+		},
+		{
+			name:    "password missing",
+			env:     map[string]string{"BIZFLY_USERNAME": "user"},
+			wantErr: true,
+		},
+		{
+			name:       "application credential inferred",
+			env:        map[string]string{"BIZFLY_APP_CREDENTIAL_ID": "id", "BIZFLY_APP_CREDENTIAL_SECRET": "secret"},
+			wantMethod: authMethodAppCredential,
+		},
+		{
+			name:    "application credential missing secret",
+			env:     map[string]string{"BIZFLY_AUTH_METHOD": "application_credential", "BIZFLY_APP_CREDENTIAL_ID": "id"},
+			wantErr: true,
+		},
+		{
+			name:       "token file inferred",
+			env:        map[string]string{"BIZFLY_TOKEN_FILE": tokenFile},
+			wantMethod: authMethodTokenFile,
+		},
+		{
+			name:    "token file does not exist",
+			env:     map[string]string{"BIZFLY_AUTH_METHOD": "token_file", "BIZFLY_TOKEN_FILE": filepath.Join(dir, "missing")},
+			wantErr: true,
+		},
+		{
+			name:    "secrets file mode without a path",
+			env:     map[string]string{"BIZFLY_AUTH_METHOD": "secrets_file"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown method",
+			env:     map[string]string{"BIZFLY_AUTH_METHOD": "kerberos"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := LoadCredentials(envMap(tt.env))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadCredentials() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && creds.Method != tt.wantMethod {
+				t.Errorf("Expected method %q, got %q", tt.wantMethod, creds.Method)
+			}
+		})
+	}
+}
+
+func TestLoadCredentialsFromSecretsFile(t *testing.T) {
+	t.Run("key value file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "bizfly.env")
+		content := "# mounted secret\nexport BIZFLY_USERNAME=user\nBIZFLY_PASSWORD=\"from-file\"\n"
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		creds, err := LoadCredentials(envMap(map[string]string{
+			"BIZFLY_AUTH_METHOD":  "secrets_file",
+			"BIZFLY_SECRETS_FILE": path,
+			"BIZFLY_PASSWORD":     "from-env",
+		}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if creds.Method != authMethodPassword || creds.Password != "from-file" {
+			t.Errorf("Expected password from secrets file, got method %q password %q", creds.Method, creds.Password)
+		}
+	})
+
+	t.Run("directory with one file per key", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "app_credential_id"), []byte("cred-id\n"), 0o600)
+		os.WriteFile(filepath.Join(dir, "app_credential_secret"), []byte("cred-secret\n"), 0o600)
+
+		creds, err := LoadCredentials(envMap(map[string]string{"BIZFLY_SECRETS_FILE": dir}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if creds.Method != authMethodAppCredential || creds.AppCredentialID != "cred-id" {
+			t.Errorf("Expected application credential from directory, got %+v", creds)
+		}
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "bizfly.env")
+		os.WriteFile(path, []byte("not a key value line\n"), 0o600)
+
+		if _, err := LoadCredentials(envMap(map[string]string{"BIZFLY_SECRETS_FILE": path})); err == nil {
+			t.Error("Expected an error for a malformed secrets file")
+		}
+	})
+}
+
+func TestReadTokenFile(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("bare token", func(t *testing.T) {
+		path := filepath.Join(dir, "bare")
+		os.WriteFile(path, []byte("abc123\n"), 0o600)
+		token, err := readTokenFile(path, "project-1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if token.KeystoneToken != "abc123" || token.ProjectID != "project-1" {
+			t.Errorf("Unexpected token %+v", token)
+		}
+	})
+
+	t.Run("json token", func(t *testing.T) {
+		path := filepath.Join(dir, "json")
+		os.WriteFile(path, []byte(`{"token":"abc123","project_id":"p2","expire_at":"2030-01-01T00:00:00Z"}`), 0o600)
+		token, err := readTokenFile(path, "project-1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if token.ProjectID != "p2" || token.ExpiresAt == "" {
+			t.Errorf("Unexpected token %+v", token)
+		}
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		path := filepath.Join(dir, "empty")
+		os.WriteFile(path, nil, 0o600)
+		if _, err := readTokenFile(path, ""); err == nil {
+			t.Error("Expected an error for an empty token file")
+		}
+	})
+}
+
+func TestTokenManagerUsesTokenFile(t *testing.T) {
+	keystone := &fakeKeystone{expiresIn: time.Hour}
+	ts := newFakeKeystoneServer(t, keystone)
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(path, []byte("file-token"), 0o600)
+
+	tokens := NewTokenManager(&Credentials{Method: authMethodTokenFile, TokenFile: path})
+	client, err := gobizfly.NewClient(
+		gobizfly.WithAPIURL(ts.URL),
+		gobizfly.WithRegionName("HaNoi"),
+		gobizfly.WithHTTPClient(tokens.HTTPClient()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if err := tokens.Authenticate(context.Background(), client); err != nil {
+		t.Fatalf("Failed to authenticate: %v", err)
+	}
+	if got := keystone.issuedCount(); got != 0 {
+		t.Errorf("Expected Keystone not to be asked for a token, got %d requests", got)
+	}
+	if tokens.token != "file-token" {
+		t.Errorf("Expected token from file, got %q", tokens.token)
+	}
+}
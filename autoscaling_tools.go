@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"strings"
 
 	"github.com/bizflycloud/gobizfly"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -17,11 +16,18 @@ func RegisterAutoScalingTools(s *server.MCPServer, client *gobizfly.Client) {
 	// List auto scaling groups tool
 	listGroupsTool := mcp.NewTool("bizflycloud_list_autoscaling_groups",
 		mcp.WithDescription("List all Bizfly Cloud AutoScaling groups"),
+		withCommonOptions(),
+		withListOptions(),
 		mcp.WithBoolean("all",
 			mcp.Description("List all groups including deleted ones (default: false)"),
 		),
 	)
 	s.AddTool(listGroupsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
+		query, err := parseListQuery(request.Params.Arguments)
+		if err != nil {
+			return errorResult(ctx, "Failed to list auto scaling groups", err), nil
+		}
 		defer func() {
 			if r := recover(); r != nil {
 				log.Printf("[PANIC] Recovered from panic in listGroupsTool: %v", r)
@@ -31,30 +37,28 @@ func RegisterAutoScalingTools(s *server.MCPServer, client *gobizfly.Client) {
 		log.Printf("[DEBUG] AutoScaling Groups List tool called")
 		all, _ := request.Params.Arguments["all"].(bool)
 
-		if client == nil || client.AutoScaling == nil || client.AutoScaling.AutoScalingGroups() == nil {
+		if services == nil || services.AutoScaling == nil {
 			log.Printf("[ERROR] AutoScaling service is not available")
-			return mcp.NewToolResultText("Available AutoScaling groups:\n\n(AutoScaling service is not available)"), nil
+			return listResult(request, "Available AutoScaling groups:\n\n(AutoScaling service is not available)", "groups", []AutoScalingGroupOutput{}), nil
 		}
 
-		groups, err := client.AutoScaling.AutoScalingGroups().List(ctx, all)
+		groups, err := services.AutoScaling.List(ctx, all)
 		if err != nil {
 			log.Printf("[ERROR] Failed to list auto scaling groups: %v", err)
-			errStr := strings.ToLower(err.Error())
-			if strings.Contains(errStr, "404") ||
-				strings.Contains(errStr, "not found") ||
-				strings.Contains(errStr, "resource not found") ||
-				strings.Contains(errStr, "<svg") ||
-				strings.Contains(errStr, "<html") {
-				return mcp.NewToolResultText("Available AutoScaling groups:\n\n(No groups found or AutoScaling service is not enabled)"), nil
+			if isServiceUnavailable(ctx, err) {
+				return listResult(request, "Available AutoScaling groups:\n\n(No groups found or AutoScaling service is not enabled)", "groups", []AutoScalingGroupOutput{}), nil
 			}
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list auto scaling groups: %v", err)), nil
+			return errorResult(ctx, "Failed to list auto scaling groups", err), nil
 		}
+		groups, page := pageItems(query, groups, autoScalingGroupListFields)
 
 		result := "Available AutoScaling groups:\n\n"
+		items := []AutoScalingGroupOutput{}
 		if len(groups) == 0 {
 			result += "(No groups found)\n"
 		} else {
 			for _, group := range groups {
+				items = append(items, newAutoScalingGroupOutput(group))
 				result += fmt.Sprintf("Group: %s\n", group.Name)
 				result += fmt.Sprintf("  ID: %s\n", group.ID)
 				result += fmt.Sprintf("  Status: %s\n", group.Status)
@@ -67,25 +71,27 @@ func RegisterAutoScalingTools(s *server.MCPServer, client *gobizfly.Client) {
 				result += "\n"
 			}
 		}
-		return mcp.NewToolResultText(result), nil
+		return pageResult(request, result, "groups", items, page), nil
 	})
 
 	// Get auto scaling group tool
 	getGroupTool := mcp.NewTool("bizflycloud_get_autoscaling_group",
 		mcp.WithDescription("Get details of a Bizfly Cloud AutoScaling group"),
+		withCommonOptions(),
 		mcp.WithString("group_id",
 			mcp.Required(),
 			mcp.Description("ID of the auto scaling group"),
 		),
 	)
 	s.AddTool(getGroupTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		groupID, ok := request.Params.Arguments["group_id"].(string)
 		if !ok {
 			return nil, errors.New("group_id must be a string")
 		}
-		group, err := client.AutoScaling.AutoScalingGroups().Get(ctx, groupID)
+		group, err := services.AutoScaling.Get(ctx, groupID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get auto scaling group: %v", err)), nil
+			return errorResult(ctx, "Failed to get auto scaling group", err), nil
 		}
 
 		result := fmt.Sprintf("AutoScaling Group Details:\n\n")
@@ -100,12 +106,14 @@ func RegisterAutoScalingTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += fmt.Sprintf("Profile Name: %s\n", group.ProfileName)
 		result += fmt.Sprintf("Created At: %s\n", group.Created)
 		result += fmt.Sprintf("Updated At: %s\n", group.Updated)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newAutoScalingGroupOutput(group)), nil
 	})
 
 	// Create auto scaling group tool
 	createGroupTool := mcp.NewTool("bizflycloud_create_autoscaling_group",
 		mcp.WithDescription("Create a new Bizfly Cloud AutoScaling group"),
+		withCommonOptions(),
+		withDryRun(),
 		mcp.WithString("name",
 			mcp.Required(),
 			mcp.Description("Name of the auto scaling group"),
@@ -128,6 +136,7 @@ func RegisterAutoScalingTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(createGroupTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		name, ok := request.Params.Arguments["name"].(string)
 		if !ok {
 			return nil, errors.New("name must be a string")
@@ -149,16 +158,22 @@ func RegisterAutoScalingTools(s *server.MCPServer, client *gobizfly.Client) {
 			return nil, errors.New("desired_capacity must be a number")
 		}
 
-		group, err := client.AutoScaling.AutoScalingGroups().Create(ctx, &gobizfly.AutoScalingGroupCreateRequest{
-			Name:           name,
-			ProfileID:      profileID,
-			MinSize:        int(minSize),
-			MaxSize:        int(maxSize),
+		createReq := &gobizfly.AutoScalingGroupCreateRequest{
+			Name:            name,
+			ProfileID:       profileID,
+			MinSize:         int(minSize),
+			MaxSize:         int(maxSize),
 			DesiredCapacity: int(desiredCapacity),
-		})
+		}
+		if isDryRun(request) {
+			return dryRunResult(request, "bizflycloud_create_autoscaling_group", "", createReq, validateAutoScalingGroupCreate(createReq)), nil
+		}
+
+		group, err := services.AutoScaling.Create(ctx, createReq)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create auto scaling group: %v", err)), nil
+			return errorResult(ctx, "Failed to create auto scaling group", err), nil
 		}
+		recordTask(ctx, services, TaskSpec{Action: "create", ResourceType: "autoscaling_group", ResourceID: group.ID, ResourceName: group.Name, Done: []string{"ACTIVE"}})
 
 		result := fmt.Sprintf("AutoScaling group created successfully:\n")
 		result += fmt.Sprintf("  Name: %s\n", group.Name)
@@ -167,27 +182,67 @@ func RegisterAutoScalingTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += fmt.Sprintf("  Min Size: %d\n", group.MinSize)
 		result += fmt.Sprintf("  Max Size: %d\n", group.MaxSize)
 		result += fmt.Sprintf("  Desired Capacity: %d\n", group.DesiredCapacity)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newAutoScalingGroupOutput(group)), nil
 	})
 
 	// Delete auto scaling group tool
 	deleteGroupTool := mcp.NewTool("bizflycloud_delete_autoscaling_group",
 		mcp.WithDescription("Delete a Bizfly Cloud AutoScaling group"),
+		withCommonOptions(),
+		withConfirmation(),
 		mcp.WithString("group_id",
 			mcp.Required(),
 			mcp.Description("ID of the auto scaling group to delete"),
 		),
 	)
 	s.AddTool(deleteGroupTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		groupID, ok := request.Params.Arguments["group_id"].(string)
 		if !ok {
 			return nil, errors.New("group_id must be a string")
 		}
-		err := client.AutoScaling.AutoScalingGroups().Delete(ctx, groupID)
+		err := services.AutoScaling.Delete(ctx, groupID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete auto scaling group: %v", err)), nil
+			return errorResult(ctx, "Failed to delete auto scaling group", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("AutoScaling group %s deleted successfully", groupID)), nil
+		recordTask(ctx, services, TaskSpec{Action: "delete", ResourceType: "autoscaling_group", ResourceID: groupID, Done: []string{statusDeleted}})
+		return actionResult(request, fmt.Sprintf("AutoScaling group %s deleted successfully", groupID), ActionOutput{Action: "delete", ResourceType: "autoscaling_group", ResourceID: groupID}), nil
 	})
 }
 
+// previewAutoScalingGroupDeletion describes the group and the nodes it manages
+func previewAutoScalingGroupDeletion(ctx context.Context, services *Services, args map[string]interface{}) (string, error) {
+	groupID, err := stringArg(args, "group_id")
+	if err != nil {
+		return "", err
+	}
+	group, err := services.AutoScaling.Get(ctx, groupID)
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("Auto Scaling Group: %s\n", group.Name)
+	result += fmt.Sprintf("  ID: %s\n", group.ID)
+	result += fmt.Sprintf("  Status: %s\n", group.Status)
+	result += fmt.Sprintf("  Desired Capacity: %d\n", group.DesiredCapacity)
+	result += fmt.Sprintf("  Nodes: %d\n", len(group.NodeIDs))
+	result += fmt.Sprintf("  Created At: %s\n", group.Created)
+	return result, nil
+}
+
+// validateAutoScalingGroupCreate checks an auto scaling group create request before it is sent
+func validateAutoScalingGroupCreate(req *gobizfly.AutoScalingGroupCreateRequest) *requestValidation {
+	v := &requestValidation{}
+	v.check(req.Name != "", "name is set")
+	v.check(req.ProfileID != "", "launch configuration profile is set")
+	v.check(req.MinSize >= 0, "min size is not negative")
+	v.check(req.MinSize <= req.MaxSize, "min size is not greater than max size")
+	v.check(req.MinSize <= req.DesiredCapacity && req.DesiredCapacity <= req.MaxSize,
+		"desired capacity is between min and max size")
+	return v
+}
+
+// autoScalingGroupListFields are the fields list_autoscaling_groups filters and sorts on
+func autoScalingGroupListFields(group *gobizfly.AutoScalingGroup) listFields {
+	return listFields{Name: group.Name, Status: group.Status, CreatedAt: group.Created}
+}
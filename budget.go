@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// continuationArg is the handle bizflycloud_fetch_more returns the rest of a truncated result for
+	continuationArg = "continuation"
+
+	fetchMoreTool = "bizflycloud_fetch_more"
+
+	// defaultMaxOutputChars is roughly 10,000 tokens
+	defaultMaxOutputChars  = 40000
+	defaultContinuationTTL = 15 * time.Minute
+
+	// charsPerToken is the rough ratio used to estimate the tokens of a result
+	charsPerToken = 4
+
+	// maxOmittedNames is how many section headings or list items of the omitted output a summary names
+	maxOmittedNames = 5
+)
+
+// TruncatedOutput is the JSON result of a call whose output exceeded the budget. The
+// content of the first call and every fetch_more call concatenate to the full output.
+type TruncatedOutput struct {
+	Content           string `json:"content"`
+	Truncated         bool   `json:"truncated"`
+	Offset            int    `json:"offset"`
+	TotalCharacters   int    `json:"total_characters"`
+	OmittedCharacters int    `json:"omitted_characters"`
+	OmittedSummary    string `json:"omitted_summary,omitempty"`
+	Continuation      string `json:"continuation,omitempty"`
+}
+
+// pendingOutput is the rest of a truncated result, waiting to be fetched
+type pendingOutput struct {
+	session   string
+	tool      string
+	format    string
+	rest      string
+	offset    int
+	total     int
+	expiresAt time.Time
+}
+
+type outputBudgetContextKey struct{}
+
+// OutputBudget caps the size of tool results. A result over the budget is cut
+// at a line break and the rest is kept for a while under a continuation handle
+// that bizflycloud_fetch_more pages through.
+type OutputBudget struct {
+	mu       sync.Mutex
+	maxChars int
+	ttl      time.Duration
+	pending  map[string]pendingOutput
+	now      func() time.Time
+}
+
+// NewOutputBudget creates a budget of maxChars characters per result; 0 disables truncation
+func NewOutputBudget(maxChars int, ttl time.Duration) *OutputBudget {
+	if ttl <= 0 {
+		ttl = defaultContinuationTTL
+	}
+	return &OutputBudget{
+		maxChars: maxChars,
+		ttl:      ttl,
+		pending:  make(map[string]pendingOutput),
+		now:      time.Now,
+	}
+}
+
+// outputBudgetFromContext returns the budget the current tool call runs under, or nil
+func outputBudgetFromContext(ctx context.Context) *OutputBudget {
+	budget, _ := ctx.Value(outputBudgetContextKey{}).(*OutputBudget)
+	return budget
+}
+
+// Middleware truncates results over the budget and makes the budget available to fetch_more
+func (b *OutputBudget) Middleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(context.WithValue(ctx, outputBudgetContextKey{}, b), request)
+			if err != nil || result == nil || result.IsError || b.maxChars <= 0 || request.Params.Name == fetchMoreTool {
+				return result, err
+			}
+			text := resultText(result)
+			if len(text) <= b.maxChars {
+				return result, nil
+			}
+			return b.page(sessionIDFromContext(ctx), request.Params.Name, outputFormat(request), text, 0, len(text))
+		}
+	}
+}
+
+// page returns the part of text that fits the budget, storing the rest under a
+// new continuation that only the session can fetch
+func (b *OutputBudget) page(session, tool, format, text string, offset, total int) (*mcp.CallToolResult, error) {
+	if b.maxChars <= 0 || len(text) <= b.maxChars {
+		if format == formatJSON {
+			return jsonResult(TruncatedOutput{Content: text, Offset: offset, TotalCharacters: total}), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	}
+
+	cut := cutPoint(text, b.maxChars)
+	shown, rest := text[:cut], text[cut:]
+	handle, err := b.store(pendingOutput{session: session, tool: tool, format: format, rest: rest, offset: offset + cut, total: total})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to store the rest of the output: %v", err)), nil
+	}
+
+	summary := omittedSummary(rest, strings.HasSuffix(shown, "\n\n"))
+	if format == formatJSON {
+		return jsonResult(TruncatedOutput{
+			Content:           shown,
+			Truncated:         true,
+			Offset:            offset,
+			TotalCharacters:   total,
+			OmittedCharacters: len(rest),
+			OmittedSummary:    summary,
+			Continuation:      handle,
+		}), nil
+	}
+
+	result := strings.TrimRight(shown, "\n") + "\n\n"
+	result += fmt.Sprintf("[Output truncated: showing characters %d-%d of %d. Omitted: %s]\n", offset+1, offset+cut, total, summary)
+	result += fmt.Sprintf("Call %s with %s: %s for the rest. It is available until %s.\n",
+		fetchMoreTool, continuationArg, handle, b.now().Add(b.ttl).UTC().Format(time.RFC3339))
+	return mcp.NewToolResultText(result), nil
+}
+
+// store keeps the rest of a truncated result and returns its continuation handle
+func (b *OutputBudget) store(output pendingOutput) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	handle := hex.EncodeToString(buf)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	for key, pending := range b.pending {
+		if now.After(pending.expiresAt) {
+			delete(b.pending, key)
+		}
+	}
+	output.expiresAt = now.Add(b.ttl)
+	b.pending[handle] = output
+	return handle, nil
+}
+
+// take removes and returns the output stored under a continuation handle.
+// A handle stored for another session is unknown to this one and stays put.
+func (b *OutputBudget) take(session, handle string) (pendingOutput, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	output, ok := b.pending[handle]
+	if !ok || output.session != session {
+		return pendingOutput{}, errors.New("the continuation is unknown or was already fetched")
+	}
+	delete(b.pending, handle)
+	if b.now().After(output.expiresAt) {
+		return pendingOutput{}, errors.New("the continuation has expired; call the original tool again")
+	}
+	return output, nil
+}
+
+// cutPoint returns where to cut text to fit max characters: the last line break
+// in the second half of the budget, or the last whole character before max
+func cutPoint(text string, max int) int {
+	if i := strings.LastIndexByte(text[:max], '\n'); i >= max/2 {
+		return i + 1
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return cut
+}
+
+// itemHeaderPattern matches the unindented "Server: web-1" line that starts each
+// item of a text list
+var itemHeaderPattern = regexp.MustCompile(`^([A-Z][A-Za-z ]*): (\S.*)$`)
+
+// jsonFieldPattern matches a string field of an indented JSON object
+var jsonFieldPattern = regexp.MustCompile(`^( *)"(name|id)": "((?:[^"\\]|\\.)*)",?$`)
+
+// omittedSummary describes the size of the omitted output and the sections and
+// list items it contains. blockStart tells whether the shown output ended a block,
+// so the first omitted line can start an item.
+func omittedSummary(rest string, blockStart bool) string {
+	lines := strings.Split(strings.TrimRight(rest, "\n"), "\n")
+	summary := fmt.Sprintf("%d characters (~%d tokens) in %d lines", len(rest), (len(rest)+charsPerToken-1)/charsPerToken, len(lines))
+
+	var headings, items []string
+	for i, line := range lines {
+		if i > 0 {
+			blockStart = lines[i-1] == ""
+		}
+		if strings.HasPrefix(line, "#") {
+			headings = append(headings, strings.TrimSpace(strings.TrimLeft(line, "#")))
+		} else if m := itemHeaderPattern.FindStringSubmatch(line); m != nil && blockStart {
+			items = append(items, m[1]+" "+m[2])
+		}
+	}
+	if len(items) == 0 {
+		items = jsonItems(lines)
+	}
+	if len(headings) > 0 {
+		summary += ", including sections " + strings.Join(capNames(headings), ", ")
+	}
+	if len(items) > 0 {
+		summary += fmt.Sprintf(", including %d items: %s", len(items), strings.Join(capNames(items), ", "))
+	}
+	return summary
+}
+
+// jsonItems names the items of omitted JSON output by the name, or else the ID,
+// of the least indented objects, which are the items of the list being cut
+func jsonItems(lines []string) []string {
+	values := map[string][]string{}
+	indent := map[string]int{}
+	for _, line := range lines {
+		m := jsonFieldPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		field, depth := m[2], len(m[1])
+		if current, ok := indent[field]; !ok || depth < current {
+			indent[field], values[field] = depth, nil
+		} else if depth > current {
+			continue
+		}
+		values[field] = append(values[field], m[3])
+	}
+	if len(values["name"]) > 0 {
+		return values["name"]
+	}
+	return values["id"]
+}
+
+// capNames keeps the first maxOmittedNames names and counts the others
+func capNames(names []string) []string {
+	if len(names) <= maxOmittedNames {
+		return names
+	}
+	return append(names[:maxOmittedNames:maxOmittedNames], fmt.Sprintf("%d more", len(names)-maxOmittedNames))
+}
+
+// RegisterOutputTools registers the tool that pages through truncated results
+func RegisterOutputTools(s *server.MCPServer) {
+	// Fetch more tool
+	fetchMore := mcp.NewTool(fetchMoreTool,
+		mcp.WithDescription("Get the rest of a tool result that was truncated because it was too large"),
+		withFormatOption(),
+		mcp.WithString(continuationArg,
+			mcp.Required(),
+			mcp.Description("Continuation handle from the truncated result"),
+		),
+	)
+	s.AddTool(fetchMore, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		handle, ok := request.Params.Arguments[continuationArg].(string)
+		if !ok {
+			return nil, errors.New("continuation must be a string")
+		}
+		budget := outputBudgetFromContext(ctx)
+		if budget == nil {
+			return mcp.NewToolResultError("Failed to fetch more output: output truncation is disabled on this server"), nil
+		}
+		output, err := budget.take(sessionIDFromContext(ctx), handle)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to fetch more output: %v", err)), nil
+		}
+		format := output.format
+		if _, ok := request.Params.Arguments[formatArg]; ok {
+			format = outputFormat(request)
+		}
+		return budget.page(output.session, output.tool, format, output.rest, output.offset, output.total)
+	})
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bizflycloud-mcp-server/internal/fakecloud"
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var continuationPattern = regexp.MustCompile(continuationArg + `: ([0-9a-f]+)`)
+
+// newBudgetTestServer serves a tool returning output behind a budget of maxChars
+func newBudgetTestServer(t *testing.T, budget *OutputBudget, output string) *server.MCPServer {
+	t.Helper()
+	s := server.NewMCPServer("BizflyCloud MCP Test", "1.0.0",
+		server.WithToolHandlerMiddleware(budget.Middleware()),
+	)
+	s.AddTool(mcp.NewTool("bizflycloud_list_all_resources", withFormatOption()), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(output), nil
+	})
+	RegisterOutputTools(s)
+	return s
+}
+
+// largeSummary builds a resource summary with the given number of sections
+func largeSummary(sections int) string {
+	var b strings.Builder
+	for i := 1; i <= sections; i++ {
+		fmt.Fprintf(&b, "## %d. Section %d\n\n", i, i)
+		for j := 0; j < 10; j++ {
+			fmt.Fprintf(&b, "| server-%d-%d | ACTIVE | 2c_4g |\n", i, j)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func TestOutputBudget(t *testing.T) {
+	output := largeSummary(8)
+
+	t.Run("keeps results within the budget", func(t *testing.T) {
+		s := newBudgetTestServer(t, NewOutputBudget(len(output), time.Minute), output)
+		result := callTool(t, s, "bizflycloud_list_all_resources", map[string]interface{}{})
+		if getTextFromResult(result) != output {
+			t.Error("Expected the result to be returned unchanged")
+		}
+	})
+
+	t.Run("truncates at a line and pages through the rest", func(t *testing.T) {
+		s := newBudgetTestServer(t, NewOutputBudget(500, time.Minute), output)
+		result := callTool(t, s, "bizflycloud_list_all_resources", map[string]interface{}{})
+		text := getTextFromResult(result)
+		verifyToolResult(t, result, "[Output truncated: showing characters 1-")
+		verifyToolResult(t, result, "including sections 3. Section 3")
+
+		var pages []string
+		for i := 0; i < 20; i++ {
+			shown, _, _ := strings.Cut(text, "\n\n[Output truncated")
+			pages = append(pages, shown)
+			match := continuationPattern.FindStringSubmatch(text)
+			if match == nil {
+				break
+			}
+			text = getTextFromResult(callTool(t, s, fetchMoreTool, map[string]interface{}{continuationArg: match[1]}))
+		}
+		for _, page := range pages[:len(pages)-1] {
+			if len(page) > 500 {
+				t.Errorf("Expected pages within the budget, got %d characters", len(page))
+			}
+		}
+		// Pages are cut at line breaks, so the lines of all pages are the original lines
+		if got := strings.Join(strings.Fields(strings.Join(pages, "\n")), " "); got != strings.Join(strings.Fields(output), " ") {
+			t.Error("Expected the pages to add up to the full output")
+		}
+	})
+
+	t.Run("continuations are single use", func(t *testing.T) {
+		s := newBudgetTestServer(t, NewOutputBudget(500, time.Minute), output)
+		match := continuationPattern.FindStringSubmatch(getTextFromResult(callTool(t, s, "bizflycloud_list_all_resources", map[string]interface{}{})))
+		callTool(t, s, fetchMoreTool, map[string]interface{}{continuationArg: match[1]})
+		verifyToolError(t, callTool(t, s, fetchMoreTool, map[string]interface{}{continuationArg: match[1]}), "unknown or was already fetched")
+	})
+
+	t.Run("continuations belong to the session", func(t *testing.T) {
+		s := newBudgetTestServer(t, NewOutputBudget(500, time.Minute), output)
+		text, _ := callTaskTool(t, s, scopedContext(s, "session-1", ""), "bizflycloud_list_all_resources", map[string]interface{}{})
+		var page TruncatedOutput
+		if err := json.Unmarshal([]byte(text), &page); err != nil || page.Continuation == "" {
+			t.Fatalf("Expected a truncated JSON page, got %q", text)
+		}
+		text, isError := callTaskTool(t, s, scopedContext(s, "session-2", ""), fetchMoreTool, map[string]interface{}{continuationArg: page.Continuation})
+		if !isError || !strings.Contains(text, "unknown or was already fetched") {
+			t.Errorf("Expected the continuation to be unknown to another session, got %q", text)
+		}
+		if text, isError := callTaskTool(t, s, scopedContext(s, "session-1", ""), fetchMoreTool, map[string]interface{}{continuationArg: page.Continuation}); isError {
+			t.Errorf("Expected the session to still fetch its continuation, got %q", text)
+		}
+	})
+
+	t.Run("continuations expire", func(t *testing.T) {
+		budget := NewOutputBudget(500, time.Minute)
+		now := time.Now()
+		budget.now = func() time.Time { return now }
+		s := newBudgetTestServer(t, budget, output)
+		match := continuationPattern.FindStringSubmatch(getTextFromResult(callTool(t, s, "bizflycloud_list_all_resources", map[string]interface{}{})))
+		now = now.Add(2 * time.Minute)
+		verifyToolError(t, callTool(t, s, fetchMoreTool, map[string]interface{}{continuationArg: match[1]}), "expired")
+	})
+
+	t.Run("json content concatenates to the full output", func(t *testing.T) {
+		s := newBudgetTestServer(t, NewOutputBudget(500, time.Minute), output)
+		result := callTool(t, s, "bizflycloud_list_all_resources", map[string]interface{}{formatArg: formatJSON})
+		var full strings.Builder
+		for i := 0; i < 20; i++ {
+			var page TruncatedOutput
+			if err := json.Unmarshal([]byte(getTextFromResult(result)), &page); err != nil {
+				t.Fatalf("Expected a JSON page, got %q", getTextFromResult(result))
+			}
+			if page.Offset != full.Len() || page.TotalCharacters != len(output) {
+				t.Fatalf("Unexpected page position %+v", page)
+			}
+			full.WriteString(page.Content)
+			if !page.Truncated {
+				break
+			}
+			result = callTool(t, s, fetchMoreTool, map[string]interface{}{continuationArg: page.Continuation})
+		}
+		if full.String() != output {
+			t.Error("Expected the JSON pages to concatenate to the full output")
+		}
+	})
+}
+
+func TestOmittedSummaryNamesListItems(t *testing.T) {
+	state := testCloudState()
+	state.Servers = nil
+	for i := 1; i <= 20; i++ {
+		state.Servers = append(state.Servers, &gobizfly.Server{
+			ID: fmt.Sprintf("srv-%d", i), Name: fmt.Sprintf("web-%d", i), Status: "ACTIVE", FlavorName: "nix.2c_4g", AvailabilityZone: "HN1",
+		})
+	}
+	cloud := fakecloud.New(state)
+	pool := NewClientPool(NewMockConfig())
+	pool.SetTransport(cloud)
+	s := server.NewMCPServer("BizflyCloud MCP Test", "1.0.0",
+		server.WithToolHandlerMiddleware(NewOutputBudget(800, time.Minute).Middleware()),
+		server.WithToolHandlerMiddleware(pool.Middleware()),
+	)
+	registerTools(s, newMockClient(t, pool), pool, nil)
+
+	// The summary counts the items whose header was cut and names the first of them
+	expectItems := func(t *testing.T, summary string, shown int, prefix string) {
+		t.Helper()
+		expected := fmt.Sprintf("including %d items: %sweb-%d, %sweb-%d", 20-shown, prefix, shown+1, prefix, shown+2)
+		if !strings.Contains(summary, expected) || !strings.Contains(summary, fmt.Sprintf("%d more", 20-shown-maxOmittedNames)) {
+			t.Errorf("Expected the summary to contain %q, got %q", expected, summary)
+		}
+	}
+
+	t.Run("text", func(t *testing.T) {
+		text := getTextFromResult(callTool(t, s, "bizflycloud_list_servers", map[string]interface{}{}))
+		shown, summary, found := strings.Cut(text, "[Output truncated")
+		if !found {
+			t.Fatalf("Expected the list to be truncated, got %q", text)
+		}
+		expectItems(t, summary, strings.Count(shown, "\nServer: "), "Server ")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var page TruncatedOutput
+		result := callTool(t, s, "bizflycloud_list_servers", map[string]interface{}{formatArg: formatJSON})
+		if err := json.Unmarshal([]byte(getTextFromResult(result)), &page); err != nil || !page.Truncated {
+			t.Fatalf("Expected a truncated JSON page, got %q", getTextFromResult(result))
+		}
+		expectItems(t, page.OmittedSummary, strings.Count(page.Content, `"name": "web-`), "")
+	})
+}
+
+func TestFetchMoreWithoutBudget(t *testing.T) {
+	s := createTestMCPServer()
+	RegisterOutputTools(s)
+	verifyToolError(t, callTool(t, s, fetchMoreTool, map[string]interface{}{continuationArg: "abc"}), "truncation is disabled")
+}
+
+func TestCutPoint(t *testing.T) {
+	if cut := cutPoint("first line\nsecond line", 15); cut != len("first line\n") {
+		t.Errorf("Expected a cut after the line break, got %d", cut)
+	}
+	if cut := cutPoint("aaaaaaaaaaé", 11); cut != 10 {
+		t.Errorf("Expected a cut before the multi-byte character, got %d", cut)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	refreshCatalogTool = "bizflycloud_refresh_catalog"
+
+	defaultCatalogTTL = 10 * time.Minute
+)
+
+// Kinds of catalog data the cache holds
+const (
+	catalogFlavors      = "flavors"
+	catalogOSImages     = "os_images"
+	catalogCustomImages = "custom_images"
+	catalogVolumeTypes  = "volume_types"
+)
+
+// catalogEntry is one cached catalog lookup
+type catalogEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+type catalogContextKey struct{}
+
+// CatalogCache keeps the flavors, images and volume types of each client for a
+// while, so tools that only look them up don't list them on every call. Entries
+// are kept per client, so every profile and region has its own catalog.
+type CatalogCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[*gobizfly.Client]map[string]catalogEntry
+	now     func() time.Time
+}
+
+// NewCatalogCache creates a catalog cache whose entries live for ttl; 0 disables caching
+func NewCatalogCache(ttl time.Duration) *CatalogCache {
+	return &CatalogCache{
+		ttl:     ttl,
+		entries: make(map[*gobizfly.Client]map[string]catalogEntry),
+		now:     time.Now,
+	}
+}
+
+// catalogFromContext returns the catalog cache of the current tool call, or nil
+func catalogFromContext(ctx context.Context) *CatalogCache {
+	catalog, _ := ctx.Value(catalogContextKey{}).(*CatalogCache)
+	return catalog
+}
+
+// Middleware makes the catalog cache available to the tool handlers
+func (c *CatalogCache) Middleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return next(context.WithValue(ctx, catalogContextKey{}, c), request)
+		}
+	}
+}
+
+// Invalidate drops the cached catalog of a client, or of every client when client is nil.
+// It returns the number of entries dropped.
+func (c *CatalogCache) Invalidate(client *gobizfly.Client) int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dropped := 0
+	for cached, entries := range c.entries {
+		if client == nil || cached == client {
+			dropped += len(entries)
+			delete(c.entries, cached)
+		}
+	}
+	return dropped
+}
+
+// catalogLookup returns the cached catalog data of a kind for a client, calling
+// fetch when it is missing or expired. A nil cache always calls fetch.
+func catalogLookup[T any](ctx context.Context, c *CatalogCache, client *gobizfly.Client, kind string, fetch func(context.Context) (T, error)) (T, error) {
+	if c == nil || c.ttl <= 0 {
+		return fetch(ctx)
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[client][kind]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expiresAt) {
+		if value, ok := entry.value.(T); ok {
+			return value, nil
+		}
+	}
+
+	// Errors are not cached, so a failed lookup is retried by the next call
+	value, err := fetch(ctx)
+	if err != nil {
+		return value, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[client] == nil {
+		c.entries[client] = make(map[string]catalogEntry)
+	}
+	c.entries[client][kind] = catalogEntry{value: value, expiresAt: c.now().Add(c.ttl)}
+	return value, nil
+}
+
+// volumeTypesInUse returns a fetch of the sorted volume types of the existing volumes
+func volumeTypesInUse(volumes VolumeService) func(context.Context) ([]string, error) {
+	return func(ctx context.Context) ([]string, error) {
+		volumes, err := volumes.List(ctx, &gobizfly.VolumeListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool)
+		types := []string{}
+		for _, volume := range volumes {
+			if volume.VolumeType != "" && !seen[volume.VolumeType] {
+				seen[volume.VolumeType] = true
+				types = append(types, volume.VolumeType)
+			}
+		}
+		sort.Strings(types)
+		return types, nil
+	}
+}
+
+// RegisterCatalogTools registers the tool that refreshes the catalog cache
+func RegisterCatalogTools(s *server.MCPServer, client *gobizfly.Client) {
+	// Refresh catalog tool
+	refreshTool := mcp.NewTool(refreshCatalogTool,
+		mcp.WithDescription("Drop the cached flavors, images and volume types so the next lookup fetches them again. Use it after the catalog changed, e.g. a new custom image was uploaded"),
+		withCommonOptions(),
+		mcp.WithBoolean("all",
+			mcp.Description("Refresh the catalog of every profile and region instead of only the selected one"),
+		),
+	)
+	s.AddTool(refreshTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := clientFromContext(ctx, client)
+		catalog := catalogFromContext(ctx)
+		if catalog == nil {
+			return mcp.NewToolResultError("Failed to refresh catalog: catalog caching is disabled on this server"), nil
+		}
+
+		scope := "the selected profile and region"
+		all, _ := request.Params.Arguments["all"].(bool)
+		if all {
+			scope = "every profile and region"
+			client = nil
+		}
+		dropped := catalog.Invalidate(client)
+
+		kinds := strings.Join([]string{catalogFlavors, catalogOSImages, catalogCustomImages, catalogVolumeTypes}, ", ")
+		return actionResult(request, fmt.Sprintf("Catalog refreshed for %s: %d cached entries dropped (%s are fetched again on next use)", scope, dropped, kinds), ActionOutput{
+			Action: "refresh", ResourceType: "catalog",
+			Details: map[string]string{"scope": scope, "dropped": fmt.Sprint(dropped)},
+		}), nil
+	})
+}
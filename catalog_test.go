@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// countingFetch returns a fetch of the given flavors that counts its calls
+func countingFetch(calls *int, flavors []string) func(context.Context) ([]string, error) {
+	return func(ctx context.Context) ([]string, error) {
+		*calls++
+		return flavors, nil
+	}
+}
+
+func TestCatalogLookup(t *testing.T) {
+	ctx := context.Background()
+	hanoi, _ := gobizfly.NewClient()
+	hcm, _ := gobizfly.NewClient()
+
+	t.Run("caches per client until the entry expires", func(t *testing.T) {
+		catalog := NewCatalogCache(time.Minute)
+		now := time.Now()
+		catalog.now = func() time.Time { return now }
+		calls := 0
+
+		for i := 0; i < 3; i++ {
+			if _, err := catalogLookup(ctx, catalog, hanoi, catalogFlavors, countingFetch(&calls, []string{"nix.1c_1g"})); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if calls != 1 {
+			t.Errorf("Expected one fetch for repeated lookups, got %d", calls)
+		}
+
+		catalogLookup(ctx, catalog, hcm, catalogFlavors, countingFetch(&calls, []string{"nix.2c_2g"}))
+		if calls != 2 {
+			t.Errorf("Expected another client to have its own catalog, got %d fetches", calls)
+		}
+
+		now = now.Add(2 * time.Minute)
+		catalogLookup(ctx, catalog, hanoi, catalogFlavors, countingFetch(&calls, []string{"nix.1c_1g"}))
+		if calls != 3 {
+			t.Errorf("Expected an expired entry to be fetched again, got %d fetches", calls)
+		}
+	})
+
+	t.Run("invalidates one client or all", func(t *testing.T) {
+		catalog := NewCatalogCache(time.Minute)
+		calls := 0
+		catalogLookup(ctx, catalog, hanoi, catalogFlavors, countingFetch(&calls, nil))
+		catalogLookup(ctx, catalog, hanoi, catalogVolumeTypes, countingFetch(&calls, nil))
+		catalogLookup(ctx, catalog, hcm, catalogFlavors, countingFetch(&calls, nil))
+
+		if dropped := catalog.Invalidate(hanoi); dropped != 2 {
+			t.Errorf("Expected 2 entries dropped for the client, got %d", dropped)
+		}
+		catalogLookup(ctx, catalog, hcm, catalogFlavors, countingFetch(&calls, nil))
+		if calls != 3 {
+			t.Errorf("Expected the other client's catalog to stay cached, got %d fetches", calls)
+		}
+		if dropped := catalog.Invalidate(nil); dropped != 1 {
+			t.Errorf("Expected 1 entry dropped for all clients, got %d", dropped)
+		}
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		catalog := NewCatalogCache(time.Minute)
+		calls := 0
+		failing := func(ctx context.Context) ([]string, error) {
+			calls++
+			return nil, errors.New("service unavailable")
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := catalogLookup(ctx, catalog, hanoi, catalogFlavors, failing); err == nil {
+				t.Fatal("Expected the error to be returned")
+			}
+		}
+		if calls != 2 {
+			t.Errorf("Expected a failed lookup to be retried, got %d fetches", calls)
+		}
+	})
+
+	t.Run("always fetches without a cache", func(t *testing.T) {
+		calls := 0
+		catalogLookup(ctx, nil, hanoi, catalogFlavors, countingFetch(&calls, nil))
+		catalogLookup(ctx, NewCatalogCache(0), hanoi, catalogFlavors, countingFetch(&calls, nil))
+		if calls != 2 {
+			t.Errorf("Expected every lookup to fetch, got %d fetches", calls)
+		}
+	})
+}
+
+func TestRefreshCatalogTool(t *testing.T) {
+	client, _ := gobizfly.NewClient()
+	catalog := NewCatalogCache(time.Minute)
+	calls := 0
+	catalogLookup(context.Background(), catalog, client, catalogFlavors, countingFetch(&calls, nil))
+
+	s := server.NewMCPServer("BizflyCloud MCP Test", "1.0.0",
+		server.WithToolHandlerMiddleware(catalog.Middleware()),
+	)
+	RegisterCatalogTools(s, client)
+
+	verifyToolResult(t, callTool(t, s, refreshCatalogTool, map[string]interface{}{}), "1 cached entries dropped")
+	catalogLookup(context.Background(), catalog, client, catalogFlavors, countingFetch(&calls, nil))
+	if calls != 2 {
+		t.Errorf("Expected the refreshed catalog to be fetched again, got %d fetches", calls)
+	}
+
+	t.Run("without a cache", func(t *testing.T) {
+		s := createTestMCPServer()
+		RegisterCatalogTools(s, client)
+		verifyToolError(t, callTool(t, s, refreshCatalogTool, map[string]interface{}{}), "caching is disabled")
+	})
+}
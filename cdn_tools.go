@@ -16,27 +16,36 @@ func RegisterCDNTools(s *server.MCPServer, client *gobizfly.Client) {
 	// List CDN domains tool
 	listDomainsTool := mcp.NewTool("bizflycloud_list_cdn_domains",
 		mcp.WithDescription("List all Bizfly Cloud CDN domains"),
+		withCommonOptions(),
+		withListOptions(),
 	)
 	s.AddTool(listDomainsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		domains, err := client.CDN.List(ctx, &gobizfly.ListOptions{})
+		services := servicesFromContext(ctx, client)
+		query, err := parseListQuery(request.Params.Arguments)
 		if err != nil {
-			// Check if error is 404 or service not available
-			errStr := strings.ToLower(err.Error())
-			if strings.Contains(errStr, "404") || 
-			   strings.Contains(errStr, "not found") || 
-			   strings.Contains(errStr, "resource not found") ||
-			   strings.Contains(errStr, "<svg") ||
-			   strings.Contains(errStr, "<html") {
-				return mcp.NewToolResultText("Available CDN domains:\n\n(No CDN domains found or CDN service is not enabled)"), nil
+			return errorResult(ctx, "Failed to list CDN domains", err), nil
+		}
+		domains, err := services.CDN.List(ctx, &gobizfly.ListOptions{})
+		if err != nil {
+			// When the service isn't enabled for the account there is nothing to list
+			if isServiceUnavailable(ctx, err) {
+				return listResult(request, "Available CDN domains:\n\n(No CDN domains found or CDN service is not enabled)", "domains", []CDNDomainOutput{}), nil
 			}
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list CDN domains: %v", err)), nil
+			return errorResult(ctx, "Failed to list CDN domains", err), nil
+		}
+		var page listPage
+		if domains != nil {
+			domains.Domains, page = pageItems(query, domains.Domains, cdnDomainListFields)
 		}
 
 		result := "Available CDN domains:\n\n"
+		items := []CDNDomainOutput{}
 		if domains == nil || len(domains.Domains) == 0 {
 			result += "(No CDN domains found)\n"
 		} else {
-			for _, domain := range domains.Domains {
+			for i := range domains.Domains {
+				domain := &domains.Domains[i]
+				items = append(items, newCDNDomainOutput(domain))
 				result += fmt.Sprintf("Domain: %s\n", domain.Domain)
 				result += fmt.Sprintf("  ID: %s\n", domain.DomainID)
 				result += fmt.Sprintf("  Slug: %s\n", domain.Slug)
@@ -44,12 +53,13 @@ func RegisterCDNTools(s *server.MCPServer, client *gobizfly.Client) {
 				result += "\n"
 			}
 		}
-		return mcp.NewToolResultText(result), nil
+		return pageResult(request, result, "domains", items, page), nil
 	})
 
 	// Create CDN domain tool
 	createDomainTool := mcp.NewTool("bizflycloud_create_cdn_domain",
 		mcp.WithDescription("Create a new Bizfly Cloud CDN domain"),
+		withCommonOptions(),
 		mcp.WithString("domain",
 			mcp.Required(),
 			mcp.Description("Domain name for CDN (e.g., example.com)"),
@@ -67,6 +77,7 @@ func RegisterCDNTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(createDomainTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		domain, ok := request.Params.Arguments["domain"].(string)
 		if !ok {
 			return nil, errors.New("domain must be a string")
@@ -84,7 +95,7 @@ func RegisterCDNTools(s *server.MCPServer, client *gobizfly.Client) {
 			upstreamProto = "http"
 		}
 
-		resp, err := client.CDN.Create(ctx, &gobizfly.CreateDomainPayload{
+		resp, err := services.CDN.Create(ctx, &gobizfly.CreateDomainPayload{
 			Domain: domain,
 			Origin: &gobizfly.Origin{
 				Name:          domain,
@@ -94,7 +105,7 @@ func RegisterCDNTools(s *server.MCPServer, client *gobizfly.Client) {
 			},
 		})
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create CDN domain: %v", err)), nil
+			return errorResult(ctx, "Failed to create CDN domain", err), nil
 		}
 
 		result := fmt.Sprintf("CDN domain created successfully:\n")
@@ -102,25 +113,29 @@ func RegisterCDNTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += fmt.Sprintf("  ID: %s\n", resp.Domain.DomainID)
 		result += fmt.Sprintf("  CDN Domain: %s\n", resp.Domain.DomainCDN)
 		result += fmt.Sprintf("  Message: %s\n", resp.Message)
-		return mcp.NewToolResultText(result), nil
+		output := newCDNDomainOutput(&resp.Domain)
+		output.Message = resp.Message
+		return formatResult(request, result, output), nil
 	})
 
 	// Get CDN domain tool
 	getDomainTool := mcp.NewTool("bizflycloud_get_cdn_domain",
 		mcp.WithDescription("Get details of a Bizfly Cloud CDN domain"),
+		withCommonOptions(),
 		mcp.WithString("domain_id",
 			mcp.Required(),
 			mcp.Description("ID of the CDN domain"),
 		),
 	)
 	s.AddTool(getDomainTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		domainID, ok := request.Params.Arguments["domain_id"].(string)
 		if !ok {
 			return nil, errors.New("domain_id must be a string")
 		}
-		domain, err := client.CDN.Get(ctx, domainID)
+		domain, err := services.CDN.Get(ctx, domainID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get CDN domain: %v", err)), nil
+			return errorResult(ctx, "Failed to get CDN domain", err), nil
 		}
 
 		result := fmt.Sprintf("CDN Domain Details:\n\n")
@@ -128,12 +143,14 @@ func RegisterCDNTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += fmt.Sprintf("ID: %s\n", domain.DomainID)
 		result += fmt.Sprintf("Slug: %s\n", domain.Slug)
 		result += fmt.Sprintf("CDN Domain: %s\n", domain.DomainCDN)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newCDNDomainOutput(domain)), nil
 	})
 
 	// Update CDN domain tool
 	updateDomainTool := mcp.NewTool("bizflycloud_update_cdn_domain",
 		mcp.WithDescription("Update a Bizfly Cloud CDN domain"),
+		withCommonOptions(),
+		withDryRun(),
 		mcp.WithString("domain_id",
 			mcp.Required(),
 			mcp.Description("ID of the CDN domain to update"),
@@ -146,6 +163,7 @@ func RegisterCDNTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(updateDomainTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		domainID, ok := request.Params.Arguments["domain_id"].(string)
 		if !ok {
 			return nil, errors.New("domain_id must be a string")
@@ -161,40 +179,51 @@ func RegisterCDNTools(s *server.MCPServer, client *gobizfly.Client) {
 			}
 		}
 
-		resp, err := client.CDN.Update(ctx, domainID, payload)
+		if isDryRun(request) {
+			target := fmt.Sprintf("CDN domain %s", domainID)
+			return dryRunResult(request, "bizflycloud_update_cdn_domain", target, payload, validateCDNDomainUpdate(payload)), nil
+		}
+
+		resp, err := services.CDN.Update(ctx, domainID, payload)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to update CDN domain: %v", err)), nil
+			return errorResult(ctx, "Failed to update CDN domain", err), nil
 		}
 
 		result := fmt.Sprintf("CDN domain updated successfully:\n")
 		result += fmt.Sprintf("  Domain: %s\n", resp.Domain.Domain)
 		result += fmt.Sprintf("  Message: %s\n", resp.Message)
-		return mcp.NewToolResultText(result), nil
+		output := newCDNDomainOutput(&resp.Domain.Domain)
+		output.Message = resp.Message
+		return formatResult(request, result, output), nil
 	})
 
 	// Delete CDN domain tool
 	deleteDomainTool := mcp.NewTool("bizflycloud_delete_cdn_domain",
 		mcp.WithDescription("Delete a Bizfly Cloud CDN domain"),
+		withCommonOptions(),
+		withConfirmation(),
 		mcp.WithString("domain_id",
 			mcp.Required(),
 			mcp.Description("ID of the CDN domain to delete"),
 		),
 	)
 	s.AddTool(deleteDomainTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		domainID, ok := request.Params.Arguments["domain_id"].(string)
 		if !ok {
 			return nil, errors.New("domain_id must be a string")
 		}
-		err := client.CDN.Delete(ctx, domainID)
+		err := services.CDN.Delete(ctx, domainID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete CDN domain: %v", err)), nil
+			return errorResult(ctx, "Failed to delete CDN domain", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("CDN domain %s deleted successfully", domainID)), nil
+		return actionResult(request, fmt.Sprintf("CDN domain %s deleted successfully", domainID), ActionOutput{Action: "delete", ResourceType: "cdn_domain", ResourceID: domainID}), nil
 	})
 
 	// Delete CDN cache tool
 	deleteCacheTool := mcp.NewTool("bizflycloud_delete_cdn_cache",
 		mcp.WithDescription("Delete cache for a Bizfly Cloud CDN domain"),
+		withCommonOptions(),
 		mcp.WithString("domain_id",
 			mcp.Required(),
 			mcp.Description("ID of the CDN domain"),
@@ -204,6 +233,7 @@ func RegisterCDNTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(deleteCacheTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		domainID, ok := request.Params.Arguments["domain_id"].(string)
 		if !ok {
 			return nil, errors.New("domain_id must be a string")
@@ -225,11 +255,45 @@ func RegisterCDNTools(s *server.MCPServer, client *gobizfly.Client) {
 			}
 		}
 
-		err := client.CDN.DeleteCache(ctx, domainID, files)
+		err := services.CDN.DeleteCache(ctx, domainID, files)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete CDN cache: %v", err)), nil
+			return errorResult(ctx, "Failed to delete CDN cache", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("CDN cache for domain %s deleted successfully", domainID)), nil
+		return actionResult(request, fmt.Sprintf("CDN cache for domain %s deleted successfully", domainID), ActionOutput{
+			Action: "purge_cache", ResourceType: "cdn_domain", ResourceID: domainID,
+			Details: map[string]string{"files": strings.Join(files.Files, ",")},
+		}), nil
 	})
 }
 
+// previewCDNDomainDeletion describes the CDN domain
+func previewCDNDomainDeletion(ctx context.Context, services *Services, args map[string]interface{}) (string, error) {
+	domainID, err := stringArg(args, "domain_id")
+	if err != nil {
+		return "", err
+	}
+	domain, err := services.CDN.Get(ctx, domainID)
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("CDN Domain: %s\n", domain.Domain)
+	result += fmt.Sprintf("  ID: %s\n", domain.DomainID)
+	result += fmt.Sprintf("  CDN Domain: %s\n", domain.DomainCDN)
+	return result, nil
+}
+
+// validateCDNDomainUpdate checks a CDN domain update request before it is sent
+func validateCDNDomainUpdate(payload *gobizfly.UpdateDomainPayload) *requestValidation {
+	v := &requestValidation{}
+	v.check(payload.Origin != nil, "origin is changed (upstream_addrs and upstream_proto are both required)")
+	if payload.Origin != nil {
+		v.check(payload.Origin.UpstreamProto == "http" || payload.Origin.UpstreamProto == "https", "upstream protocol is http or https")
+	}
+	return v
+}
+
+// cdnDomainListFields are the fields list_cdn_domains filters and sorts on
+func cdnDomainListFields(domain gobizfly.Domain) listFields {
+	return listFields{Name: domain.Domain}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bizflycloud/gobizfly"
+	"github.com/bizflycloud/gobizfly/constants"
+	"github.com/bizflycloud/gobizfly/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Context keys for the client, profile and pool selected for a tool call
+type (
+	clientContextKey  struct{}
+	profileContextKey struct{}
+	poolContextKey    struct{}
+)
+
+// ClientPool lazily builds one authenticated gobizfly client per profile and
+// region. Every client keeps its own token fresh.
+type ClientPool struct {
+	mu        sync.Mutex
+	config    *Config
+	clients   map[string]*pooledClient
+	calls     *callContexts
+	transport http.RoundTripper
+}
+
+// pooledClient is a client of the pool. client and err are set once ready is closed.
+type pooledClient struct {
+	client  *gobizfly.Client
+	tokens  *TokenManager
+	options []gobizfly.Option
+	ready   chan struct{}
+	err     error
+}
+
+// NewClientPool creates a client pool; no client is built until it is first requested
+func NewClientPool(config *Config) *ClientPool {
+	return &ClientPool{
+		config:  config,
+		clients: make(map[string]*pooledClient),
+		calls:   &callContexts{contexts: make(map[uint64]context.Context)},
+	}
+}
+
+// Config returns the profiles the pool builds clients for
+func (p *ClientPool) Config() *Config {
+	return p.config
+}
+
+// SetTransport sets the transport the clients built from now on send their API requests through
+func (p *ClientPool) SetTransport(transport http.RoundTripper) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.transport = transport
+}
+
+// Client returns the authenticated client for the profile and region, building
+// it on first use. Empty values select the default profile and its region.
+// Concurrent first uses share one login, which holds up no other client.
+func (p *ClientPool) Client(ctx context.Context, profileName, region string) (*gobizfly.Client, error) {
+	profile, err := p.config.Profile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	if region == "" {
+		region = profile.Region
+	}
+	regionName, err := utils.ParseRegionName(region)
+	if err != nil {
+		return nil, invalidArgument(fmt.Errorf("unknown region %q (available: %s)", region, strings.Join(KnownRegions(), ", ")))
+	}
+
+	key := clientKey(profile.Name, regionName)
+	p.mu.Lock()
+	pooled, ok := p.clients[key]
+	if !ok {
+		// Calls for the same profile and region wait for this one's login;
+		// the others don't wait at all
+		pooled = &pooledClient{
+			tokens:  NewTokenManager(profile.Credentials),
+			options: []gobizfly.Option{gobizfly.WithAPIURL(profile.APIURL), gobizfly.WithRegionName(regionName)},
+			ready:   make(chan struct{}),
+		}
+		if p.transport != nil {
+			pooled.tokens.next = p.transport
+		}
+		pooled.options = append(pooled.options, gobizfly.WithHTTPClient(&http.Client{Transport: &callTransport{calls: p.calls, next: pooled.tokens}}))
+		p.clients[key] = pooled
+	}
+	p.mu.Unlock()
+
+	if !ok {
+		pooled.err = pooled.connect(ctx, profile.Name, regionName)
+		if pooled.err != nil {
+			// A failed login isn't kept, so the next call tries again
+			p.mu.Lock()
+			delete(p.clients, key)
+			p.mu.Unlock()
+		}
+		close(pooled.ready)
+	}
+	select {
+	case <-pooled.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if pooled.err != nil {
+		return nil, pooled.err
+	}
+	return pooled.client, nil
+}
+
+// connect builds and authenticates the pooled client
+func (c *pooledClient) connect(ctx context.Context, profileName, regionName string) error {
+	client, err := gobizfly.NewClient(c.options...)
+	if err != nil {
+		return fmt.Errorf("failed to create client for profile %s in region %s: %w", profileName, regionName, err)
+	}
+	if err := c.tokens.Authenticate(ctx, client); err != nil {
+		return fmt.Errorf("failed to authenticate profile %s in region %s: %w", profileName, regionName, err)
+	}
+	c.client = client
+	return nil
+}
+
+// connected reports whether the pooled client is built and authenticated
+func (c *pooledClient) connected() bool {
+	select {
+	case <-c.ready:
+		return c.err == nil
+	default:
+		return false
+	}
+}
+
+// bind makes the API requests the calling goroutine sends through the pool's
+// clients carry ctx, until the tool call it runs ends
+func (p *ClientPool) bind(ctx context.Context) {
+	p.calls.enter(ctx)
+}
+
+// callContexts are the contexts of the tool calls using the pool's clients, by
+// the goroutine running each call. gobizfly builds its requests without a
+// context, so without them the cancellation, retry count and API status of a
+// tool call wouldn't reach the transports; it sends them from the goroutine of
+// the call, though.
+type callContexts struct {
+	mu       sync.Mutex
+	contexts map[uint64]context.Context
+}
+
+// enter sets the context of the call running on the calling goroutine
+func (c *callContexts) enter(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.contexts[goroutineID()] = ctx
+}
+
+// leave forgets the context of the call running on the calling goroutine
+func (c *callContexts) leave() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.contexts, goroutineID())
+}
+
+// current returns the context of the call running on the calling goroutine
+func (c *callContexts) current() (context.Context, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ctx, ok := c.contexts[goroutineID()]
+	return ctx, ok
+}
+
+// goroutineID returns the ID of the calling goroutine, which the runtime only
+// reveals in the header of its stack trace
+func goroutineID() uint64 {
+	var buf [64]byte
+	stack := strings.TrimPrefix(string(buf[:runtime.Stack(buf[:], false)]), "goroutine ")
+	id, _ := strconv.ParseUint(stack[:strings.IndexByte(stack, ' ')], 10, 64)
+	return id
+}
+
+// callTransport sends the requests of the pool's clients with the context of
+// the tool call sending them, if any
+type callTransport struct {
+	calls *callContexts
+	next  http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *callTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if ctx, ok := t.calls.current(); ok {
+		req = req.WithContext(ctx)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// Connected reports whether a client for the profile and region has already been built
+func (p *ClientPool) Connected(profileName, region string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pooled, ok := p.clients[clientKey(profileName, region)]
+	return ok && pooled.connected()
+}
+
+// Middleware selects the client for each tool call from its optional profile
+// and region arguments and hands it to the handler through the context
+func (p *ClientPool) Middleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx = withAPIStatus(ctx)
+			profileName, _ := request.Params.Arguments["profile"].(string)
+			profile, err := p.config.Profile(profileName)
+			if err != nil {
+				return errorResult(ctx, "Failed to select profile", invalidArgument(err)), nil
+			}
+			region, _ := request.Params.Arguments["region"].(string)
+			client, err := p.Client(ctx, profile.Name, region)
+			if err != nil {
+				// An unknown region is the caller's mistake; anything else
+				// failed while logging in to the profile
+				action := fmt.Sprintf("Failed to connect to profile %s", profile.Name)
+				if classifyError(ctx, err).Code == ErrorValidation {
+					action = "Failed to select region"
+				}
+				return errorResult(ctx, action, err), nil
+			}
+			ctx = context.WithValue(ctx, profileContextKey{}, profile)
+			ctx = context.WithValue(ctx, poolContextKey{}, p)
+			defer p.calls.leave()
+			return next(context.WithValue(ctx, clientContextKey{}, client), request)
+		}
+	}
+}
+
+// clientFromContext returns the client selected for the current tool call,
+// or the fallback when the call didn't go through the client pool
+func clientFromContext(ctx context.Context, fallback *gobizfly.Client) *gobizfly.Client {
+	if client, ok := ctx.Value(clientContextKey{}).(*gobizfly.Client); ok && client != nil {
+		return client
+	}
+	return fallback
+}
+
+// profileFromContext returns the profile selected for the current tool call, or nil
+func profileFromContext(ctx context.Context) *Profile {
+	profile, _ := ctx.Value(profileContextKey{}).(*Profile)
+	return profile
+}
+
+// availabilityZoneFromContext returns the selected profile's default
+// availability zone, or the fallback when it doesn't set one
+func availabilityZoneFromContext(ctx context.Context, fallback string) string {
+	if profile := profileFromContext(ctx); profile != nil && profile.AvailabilityZone != "" {
+		return profile.AvailabilityZone
+	}
+	return fallback
+}
+
+// withCommonOptions adds the arguments that every tool accepts
+func withCommonOptions() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		withProfileOption()(t)
+		mcp.WithString("region",
+			mcp.Description("Region to run against (e.g. HaNoi, HoChiMinh). Defaults to the profile's region"),
+		)(t)
+		withFormatOption()(t)
+	}
+}
+
+// withProfileOption adds the profile argument
+func withProfileOption() mcp.ToolOption {
+	return mcp.WithString("profile",
+		mcp.Description("Named account profile to use (see bizflycloud_list_profiles). Defaults to the server's default profile"),
+	)
+}
+
+func clientKey(profileName, regionName string) string {
+	return profileName + "/" + regionName
+}
+
+// KnownRegions returns the canonical names of the regions gobizfly supports
+func KnownRegions() []string {
+	seen := make(map[string]bool)
+	regions := []string{}
+	for _, region := range constants.RegionMapping {
+		if !seen[region] {
+			seen[region] = true
+			regions = append(regions, region)
+		}
+	}
+	sort.Strings(regions)
+	return regions
+}
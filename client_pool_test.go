@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bizflycloud-mcp-server/internal/fakecloud"
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func newTestClientPool(t *testing.T) *ClientPool {
+	t.Helper()
+	keystone := &fakeKeystone{expiresIn: time.Hour}
+	ts := newFakeKeystoneServer(t, keystone)
+	t.Cleanup(ts.Close)
+	creds := &Credentials{
+		Method:   authMethodPassword,
+		Username: "user",
+		Password: "secret",
+	}
+	return NewClientPool(&Config{
+		DefaultProfile: "prod",
+		Profiles: map[string]*Profile{
+			"prod":    {Name: "prod", Region: "HaNoi", APIURL: ts.URL, Credentials: creds},
+			"staging": {Name: "staging", Region: "HoChiMinh", APIURL: ts.URL, AvailabilityZone: "HCM1", Credentials: creds},
+		},
+	})
+}
+
+func TestKnownRegions(t *testing.T) {
+	regions := KnownRegions()
+	for _, want := range []string{"HaNoi", "HoChiMinh"} {
+		found := false
+		for _, region := range regions {
+			if region == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected %s in known regions, got %v", want, regions)
+		}
+	}
+}
+
+func TestClientPoolClient(t *testing.T) {
+	pool := newTestClientPool(t)
+	ctx := context.Background()
+
+	t.Run("default region is built once", func(t *testing.T) {
+		first, err := pool.Client(ctx, "", "")
+		if err != nil {
+			t.Fatalf("Expected default client, got %v", err)
+		}
+		second, err := pool.Client(ctx, "prod", "hn")
+		if err != nil {
+			t.Fatalf("Expected client for alias, got %v", err)
+		}
+		if first != second {
+			t.Error("Expected region aliases to share the same client")
+		}
+		if !pool.Connected("prod", "HaNoi") {
+			t.Error("Expected HaNoi to be connected")
+		}
+	})
+
+	t.Run("unknown region", func(t *testing.T) {
+		_, err := pool.Client(ctx, "", "Atlantis")
+		if err == nil || !contains(err.Error(), "HoChiMinh") {
+			t.Errorf("Expected unknown region error listing regions, got %v", err)
+		}
+	})
+
+	t.Run("profiles get their own clients", func(t *testing.T) {
+		prod, err := pool.Client(ctx, "prod", "HoChiMinh")
+		if err != nil {
+			t.Fatalf("Expected prod client, got %v", err)
+		}
+		staging, err := pool.Client(ctx, "staging", "")
+		if err != nil {
+			t.Fatalf("Expected staging client, got %v", err)
+		}
+		if prod == staging {
+			t.Error("Expected each profile to have its own client")
+		}
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := pool.Client(ctx, "qa", "")
+		if err == nil || !contains(err.Error(), "staging") {
+			t.Errorf("Expected unknown profile error listing profiles, got %v", err)
+		}
+	})
+}
+
+func TestClientPoolMiddleware(t *testing.T) {
+	pool := newTestClientPool(t)
+	defaultClient, err := pool.Client(context.Background(), "", "")
+	if err != nil {
+		t.Fatalf("Expected default client, got %v", err)
+	}
+
+	var selected *gobizfly.Client
+	var zone string
+	handler := pool.Middleware()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		selected = clientFromContext(ctx, nil)
+		zone = availabilityZoneFromContext(ctx, "HN1")
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	t.Run("region argument selects another client", func(t *testing.T) {
+		request := createTestMCPRequest("bizflycloud_list_servers", map[string]interface{}{
+			"region": "HoChiMinh",
+		})
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		verifyToolResult(t, result, "ok")
+		if selected == nil || selected == defaultClient {
+			t.Error("Expected the HoChiMinh client to be selected")
+		}
+	})
+
+	t.Run("missing region uses default client", func(t *testing.T) {
+		request := createTestMCPRequest("bizflycloud_list_servers", map[string]interface{}{})
+		if _, err := handler(context.Background(), request); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if selected != defaultClient {
+			t.Error("Expected the default client to be selected")
+		}
+		if zone != "HN1" {
+			t.Errorf("Expected fallback availability zone, got %s", zone)
+		}
+	})
+
+	t.Run("profile argument selects the profile's client and zone", func(t *testing.T) {
+		request := createTestMCPRequest("bizflycloud_list_servers", map[string]interface{}{
+			"profile": "staging",
+		})
+		if _, err := handler(context.Background(), request); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if selected == nil || selected == defaultClient {
+			t.Error("Expected the staging client to be selected")
+		}
+		if zone != "HCM1" {
+			t.Errorf("Expected the staging availability zone, got %s", zone)
+		}
+	})
+
+	t.Run("unknown profile is a tool error", func(t *testing.T) {
+		request := createTestMCPRequest("bizflycloud_list_servers", map[string]interface{}{
+			"profile": "qa",
+		})
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		verifyToolError(t, result, "Failed to select profile")
+	})
+
+	t.Run("unknown region is a tool error", func(t *testing.T) {
+		request := createTestMCPRequest("bizflycloud_list_servers", map[string]interface{}{
+			"region": "Atlantis",
+		})
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		verifyToolError(t, result, "Failed to select region")
+	})
+}
+
+func TestClientPoolMiddlewareLoginFailure(t *testing.T) {
+	cloud := fakecloud.New(testCloudState())
+	cloud.InjectFailure(fakecloud.Failure{Method: http.MethodPost, Path: "/api/token", Status: http.StatusUnauthorized})
+	pool := NewClientPool(NewMockConfig())
+	pool.SetTransport(cloud)
+	handler := pool.Middleware()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	result, err := handler(context.Background(), createTestMCPRequest("bizflycloud_list_servers", map[string]interface{}{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	verifyToolError(t, result, "Failed to connect to profile mock")
+	if strings.Contains(getTextFromResult(result), "Failed to select region") {
+		t.Errorf("Expected a login failure not to blame the region, got: %s", getTextFromResult(result))
+	}
+}
+
+// gatedTransport holds token requests until its gate is closed
+type gatedTransport struct {
+	gate   chan struct{}
+	logins atomic.Int32
+	next   http.RoundTripper
+}
+
+func (g *gatedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if isTokenRequest(req) {
+		g.logins.Add(1)
+		<-g.gate
+	}
+	return g.next.RoundTrip(req)
+}
+
+func TestClientPoolConcurrentLogins(t *testing.T) {
+	cloud := fakecloud.New(testCloudState())
+	pool := NewClientPool(NewMockConfig())
+	pool.SetTransport(cloud)
+	hanoi := newMockClient(t, pool)
+	gated := &gatedTransport{gate: make(chan struct{}), next: cloud}
+	pool.SetTransport(gated)
+
+	var wg sync.WaitGroup
+	clients := make([]*gobizfly.Client, 5)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client, err := pool.Client(context.Background(), "", "HoChiMinh")
+			if err != nil {
+				t.Errorf("Expected the HoChiMinh client, got %v", err)
+			}
+			clients[i] = client
+		}(i)
+	}
+	for gated.logins.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The pending login doesn't hold up the client that is already connected
+	done := make(chan *gobizfly.Client)
+	go func() {
+		client, _ := pool.Client(context.Background(), "", "HaNoi")
+		done <- client
+	}()
+	select {
+	case client := <-done:
+		if client != hanoi {
+			t.Error("Expected the connected HaNoi client")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the HaNoi client while HoChiMinh logs in")
+	}
+	if pool.Connected(mockProfileName, "HoChiMinh") {
+		t.Error("Expected HoChiMinh not to be connected before its login finished")
+	}
+
+	close(gated.gate)
+	wg.Wait()
+	if logins := gated.logins.Load(); logins != 1 {
+		t.Errorf("Expected concurrent calls to share one login, got %d", logins)
+	}
+	for _, client := range clients {
+		if client == nil || client != clients[0] {
+			t.Fatal("Expected every call to get the same HoChiMinh client")
+		}
+	}
+}
+
+func TestClientPoolBindsCalls(t *testing.T) {
+	cloud := fakecloud.New(testCloudState())
+	cloud.RecordRequests()
+	pool := NewClientPool(NewMockConfig())
+	pool.SetTransport(cloud)
+	s := server.NewMCPServer("BizflyCloud MCP Test", "1.0.0", server.WithToolHandlerMiddleware(pool.Middleware()))
+	registerTools(s, newMockClient(t, pool), pool, nil)
+	for i := 0; i < 3; i++ {
+		if result := callTool(t, s, "bizflycloud_list_servers", nil); result.IsError {
+			t.Fatalf("list_servers failed: %s", getTextFromResult(result))
+		}
+	}
+	// Every call uses the pooled client, authenticated once
+	counts := make(map[string]int)
+	for _, request := range cloud.Requests() {
+		counts[request]++
+	}
+	if counts["POST /api/token"] != 1 || counts["GET /api/auth/service"] != 1 {
+		t.Errorf("Expected a single authentication, got %v", cloud.Requests())
+	}
+	if counts["GET /iaas-cloud/api/servers"] != 3 {
+		t.Errorf("Expected every call to list the servers, got %v", cloud.Requests())
+	}
+	// The contexts of finished calls are forgotten
+	if _, ok := pool.calls.current(); ok || len(pool.calls.contexts) != 0 {
+		t.Errorf("Expected no call context left, got %d", len(pool.calls.contexts))
+	}
+}
+
+// roundTripFunc is a transport answering with a function
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestCallContextsPerGoroutine(t *testing.T) {
+	type key struct{}
+	calls := &callContexts{contexts: make(map[uint64]context.Context)}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			calls.enter(context.WithValue(context.Background(), key{}, i))
+			defer calls.leave()
+			transport := &callTransport{calls: calls, next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				if got := req.Context().Value(key{}); got != i {
+					t.Errorf("Expected the request of call %d to carry its context, got %v", i, got)
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			})}
+			req, _ := http.NewRequest(http.MethodGet, "https://manage.bizflycloud.vn/api", nil)
+			transport.RoundTrip(req)
+		}(i)
+	}
+	wg.Wait()
+	if len(calls.contexts) != 0 {
+		t.Errorf("Expected every call to leave, got %d contexts", len(calls.contexts))
+	}
+}
+
+func TestClientFromContextFallback(t *testing.T) {
+	fallback, _ := gobizfly.NewClient()
+	if got := clientFromContext(context.Background(), fallback); got != fallback {
+		t.Error("Expected fallback client when none is selected")
+	}
+}
+
+func TestRegisterRegionTools(t *testing.T) {
+	s := createTestMCPServer()
+	pool := newTestClientPool(t)
+
+	// Should not panic
+	RegisterRegionTools(s, pool)
+	RegisterProfileTools(s, pool)
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultProfileName names the profile built from environment variables when no config file is used
+const defaultProfileName = "default"
+
+// Config is the set of named account profiles the server can act as, plus
+// the server-wide tool selection
+type Config struct {
+	DefaultProfile string              `yaml:"default_profile" toml:"default_profile"`
+	Profiles       map[string]*Profile `yaml:"profiles" toml:"profiles"`
+	// Tools selects which service groups and tools are registered
+	Tools ToolFilter `yaml:"tools" toml:"tools"`
+	// Path is the file the config was loaded from, empty when built from the environment
+	Path string `yaml:"-" toml:"-"`
+}
+
+// Profile is one Bizfly account: its credentials and where it runs by default.
+// Credential keys are the same as in a secrets file, so auth_method, username,
+// app_credential_id and so on map onto the BIZFLY_* variables.
+type Profile struct {
+	Name                string `yaml:"-" toml:"-"`
+	AuthMethod          string `yaml:"auth_method" toml:"auth_method"`
+	Username            string `yaml:"username" toml:"username"`
+	Password            string `yaml:"password" toml:"password"`
+	AppCredentialID     string `yaml:"app_credential_id" toml:"app_credential_id"`
+	AppCredentialSecret string `yaml:"app_credential_secret" toml:"app_credential_secret"`
+	ProjectID           string `yaml:"project_id" toml:"project_id"`
+	TokenFile           string `yaml:"token_file" toml:"token_file"`
+	SecretsFile         string `yaml:"secrets_file" toml:"secrets_file"`
+	Region              string `yaml:"region" toml:"region"`
+	APIURL              string `yaml:"api_url" toml:"api_url"`
+	AvailabilityZone    string `yaml:"availability_zone" toml:"availability_zone"`
+
+	Credentials *Credentials `yaml:"-" toml:"-"`
+}
+
+// LoadConfig reads named profiles from a YAML or TOML file. The format is
+// picked from the extension (.toml, otherwise YAML), and relative token and
+// secrets file paths are resolved against the config file's directory.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{Path: path}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		meta, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("failed to parse %s: unknown key %q", path, undecoded[0].String())
+		}
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+
+	dir := filepath.Dir(path)
+	for name, profile := range cfg.Profiles {
+		if profile == nil {
+			return nil, fmt.Errorf("profile %q in %s is empty", name, path)
+		}
+		profile.Name = name
+		profile.TokenFile = resolvePath(dir, profile.TokenFile)
+		profile.SecretsFile = resolvePath(dir, profile.SecretsFile)
+		creds, err := loadCredentials(profile.lookup, fmt.Sprintf("profile %q in %s", name, path))
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %w", name, err)
+		}
+		profile.Credentials = creds
+	}
+	if err := cfg.finish(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.Tools.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: tools: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ConfigFromEnv builds a config holding a single profile from the BIZFLY_*
+// environment variables, for deployments that don't use a config file
+func ConfigFromEnv(getenv func(string) string) (*Config, error) {
+	creds, err := LoadCredentials(getenv)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{
+		DefaultProfile: defaultProfileName,
+		Profiles: map[string]*Profile{
+			defaultProfileName: {
+				Name:             defaultProfileName,
+				AuthMethod:       creds.Method,
+				Region:           getenv("BIZFLY_REGION"),
+				APIURL:           getenv("BIZFLY_API_URL"),
+				AvailabilityZone: getenv("BIZFLY_AVAILABILITY_ZONE"),
+				Credentials:      creds,
+			},
+		},
+	}
+	if err := cfg.finish(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Profile returns the named profile, or the default profile when name is empty
+func (c *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	return profile, nil
+}
+
+// ProfileNames returns the sorted names of the configured profiles
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// finish fills in defaults and picks the default profile
+func (c *Config) finish() error {
+	if len(c.Profiles) == 0 {
+		return errors.New("no profiles configured")
+	}
+	for _, profile := range c.Profiles {
+		if profile.Region == "" {
+			profile.Region = defaultRegion
+		}
+		if profile.APIURL == "" {
+			profile.APIURL = defaultAPIURL
+		}
+	}
+
+	if c.DefaultProfile == "" {
+		switch {
+		case len(c.Profiles) == 1:
+			c.DefaultProfile = c.ProfileNames()[0]
+		case c.Profiles[defaultProfileName] != nil:
+			c.DefaultProfile = defaultProfileName
+		default:
+			return fmt.Errorf("default_profile must be set when several profiles are configured (available: %s)", strings.Join(c.ProfileNames(), ", "))
+		}
+	}
+	if _, ok := c.Profiles[c.DefaultProfile]; !ok {
+		return fmt.Errorf("default profile %q is not configured (available: %s)", c.DefaultProfile, strings.Join(c.ProfileNames(), ", "))
+	}
+	return nil
+}
+
+// lookup exposes the profile's credentials under their BIZFLY_* names so
+// they go through the same resolution as environment variables
+func (p *Profile) lookup(key string) string {
+	switch key {
+	case "BIZFLY_AUTH_METHOD":
+		return p.AuthMethod
+	case "BIZFLY_USERNAME":
+		return p.Username
+	case "BIZFLY_PASSWORD":
+		return p.Password
+	case "BIZFLY_APP_CREDENTIAL_ID":
+		return p.AppCredentialID
+	case "BIZFLY_APP_CREDENTIAL_SECRET":
+		return p.AppCredentialSecret
+	case "BIZFLY_PROJECT_ID":
+		return p.ProjectID
+	case "BIZFLY_TOKEN_FILE":
+		return p.TokenFile
+	case "BIZFLY_SECRETS_FILE":
+		return p.SecretsFile
+	}
+	return ""
+}
+
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
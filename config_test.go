@@ -0,0 +1,176 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+default_profile: prod
+profiles:
+  prod:
+    username: ops@example.com
+    password: secret
+    region: HoChiMinh
+    availability_zone: HCM1
+  staging:
+    app_credential_id: cred-id
+    app_credential_secret: cred-secret
+    project_id: project-1
+    api_url: https://staging.example.com
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	prod, err := cfg.Profile("")
+	if err != nil {
+		t.Fatalf("Expected default profile, got %v", err)
+	}
+	if prod.Name != "prod" || prod.Region != "HoChiMinh" || prod.AvailabilityZone != "HCM1" {
+		t.Errorf("Unexpected prod profile: %+v", prod)
+	}
+	if prod.APIURL != defaultAPIURL {
+		t.Errorf("Expected default API URL, got %s", prod.APIURL)
+	}
+	if prod.Credentials.Method != authMethodPassword || prod.Credentials.Username != "ops@example.com" {
+		t.Errorf("Unexpected prod credentials: %+v", prod.Credentials)
+	}
+
+	staging, err := cfg.Profile("staging")
+	if err != nil {
+		t.Fatalf("Expected staging profile, got %v", err)
+	}
+	if staging.Region != defaultRegion || staging.APIURL != "https://staging.example.com" {
+		t.Errorf("Unexpected staging profile: %+v", staging)
+	}
+	if staging.Credentials.Method != authMethodAppCredential || staging.Credentials.ProjectID != "project-1" {
+		t.Errorf("Unexpected staging credentials: %+v", staging.Credentials)
+	}
+}
+
+func TestLoadConfigTOML(t *testing.T) {
+	path := writeConfigFile(t, "config.toml", `
+[profiles.sandbox]
+username = "user"
+password = "secret"
+region = "hn"
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.DefaultProfile != "sandbox" {
+		t.Errorf("Expected the only profile to be the default, got %q", cfg.DefaultProfile)
+	}
+}
+
+func TestLoadConfigResolvesRelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "token"), []byte("pre-issued"), 0600); err != nil {
+		t.Fatalf("Failed to write token: %v", err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	content := "profiles:\n  ci:\n    token_file: token\n    project_id: project-1\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	profile, _ := cfg.Profile("ci")
+	if profile.Credentials.TokenFile != filepath.Join(dir, "token") {
+		t.Errorf("Expected token file next to the config, got %s", profile.Credentials.TokenFile)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "unknown key",
+			file:    "config.yaml",
+			content: "profiles:\n  prod:\n    usernme: user\n",
+			wantErr: "usernme",
+		},
+		{
+			name:    "unknown toml key",
+			file:    "config.toml",
+			content: "[profiles.prod]\nusernme = \"user\"\n",
+			wantErr: "usernme",
+		},
+		{
+			name:    "missing credentials",
+			file:    "config.yaml",
+			content: "profiles:\n  prod:\n    username: user\n",
+			wantErr: `profile "prod"`,
+		},
+		{
+			name:    "ambiguous default",
+			file:    "config.yaml",
+			content: "profiles:\n  a:\n    username: u\n    password: p\n  b:\n    username: u\n    password: p\n",
+			wantErr: "default_profile",
+		},
+		{
+			name:    "unknown default",
+			file:    "config.yaml",
+			content: "default_profile: c\nprofiles:\n  a:\n    username: u\n    password: p\n",
+			wantErr: `default profile "c"`,
+		},
+		{
+			name:    "no profiles",
+			file:    "config.yaml",
+			content: "default_profile: a\n",
+			wantErr: "no profiles",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfigFile(t, tt.file, tt.content))
+			if err == nil || !contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	cfg, err := ConfigFromEnv(envMap(map[string]string{
+		"BIZFLY_USERNAME":          "user",
+		"BIZFLY_PASSWORD":          "secret",
+		"BIZFLY_REGION":            "HoChiMinh",
+		"BIZFLY_AVAILABILITY_ZONE": "HCM1",
+	}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	profile, err := cfg.Profile("")
+	if err != nil {
+		t.Fatalf("Expected default profile, got %v", err)
+	}
+	if profile.Name != defaultProfileName || profile.Region != "HoChiMinh" || profile.AvailabilityZone != "HCM1" {
+		t.Errorf("Unexpected profile: %+v", profile)
+	}
+	if profile.APIURL != defaultAPIURL {
+		t.Errorf("Expected default API URL, got %s", profile.APIURL)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// confirmationTokenArg is the argument a destructive tool is called again with to run it
+	confirmationTokenArg = "confirmation_token"
+
+	defaultConfirmationTTL = 5 * time.Minute
+)
+
+// deletionPreviewFunc describes the resource a destructive tool call would delete
+type deletionPreviewFunc func(ctx context.Context, services *Services, args map[string]interface{}) (string, error)
+
+// deletionPreviews describes the resources behind each destructive tool. The
+// preview functions live next to the tools in the service files.
+var deletionPreviews = map[string]deletionPreviewFunc{
+	"bizflycloud_delete_server":                 previewServerDeletion,
+	"bizflycloud_delete_volume":                 previewVolumeDeletion,
+	"bizflycloud_delete_snapshot":               previewSnapshotDeletion,
+	"bizflycloud_delete_kubernetes_cluster":     previewKubernetesClusterDeletion,
+	"bizflycloud_delete_kubernetes_pool":        previewKubernetesPoolDeletion,
+	"bizflycloud_delete_database":               previewDatabaseDeletion,
+	"bizflycloud_delete_loadbalancer":           previewLoadBalancerDeletion,
+	"bizflycloud_delete_dns_zone":               previewDNSZoneDeletion,
+	"bizflycloud_delete_dns_record":             previewDNSRecordDeletion,
+	"bizflycloud_delete_cdn_domain":             previewCDNDomainDeletion,
+	"bizflycloud_delete_kms_certificate":        previewKMSCertificateDeletion,
+	"bizflycloud_delete_container_registry":     previewContainerRegistryDeletion,
+	"bizflycloud_delete_container_registry_tag": previewContainerRegistryTagDeletion,
+	"bizflycloud_delete_autoscaling_group":      previewAutoScalingGroupDeletion,
+}
+
+// pendingConfirmation is a destructive call that was previewed and may be confirmed once
+type pendingConfirmation struct {
+	session   string
+	tool      string
+	args      string
+	expiresAt time.Time
+}
+
+// ConfirmationStore makes destructive tools two-phase: the first call returns
+// a preview of what would be deleted plus a short-lived token, and only a
+// second call with the same arguments and that token runs the tool.
+type ConfirmationStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	pending map[string]pendingConfirmation
+	now     func() time.Time
+}
+
+// NewConfirmationStore creates a confirmation store whose tokens expire after ttl
+func NewConfirmationStore(ttl time.Duration) *ConfirmationStore {
+	if ttl <= 0 {
+		ttl = defaultConfirmationTTL
+	}
+	return &ConfirmationStore{
+		ttl:     ttl,
+		pending: make(map[string]pendingConfirmation),
+		now:     time.Now,
+	}
+}
+
+// Issue records a call previewed in the session and returns the token that confirms it
+func (c *ConfirmationStore) Issue(session, tool string, args map[string]interface{}) (string, time.Time, error) {
+	fingerprint, err := argsFingerprint(args)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(b)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for key, pending := range c.pending {
+		if now.After(pending.expiresAt) {
+			delete(c.pending, key)
+		}
+	}
+	expiresAt := now.Add(c.ttl)
+	c.pending[token] = pendingConfirmation{session: session, tool: tool, args: fingerprint, expiresAt: expiresAt}
+	return token, expiresAt, nil
+}
+
+// Redeem consumes the token if it confirms this exact call from the session
+// that previewed it. A token from another session is unknown to this one.
+func (c *ConfirmationStore) Redeem(session, token, tool string, args map[string]interface{}) error {
+	fingerprint, err := argsFingerprint(args)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pending, ok := c.pending[token]
+	if !ok || pending.session != session {
+		return errors.New("the confirmation token is unknown or was already used")
+	}
+	if c.now().After(pending.expiresAt) {
+		delete(c.pending, token)
+		return errors.New("the confirmation token has expired")
+	}
+	if pending.tool != tool || pending.args != fingerprint {
+		return fmt.Errorf("the confirmation token was issued for a different call (%s)", pending.tool)
+	}
+	delete(c.pending, token)
+	return nil
+}
+
+// ConfirmationOutput is the JSON preview of a destructive tool called without a confirmation token
+type ConfirmationOutput struct {
+	Tool              string `json:"tool"`
+	Preview           string `json:"preview"`
+	ConfirmationToken string `json:"confirmation_token"`
+	ExpiresAt         string `json:"expires_at"`
+}
+
+// Middleware intercepts destructive tool calls: without a confirmation token
+// it returns a preview and a token, with one it runs the tool if the token matches
+func (c *ConfirmationStore) Middleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			name := request.Params.Name
+			if !isDestructiveTool(name) {
+				return next(ctx, request)
+			}
+
+			if token, _ := request.Params.Arguments[confirmationTokenArg].(string); token != "" {
+				if err := c.Redeem(sessionIDFromContext(ctx), token, name, request.Params.Arguments); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Confirmation failed: %v. Call %s again without %s to get a new preview.", err, name, confirmationTokenArg)), nil
+				}
+				return next(ctx, request)
+			}
+
+			preview, err := previewDeletion(ctx, name, request.Params.Arguments)
+			if err != nil {
+				return errorResult(ctx, fmt.Sprintf("Failed to preview %s", name), err), nil
+			}
+			token, expiresAt, err := c.Issue(sessionIDFromContext(ctx), name, request.Params.Arguments)
+			if err != nil {
+				return errorResult(ctx, "Failed to issue confirmation token", err), nil
+			}
+
+			if outputFormat(request) == formatJSON {
+				return jsonResult(ConfirmationOutput{
+					Tool:              name,
+					Preview:           preview,
+					ConfirmationToken: token,
+					ExpiresAt:         expiresAt.UTC().Format(time.RFC3339),
+				}), nil
+			}
+
+			result := "Confirmation required. This will permanently delete:\n\n"
+			result += preview
+			result += "\nNothing has been deleted yet. To proceed, call " + name + " again with the same arguments and\n"
+			result += fmt.Sprintf("  %s: %s\n", confirmationTokenArg, token)
+			result += fmt.Sprintf("The token can be used once and expires at %s.\n", expiresAt.UTC().Format(time.RFC3339))
+			return mcp.NewToolResultText(result), nil
+		}
+	}
+}
+
+// withConfirmation adds the confirmation token argument to a destructive tool
+func withConfirmation() mcp.ToolOption {
+	return mcp.WithString(confirmationTokenArg,
+		mcp.Description("Token from the preview returned by a first call without it. The deletion only runs when the token is given"),
+	)
+}
+
+// previewDeletion describes what the destructive call would delete
+func previewDeletion(ctx context.Context, tool string, args map[string]interface{}) (string, error) {
+	services := servicesFromContext(ctx, nil)
+	preview, ok := deletionPreviews[tool]
+	if !ok || services == nil {
+		data, err := json.MarshalIndent(withoutConfirmationToken(args), "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Tool: %s\nArguments: %s\n", tool, data), nil
+	}
+	return preview(ctx, services, args)
+}
+
+// argsFingerprint serialises the call arguments, minus the token, so a token
+// only confirms the exact call that was previewed. Map keys are sorted by
+// encoding/json, which makes the fingerprint stable.
+func argsFingerprint(args map[string]interface{}) (string, error) {
+	data, err := json.Marshal(withoutConfirmationToken(args))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func withoutConfirmationToken(args map[string]interface{}) map[string]interface{} {
+	filtered := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		if key != confirmationTokenArg {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
+
+// stringArg returns a required string argument
+func stringArg(args map[string]interface{}, key string) (string, error) {
+	value, ok := args[key].(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", key)
+	}
+	return value, nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var confirmationTokenPattern = regexp.MustCompile(confirmationTokenArg + `: ([0-9a-f]+)`)
+
+func TestDeletionPreviewsCoverDestructiveTools(t *testing.T) {
+	for name := range toolCatalog {
+		if _, ok := deletionPreviews[name]; isDestructiveTool(name) && !ok {
+			t.Errorf("Destructive tool %s has no deletion preview", name)
+		}
+	}
+	for name := range deletionPreviews {
+		if !isDestructiveTool(name) {
+			t.Errorf("Deletion preview registered for %s, which is not destructive", name)
+		}
+	}
+}
+
+func TestConfirmationStore(t *testing.T) {
+	args := map[string]interface{}{"server_id": "server-123"}
+
+	t.Run("token confirms the previewed call once", func(t *testing.T) {
+		store := NewConfirmationStore(time.Minute)
+		token, _, err := store.Issue("session-1", "bizflycloud_delete_server", args)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		confirmed := map[string]interface{}{"server_id": "server-123", confirmationTokenArg: token}
+		if err := store.Redeem("session-1", token, "bizflycloud_delete_server", confirmed); err != nil {
+			t.Fatalf("Expected token to confirm the call, got %v", err)
+		}
+		if err := store.Redeem("session-1", token, "bizflycloud_delete_server", confirmed); err == nil {
+			t.Error("Expected token to be single use")
+		}
+	})
+
+	t.Run("token is bound to tool and arguments", func(t *testing.T) {
+		store := NewConfirmationStore(time.Minute)
+		token, _, _ := store.Issue("session-1", "bizflycloud_delete_server", args)
+		if err := store.Redeem("session-1", token, "bizflycloud_delete_server", map[string]interface{}{"server_id": "server-456"}); err == nil {
+			t.Error("Expected token to be rejected for different arguments")
+		}
+		if err := store.Redeem("session-1", token, "bizflycloud_delete_volume", args); err == nil {
+			t.Error("Expected token to be rejected for a different tool")
+		}
+	})
+
+	t.Run("token is bound to the session", func(t *testing.T) {
+		store := NewConfirmationStore(time.Minute)
+		token, _, _ := store.Issue("session-1", "bizflycloud_delete_server", args)
+		if err := store.Redeem("session-2", token, "bizflycloud_delete_server", args); err == nil {
+			t.Error("Expected token to be rejected from another session")
+		}
+		if err := store.Redeem("session-1", token, "bizflycloud_delete_server", args); err != nil {
+			t.Errorf("Expected token to still confirm the call in its session, got %v", err)
+		}
+	})
+
+	t.Run("token expires", func(t *testing.T) {
+		store := NewConfirmationStore(time.Minute)
+		now := time.Now()
+		store.now = func() time.Time { return now }
+		token, _, _ := store.Issue("session-1", "bizflycloud_delete_server", args)
+		now = now.Add(2 * time.Minute)
+		err := store.Redeem("session-1", token, "bizflycloud_delete_server", args)
+		if err == nil || !contains(err.Error(), "expired") {
+			t.Errorf("Expected expired token error, got %v", err)
+		}
+	})
+}
+
+func TestConfirmationMiddleware(t *testing.T) {
+	store := NewConfirmationStore(time.Minute)
+	called := 0
+	handler := store.Middleware()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called++
+		return mcp.NewToolResultText("deleted"), nil
+	})
+	ctx := context.Background()
+
+	t.Run("non-destructive tools run directly", func(t *testing.T) {
+		called = 0
+		result, _ := handler(ctx, createTestMCPRequest("bizflycloud_stop_server", map[string]interface{}{"server_id": "server-123"}))
+		verifyToolResult(t, result, "deleted")
+		if called != 1 {
+			t.Error("Expected handler to run")
+		}
+	})
+
+	t.Run("destructive tools need a confirmed preview", func(t *testing.T) {
+		called = 0
+		result, err := handler(ctx, createTestMCPRequest("bizflycloud_delete_server", map[string]interface{}{"server_id": "server-123"}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		verifyToolResult(t, result, "Confirmation required")
+		if called != 0 {
+			t.Fatal("Expected the deletion not to run before confirmation")
+		}
+
+		match := confirmationTokenPattern.FindStringSubmatch(getTextFromResult(result))
+		if match == nil {
+			t.Fatalf("Expected a confirmation token in the preview, got: %s", getTextFromResult(result))
+		}
+		result, _ = handler(ctx, createTestMCPRequest("bizflycloud_delete_server", map[string]interface{}{
+			"server_id":          "server-123",
+			confirmationTokenArg: match[1],
+		}))
+		verifyToolResult(t, result, "deleted")
+		if called != 1 {
+			t.Error("Expected the deletion to run after confirmation")
+		}
+	})
+
+	t.Run("token from another session is a tool error", func(t *testing.T) {
+		called = 0
+		s := server.NewMCPServer("BizflyCloud MCP Test", "1.0.0")
+		args := map[string]interface{}{"server_id": "server-123"}
+		previewCtx := s.WithContext(ctx, &httpSession{id: "session-1"})
+		result, _ := handler(previewCtx, createTestMCPRequest("bizflycloud_delete_server", args))
+		match := confirmationTokenPattern.FindStringSubmatch(getTextFromResult(result))
+		if match == nil {
+			t.Fatalf("Expected a confirmation token in the preview, got: %s", getTextFromResult(result))
+		}
+		otherCtx := s.WithContext(ctx, &httpSession{id: "session-2"})
+		result, _ = handler(otherCtx, createTestMCPRequest("bizflycloud_delete_server", map[string]interface{}{
+			"server_id":          "server-123",
+			confirmationTokenArg: match[1],
+		}))
+		verifyToolError(t, result, "Confirmation failed")
+		if called != 0 {
+			t.Error("Expected the deletion not to run from another session")
+		}
+	})
+
+	t.Run("invalid token is a tool error", func(t *testing.T) {
+		called = 0
+		result, _ := handler(ctx, createTestMCPRequest("bizflycloud_delete_server", map[string]interface{}{
+			"server_id":          "server-123",
+			confirmationTokenArg: "bogus",
+		}))
+		verifyToolError(t, result, "Confirmation failed")
+		if called != 0 {
+			t.Error("Expected the deletion not to run")
+		}
+	})
+}
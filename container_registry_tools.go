@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
+	"strconv"
 
 	"github.com/bizflycloud/gobizfly"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -16,27 +16,32 @@ func RegisterContainerRegistryTools(s *server.MCPServer, client *gobizfly.Client
 	// List repositories tool
 	listRepositoriesTool := mcp.NewTool("bizflycloud_list_container_registries",
 		mcp.WithDescription("List all Bizfly Cloud Container Registry repositories"),
+		withCommonOptions(),
+		withListOptions(),
 	)
 	s.AddTool(listRepositoriesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		repositories, err := client.ContainerRegistry.List(ctx, &gobizfly.ListOptions{})
+		services := servicesFromContext(ctx, client)
+		query, err := parseListQuery(request.Params.Arguments)
 		if err != nil {
-			// Check if error is 404 or service not available
-			errStr := strings.ToLower(err.Error())
-			if strings.Contains(errStr, "404") || 
-			   strings.Contains(errStr, "not found") || 
-			   strings.Contains(errStr, "resource not found") ||
-			   strings.Contains(errStr, "<svg") ||
-			   strings.Contains(errStr, "<html") {
-				return mcp.NewToolResultText("Available repositories:\n\n(No repositories found or Container Registry service is not enabled)"), nil
+			return errorResult(ctx, "Failed to list repositories", err), nil
+		}
+		repositories, err := services.Registry.List(ctx, &gobizfly.ListOptions{})
+		if err != nil {
+			// When the service isn't enabled for the account there is nothing to list
+			if isServiceUnavailable(ctx, err) {
+				return listResult(request, "Available repositories:\n\n(No repositories found or Container Registry service is not enabled)", "repositories", []RepositoryOutput{}), nil
 			}
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list repositories: %v", err)), nil
+			return errorResult(ctx, "Failed to list repositories", err), nil
 		}
+		repositories, page := pageItems(query, repositories, repositoryListFields)
 
 		result := "Available repositories:\n\n"
+		items := []RepositoryOutput{}
 		if len(repositories) == 0 {
 			result += "(No repositories found)\n"
 		} else {
 			for _, repo := range repositories {
+				items = append(items, newRepositoryOutput(repo))
 				result += fmt.Sprintf("Repository: %s\n", repo.Name)
 				result += fmt.Sprintf("  Public: %v\n", repo.Public)
 				result += fmt.Sprintf("  Pulls: %d\n", repo.Pulls)
@@ -45,12 +50,13 @@ func RegisterContainerRegistryTools(s *server.MCPServer, client *gobizfly.Client
 				result += "\n"
 			}
 		}
-		return mcp.NewToolResultText(result), nil
+		return pageResult(request, result, "repositories", items, page), nil
 	})
 
 	// Create repository tool
 	createRepositoryTool := mcp.NewTool("bizflycloud_create_container_registry",
 		mcp.WithDescription("Create a new Bizfly Cloud Container Registry repository"),
+		withCommonOptions(),
 		mcp.WithString("name",
 			mcp.Required(),
 			mcp.Description("Name of the repository"),
@@ -60,67 +66,83 @@ func RegisterContainerRegistryTools(s *server.MCPServer, client *gobizfly.Client
 		),
 	)
 	s.AddTool(createRepositoryTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		name, ok := request.Params.Arguments["name"].(string)
 		if !ok {
 			return nil, errors.New("name must be a string")
 		}
 		public, _ := request.Params.Arguments["public"].(bool)
 
-		err := client.ContainerRegistry.Create(ctx, &gobizfly.CreateRepositoryPayload{
+		err := services.Registry.Create(ctx, &gobizfly.CreateRepositoryPayload{
 			Name:   name,
 			Public: public,
 		})
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create repository: %v", err)), nil
+			return errorResult(ctx, "Failed to create repository", err), nil
 		}
 
 		result := fmt.Sprintf("Repository created successfully:\n")
 		result += fmt.Sprintf("  Name: %s\n", name)
 		result += fmt.Sprintf("  Public: %v\n", public)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, RepositoryOutput{Name: name, Public: public}), nil
 	})
 
 	// Delete repository tool
 	deleteRepositoryTool := mcp.NewTool("bizflycloud_delete_container_registry",
 		mcp.WithDescription("Delete a Bizfly Cloud Container Registry repository"),
+		withCommonOptions(),
+		withConfirmation(),
 		mcp.WithString("repository_name",
 			mcp.Required(),
 			mcp.Description("Name of the repository to delete"),
 		),
 	)
 	s.AddTool(deleteRepositoryTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		repositoryName, ok := request.Params.Arguments["repository_name"].(string)
 		if !ok {
 			return nil, errors.New("repository_name must be a string")
 		}
-		err := client.ContainerRegistry.Delete(ctx, repositoryName)
+		err := services.Registry.Delete(ctx, repositoryName)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete repository: %v", err)), nil
+			return errorResult(ctx, "Failed to delete repository", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Repository %s deleted successfully", repositoryName)), nil
+		return actionResult(request, fmt.Sprintf("Repository %s deleted successfully", repositoryName), ActionOutput{Action: "delete", ResourceType: "repository", ResourceID: repositoryName}), nil
 	})
 
 	// Get repository tags tool
 	getTagsTool := mcp.NewTool("bizflycloud_list_container_registry_tags",
 		mcp.WithDescription("List tags for a Bizfly Cloud Container Registry repository"),
+		withCommonOptions(),
+		withListOptions(),
 		mcp.WithString("repository_name",
 			mcp.Required(),
 			mcp.Description("Name of the repository"),
 		),
 	)
 	s.AddTool(getTagsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		repositoryName, ok := request.Params.Arguments["repository_name"].(string)
 		if !ok {
 			return nil, errors.New("repository_name must be a string")
 		}
-		tags, err := client.ContainerRegistry.GetTags(ctx, repositoryName)
+		query, err := parseListQuery(request.Params.Arguments)
+		if err != nil {
+			return errorResult(ctx, "Failed to get tags", err), nil
+		}
+		tags, err := services.Registry.GetTags(ctx, repositoryName)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get tags: %v", err)), nil
+			return errorResult(ctx, "Failed to get tags", err), nil
 		}
+		var page listPage
+		tags.Tags, page = pageItems(query, tags.Tags, repositoryTagListFields)
 
 		result := fmt.Sprintf("Repository: %s\n\n", tags.Repository.Name)
 		result += fmt.Sprintf("Tags:\n\n")
-		for _, tag := range tags.Tags {
+		items := []RepositoryTagOutput{}
+		for i := range tags.Tags {
+			tag := &tags.Tags[i]
+			items = append(items, newRepositoryTagOutput(tags.Repository.Name, tag))
 			result += fmt.Sprintf("Tag: %s\n", tag.Name)
 			result += fmt.Sprintf("  Author: %s\n", tag.Author)
 			result += fmt.Sprintf("  Created At: %s\n", tag.CreatedAt)
@@ -129,12 +151,13 @@ func RegisterContainerRegistryTools(s *server.MCPServer, client *gobizfly.Client
 			result += fmt.Sprintf("  Fixes: %d\n", tag.Fixes)
 			result += "\n"
 		}
-		return mcp.NewToolResultText(result), nil
+		return pageResult(request, result, "tags", items, page), nil
 	})
 
 	// Get tag details tool
 	getTagTool := mcp.NewTool("bizflycloud_get_container_registry_tag",
 		mcp.WithDescription("Get details of a Container Registry tag"),
+		withCommonOptions(),
 		mcp.WithString("repository_name",
 			mcp.Required(),
 			mcp.Description("Name of the repository"),
@@ -148,6 +171,7 @@ func RegisterContainerRegistryTools(s *server.MCPServer, client *gobizfly.Client
 		),
 	)
 	s.AddTool(getTagTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		repositoryName, ok := request.Params.Arguments["repository_name"].(string)
 		if !ok {
 			return nil, errors.New("repository_name must be a string")
@@ -161,9 +185,9 @@ func RegisterContainerRegistryTools(s *server.MCPServer, client *gobizfly.Client
 			vulns = "no"
 		}
 
-		image, err := client.ContainerRegistry.GetTag(ctx, repositoryName, tagName, vulns)
+		image, err := services.Registry.GetTag(ctx, repositoryName, tagName, vulns)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get tag: %v", err)), nil
+			return errorResult(ctx, "Failed to get tag", err), nil
 		}
 
 		result := fmt.Sprintf("Tag Details:\n\n")
@@ -175,18 +199,28 @@ func RegisterContainerRegistryTools(s *server.MCPServer, client *gobizfly.Client
 		result += fmt.Sprintf("Scan Status: %s\n", image.Tag.ScanStatus)
 		result += fmt.Sprintf("Vulnerabilities: %d\n", image.Tag.Vulnerabilities)
 		result += fmt.Sprintf("Fixes: %d\n", image.Tag.Fixes)
+		output := newRepositoryTagOutput(image.Repository.Name, &image.Tag)
 		if len(image.Vulnerabilities) > 0 {
 			result += fmt.Sprintf("\nVulnerabilities:\n")
 			for _, vuln := range image.Vulnerabilities {
 				result += fmt.Sprintf("  - %s (%s): %s\n", vuln.Name, vuln.Severity, vuln.Description)
+				output.Vulnerabilities = append(output.Vulnerabilities, VulnerabilityOutput{
+					Name:        vuln.Name,
+					Package:     vuln.Package,
+					Severity:    vuln.Severity,
+					Description: vuln.Description,
+					FixedBy:     vuln.FixedBy,
+				})
 			}
 		}
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, output), nil
 	})
 
 	// Delete tag tool
 	deleteTagTool := mcp.NewTool("bizflycloud_delete_container_registry_tag",
 		mcp.WithDescription("Delete a tag from a Bizfly Cloud Container Registry repository"),
+		withCommonOptions(),
+		withConfirmation(),
 		mcp.WithString("repository_name",
 			mcp.Required(),
 			mcp.Description("Name of the repository"),
@@ -197,6 +231,7 @@ func RegisterContainerRegistryTools(s *server.MCPServer, client *gobizfly.Client
 		),
 	)
 	s.AddTool(deleteTagTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		repositoryName, ok := request.Params.Arguments["repository_name"].(string)
 		if !ok {
 			return nil, errors.New("repository_name must be a string")
@@ -205,16 +240,21 @@ func RegisterContainerRegistryTools(s *server.MCPServer, client *gobizfly.Client
 		if !ok {
 			return nil, errors.New("tag_name must be a string")
 		}
-		err := client.ContainerRegistry.DeleteTag(ctx, tagName, repositoryName)
+		err := services.Registry.DeleteTag(ctx, repositoryName, tagName)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete tag: %v", err)), nil
+			return errorResult(ctx, "Failed to delete tag", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Tag %s deleted from repository %s successfully", tagName, repositoryName)), nil
+		return actionResult(request, fmt.Sprintf("Tag %s deleted from repository %s successfully", tagName, repositoryName), ActionOutput{
+			Action: "delete", ResourceType: "repository_tag", ResourceID: tagName,
+			Details: map[string]string{"repository": repositoryName},
+		}), nil
 	})
 
 	// Update repository tool
 	updateRepositoryTool := mcp.NewTool("bizflycloud_update_container_registry",
 		mcp.WithDescription("Update a Bizfly Cloud Container Registry repository"),
+		withCommonOptions(),
+		withDryRun(),
 		mcp.WithString("repository_name",
 			mcp.Required(),
 			mcp.Description("Name of the repository to update"),
@@ -225,6 +265,7 @@ func RegisterContainerRegistryTools(s *server.MCPServer, client *gobizfly.Client
 		),
 	)
 	s.AddTool(updateRepositoryTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		repositoryName, ok := request.Params.Arguments["repository_name"].(string)
 		if !ok {
 			return nil, errors.New("repository_name must be a string")
@@ -234,13 +275,87 @@ func RegisterContainerRegistryTools(s *server.MCPServer, client *gobizfly.Client
 			return nil, errors.New("public must be a boolean")
 		}
 
-		err := client.ContainerRegistry.EditRepo(ctx, repositoryName, &gobizfly.EditRepositoryPayload{
+		payload := &gobizfly.EditRepositoryPayload{
 			Public: public,
-		})
+		}
+		if isDryRun(request) {
+			target := fmt.Sprintf("repository %s", repositoryName)
+			return dryRunResult(request, "bizflycloud_update_container_registry", target, payload, validateRepositoryUpdate(repositoryName)), nil
+		}
+
+		err := services.Registry.EditRepo(ctx, repositoryName, payload)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to update repository: %v", err)), nil
+			return errorResult(ctx, "Failed to update repository", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Repository %s updated successfully", repositoryName)), nil
+		return actionResult(request, fmt.Sprintf("Repository %s updated successfully", repositoryName), ActionOutput{
+			Action: "update", ResourceType: "repository", ResourceID: repositoryName,
+			Details: map[string]string{"public": strconv.FormatBool(public)},
+		}), nil
 	})
 }
 
+// previewContainerRegistryDeletion describes the repository and the tags deleted with it
+func previewContainerRegistryDeletion(ctx context.Context, services *Services, args map[string]interface{}) (string, error) {
+	repositoryName, err := stringArg(args, "repository_name")
+	if err != nil {
+		return "", err
+	}
+	tags, err := services.Registry.GetTags(ctx, repositoryName)
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("Repository: %s\n", repositoryName)
+	result += fmt.Sprintf("  Public: %v\n", tags.Repository.Public)
+	result += fmt.Sprintf("  Pulls: %d\n", tags.Repository.Pulls)
+	result += fmt.Sprintf("  Last Push: %s\n", tags.Repository.LastPush)
+	result += fmt.Sprintf("  Tags: %d\n", len(tags.Tags))
+	for _, tag := range tags.Tags {
+		result += fmt.Sprintf("    - %s (updated %s)\n", tag.Name, tag.LastUpdated)
+	}
+	return result, nil
+}
+
+// previewContainerRegistryTagDeletion describes the tag
+func previewContainerRegistryTagDeletion(ctx context.Context, services *Services, args map[string]interface{}) (string, error) {
+	repositoryName, err := stringArg(args, "repository_name")
+	if err != nil {
+		return "", err
+	}
+	tagName, err := stringArg(args, "tag_name")
+	if err != nil {
+		return "", err
+	}
+	tags, err := services.Registry.GetTags(ctx, repositoryName)
+	if err != nil {
+		return "", err
+	}
+
+	for _, tag := range tags.Tags {
+		if tag.Name == tagName {
+			result := fmt.Sprintf("Tag: %s:%s\n", repositoryName, tag.Name)
+			result += fmt.Sprintf("  Author: %s\n", tag.Author)
+			result += fmt.Sprintf("  Created At: %s\n", tag.CreatedAt)
+			result += fmt.Sprintf("  Last Updated: %s\n", tag.LastUpdated)
+			return result, nil
+		}
+	}
+	return "", fmt.Errorf("tag %s not found in repository %s", tagName, repositoryName)
+}
+
+// validateRepositoryUpdate checks a repository update request before it is sent
+func validateRepositoryUpdate(repositoryName string) *requestValidation {
+	v := &requestValidation{}
+	v.check(repositoryName != "", "repository name is set")
+	return v
+}
+
+// repositoryListFields are the fields list_container_registries filters and sorts on
+func repositoryListFields(repo *gobizfly.Repository) listFields {
+	return listFields{Name: repo.Name, CreatedAt: repo.CreatedAt}
+}
+
+// repositoryTagListFields are the fields list_container_registry_tags filters and sorts on; a tag's status is its scan status
+func repositoryTagListFields(tag gobizfly.RepositoryTag) listFields {
+	return listFields{Name: tag.Name, Status: tag.ScanStatus, CreatedAt: tag.CreatedAt}
+}
@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"strings"
+	"time"
 
 	"github.com/bizflycloud/gobizfly"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -22,8 +22,15 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 	// List databases tool
 	listDatabasesTool := mcp.NewTool("bizflycloud_list_databases",
 		mcp.WithDescription("List all Bizfly Cloud databases"),
+		withCommonOptions(),
+		withListOptions(),
 	)
 	s.AddTool(listDatabasesTool, func(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+		services := servicesFromContext(ctx, client)
+		query, err := parseListQuery(request.Params.Arguments)
+		if err != nil {
+			return errorResult(ctx, "Failed to list databases", err), nil
+		}
 		// Panic recovery - return error result on panic
 		defer func() {
 			if r := recover(); r != nil {
@@ -36,48 +43,33 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 
 		log.Printf("[DEBUG] Database List tool called")
 		
-		// Check if client is nil
-		if client == nil {
-			log.Printf("[ERROR] Client is nil")
-			return mcp.NewToolResultText("Available databases:\n\n(Database service is not available - client is nil)"), nil
-		}
-
-		// Check if CloudDatabase service exists
-		if client.CloudDatabase == nil {
-			log.Printf("[ERROR] CloudDatabase is nil")
-			return mcp.NewToolResultText("Available databases:\n\n(Database service is not available)"), nil
-		}
-
-		// Check if Instances() is available
-		if client.CloudDatabase.Instances() == nil {
-			log.Printf("[ERROR] CloudDatabase.Instances() is nil")
-			return mcp.NewToolResultText("Available databases:\n\n(Database service is not available)"), nil
+		// Check if the database service exists
+		if services == nil || services.Databases == nil {
+			log.Printf("[ERROR] Database service is nil")
+			return listResult(request, "Available databases:\n\n(Database service is not available)", "databases", []DatabaseOutput{}), nil
 		}
 
 		// Get databases - call List() with empty struct to avoid nil pointer dereference in AddParamsListOption
-		databases, err := client.CloudDatabase.Instances().List(ctx, &gobizfly.CloudDatabaseListOption{})
+		databases, err := services.Databases.ListInstances(ctx, &gobizfly.CloudDatabaseListOption{})
 		
 		if err != nil {
 			log.Printf("[ERROR] Failed to list databases: %v", err)
-			// Check if error is 404 or service not available
-			errStr := strings.ToLower(err.Error())
-			if strings.Contains(errStr, "404") ||
-				strings.Contains(errStr, "not found") ||
-				strings.Contains(errStr, "resource not found") ||
-				strings.Contains(errStr, "<svg") ||
-				strings.Contains(errStr, "<html") {
-				return mcp.NewToolResultText("Available databases:\n\n(No databases found or Database service is not enabled)"), nil
+			// When the service isn't enabled for the account there is nothing to list
+			if isServiceUnavailable(ctx, err) {
+				return listResult(request, "Available databases:\n\n(No databases found or Database service is not enabled)", "databases", []DatabaseOutput{}), nil
 			}
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list databases: %v", err)), nil
+			return errorResult(ctx, "Failed to list databases", err), nil
 		}
 
 		// Check if databases is nil
 		if databases == nil {
 			log.Printf("[WARN] databases is nil after List() call")
-			return mcp.NewToolResultText("Available databases:\n\n(No databases found)"), nil
+			return listResult(request, "Available databases:\n\n(No databases found)", "databases", []DatabaseOutput{}), nil
 		}
+		databases, page := pageItems(query, databases, databaseListFields)
 
 		resultText := "Available databases:\n\n"
+		items := []DatabaseOutput{}
 		if len(databases) == 0 {
 			resultText += "(No databases found)\n"
 		} else {
@@ -88,6 +80,7 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 				}
 				
 				// Safely access db fields
+				items = append(items, newDatabaseOutput(db, nil))
 				resultText += fmt.Sprintf("Database: %s\n", safeString(db.Name))
 				resultText += fmt.Sprintf("  ID: %s\n", safeString(db.ID))
 				
@@ -103,21 +96,25 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 				resultText += "\n"
 			}
 		}
-		return mcp.NewToolResultText(resultText), nil
+		return pageResult(request, resultText, "databases", items, page), nil
 	})
 
 	// List datastores tool
 	listDatastoresTool := mcp.NewTool("bizflycloud_list_datastores",
 		mcp.WithDescription("List all available Bizfly Cloud database engines and versions"),
+		withCommonOptions(),
 	)
 	s.AddTool(listDatastoresTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		engines, err := client.CloudDatabase.Engines().List(ctx)
+		services := servicesFromContext(ctx, client)
+		engines, err := services.Databases.ListEngines(ctx)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list database engines: %v", err)), nil
+			return errorResult(ctx, "Failed to list database engines", err), nil
 		}
 
 		result := "Available database engines and versions:\n\n"
+		items := []DatastoreOutput{}
 		for _, engine := range engines {
+			items = append(items, newDatastoreOutput(engine))
 			result += fmt.Sprintf("Database Engine: %s\n", engine.Name)
 			result += fmt.Sprintf("  ID: %s\n", engine.ID)
 			if len(engine.Versions) > 0 {
@@ -128,12 +125,15 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 			}
 			result += "\n"
 		}
-		return mcp.NewToolResultText(result), nil
+		return listResult(request, result, "datastores", items), nil
 	})
 
 	// Create database tool
 	createDatabaseTool := mcp.NewTool("bizflycloud_create_database",
 		mcp.WithDescription("Create a new Bizfly Cloud database"),
+		withCommonOptions(),
+		withDryRun(),
+		withWait(),
 		mcp.WithString("name",
 			mcp.Required(),
 			mcp.Description("Name of the database"),
@@ -155,11 +155,11 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 			mcp.Description("Size of the volume in GB"),
 		),
 		mcp.WithString("availability_zone",
-			mcp.Required(),
-			mcp.Description("Availability zone for the database"),
+			mcp.Description("Availability zone for the database (optional, defaults to the profile's availability zone)"),
 		),
 	)
 	s.AddTool(createDatabaseTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		name, ok := request.Params.Arguments["name"].(string)
 		if !ok {
 			return nil, errors.New("name must be a string")
@@ -180,12 +180,15 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 		if !ok {
 			return nil, errors.New("volume_size must be a number")
 		}
-		availabilityZone, ok := request.Params.Arguments["availability_zone"].(string)
-		if !ok {
-			return nil, errors.New("availability_zone must be a string")
+		availabilityZone := availabilityZoneFromContext(ctx, "")
+		if zone, ok := request.Params.Arguments["availability_zone"].(string); ok && zone != "" {
+			availabilityZone = zone
+		}
+		if availabilityZone == "" {
+			return errorResult(ctx, "Failed to create database", invalidArgument(errors.New("availability_zone is required when the profile has no default availability zone"))), nil
 		}
 
-		database, err := client.CloudDatabase.Instances().Create(ctx, &gobizfly.CloudDatabaseInstanceCreate{
+		createReq := &gobizfly.CloudDatabaseInstanceCreate{
 			Name: name,
 			Datastore: gobizfly.CloudDatabaseDatastore{
 				Type: dbType,
@@ -195,11 +198,21 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 			VolumeSize:       int(volumeSize),
 			AvailabilityZone: availabilityZone,
 			Networks:         []gobizfly.CloudDatabaseNetworks{{}}, // Default network
-		})
+		}
+		timeout, err := waitTimeout(request)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create database: %v", err)), nil
+			return errorResult(ctx, "Failed to create database", err), nil
+		}
+		if isDryRun(request) {
+			return dryRunResult(request, "bizflycloud_create_database", "", createReq, validateDatabaseCreate(createReq)), nil
 		}
 
+		database, err := services.Databases.CreateInstance(ctx, createReq)
+		if err != nil {
+			return errorResult(ctx, "Failed to create database", err), nil
+		}
+		recordTask(ctx, services, TaskSpec{Action: "create", ResourceType: "database", ResourceID: database.ID, ResourceName: database.Name, Done: []string{"ACTIVE"}})
+
 		result := fmt.Sprintf("Database created successfully:\n")
 		result += fmt.Sprintf("  Name: %s\n", database.Name)
 		result += fmt.Sprintf("  ID: %s\n", database.ID)
@@ -207,45 +220,85 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += fmt.Sprintf("  DataStore ID: %s\n", database.Datastore.ID)
 		result += fmt.Sprintf("  Status: %s\n", database.Status)
 		result += fmt.Sprintf("  Created At: %s\n", database.CreatedAt)
-		return mcp.NewToolResultText(result), nil
+		output := DatabaseCreationOutput{DatabaseOutput: newDatabaseOutput(database, nil)}
+		if timeout == 0 {
+			return formatResult(request, result, output), nil
+		}
+
+		current, wait, err := waitForDatabase(ctx, services, request, database, timeout)
+		if err != nil {
+			return errorResult(ctx, "Database was created but waiting for it failed", err), nil
+		}
+		output.Wait = &wait
+		if current != nil {
+			output.DatabaseOutput = newDatabaseOutput(current, nil)
+			output.PrivateEndpoint = current.DNS.Private
+			output.PublicEndpoint = current.DNS.Public
+		}
+		if wait.Ready {
+			if output.PrivateEndpoint != "" {
+				result += fmt.Sprintf("  Private Endpoint: %s\n", output.PrivateEndpoint)
+			}
+			if output.PublicEndpoint != "" {
+				result += fmt.Sprintf("  Public Endpoint: %s\n", output.PublicEndpoint)
+			}
+			for _, node := range output.Nodes {
+				for _, address := range node.PrivateAddresses {
+					result += fmt.Sprintf("  Node %s (%s): %s:%d\n", node.Name, node.Role, address.IPAddress, address.Port)
+				}
+				for _, address := range node.PublicAddresses {
+					result += fmt.Sprintf("  Node %s (%s, public): %s:%d\n", node.Name, node.Role, address.IPAddress, address.Port)
+				}
+			}
+		}
+		result += describeWait("database", wait)
+		final := formatResult(request, result, output)
+		final.IsError = wait.Failed
+		return final, nil
 	})
 
 	// Delete database tool
 	deleteDatabaseTool := mcp.NewTool("bizflycloud_delete_database",
 		mcp.WithDescription("Delete a Bizfly Cloud database"),
+		withCommonOptions(),
+		withConfirmation(),
 		mcp.WithString("database_id",
 			mcp.Required(),
 			mcp.Description("ID of the database to delete"),
 		),
 	)
 	s.AddTool(deleteDatabaseTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		databaseID, ok := request.Params.Arguments["database_id"].(string)
 		if !ok {
 			return nil, errors.New("database_id must be a string")
 		}
-		_, err := client.CloudDatabase.Instances().Delete(ctx, databaseID, &gobizfly.CloudDatabaseDelete{})
+		_, err := services.Databases.DeleteInstance(ctx, databaseID, &gobizfly.CloudDatabaseDelete{})
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete database: %v", err)), nil
+			return errorResult(ctx, "Failed to delete database", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Database %s deleted successfully", databaseID)), nil
+		recordTask(ctx, services, TaskSpec{Action: "delete", ResourceType: "database", ResourceID: databaseID, Done: []string{statusDeleted}})
+		return actionResult(request, fmt.Sprintf("Database %s deleted successfully", databaseID), ActionOutput{Action: "delete", ResourceType: "database", ResourceID: databaseID}), nil
 	})
 
 	// Get database tool
 	getDatabaseTool := mcp.NewTool("bizflycloud_get_database",
 		mcp.WithDescription("Get details of a Bizfly Cloud database instance"),
+		withCommonOptions(),
 		mcp.WithString("database_id",
 			mcp.Required(),
 			mcp.Description("ID of the database to get details for"),
 		),
 	)
 	s.AddTool(getDatabaseTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		databaseID, ok := request.Params.Arguments["database_id"].(string)
 		if !ok {
 			return nil, errors.New("database_id must be a string")
 		}
-		db, err := client.CloudDatabase.Instances().Get(ctx, databaseID)
+		db, err := services.Databases.GetInstance(ctx, databaseID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get database: %v", err)), nil
+			return errorResult(ctx, "Failed to get database", err), nil
 		}
 
 		result := fmt.Sprintf("Database Details:\n\n")
@@ -262,7 +315,7 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += fmt.Sprintf("Nodes Count: %d\n", len(db.Nodes))
 		
 		// Get detailed nodes information using ListNodes
-		nodes, nodesErr := client.CloudDatabase.Instances().ListNodes(ctx, databaseID, &gobizfly.CloudDatabaseListOption{})
+		nodes, nodesErr := services.Databases.ListNodes(ctx, databaseID, &gobizfly.CloudDatabaseListOption{})
 		if nodesErr == nil && len(nodes) > 0 {
 			result += fmt.Sprintf("\nNodes Details:\n")
 			for i, node := range nodes {
@@ -312,33 +365,37 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 		}
 		
 		result += fmt.Sprintf("Created At: %s\n", db.CreatedAt)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newDatabaseOutput(db, nodes)), nil
 	})
 	
 	// List database nodes tool
 	listNodesTool := mcp.NewTool("bizflycloud_list_database_nodes",
 		mcp.WithDescription("List all nodes in a Bizfly Cloud database instance"),
+		withCommonOptions(),
 		mcp.WithString("database_id",
 			mcp.Required(),
 			mcp.Description("ID of the database instance"),
 		),
 	)
 	s.AddTool(listNodesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		databaseID, ok := request.Params.Arguments["database_id"].(string)
 		if !ok {
 			return nil, errors.New("database_id must be a string")
 		}
 		
-		nodes, err := client.CloudDatabase.Instances().ListNodes(ctx, databaseID, &gobizfly.CloudDatabaseListOption{})
+		nodes, err := services.Databases.ListNodes(ctx, databaseID, &gobizfly.CloudDatabaseListOption{})
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list database nodes: %v", err)), nil
+			return errorResult(ctx, "Failed to list database nodes", err), nil
 		}
 		
 		result := fmt.Sprintf("Database Nodes for Instance %s:\n\n", databaseID)
+		items := []DatabaseNodeOutput{}
 		if len(nodes) == 0 {
 			result += "(No nodes found)\n"
 		} else {
 			for i, node := range nodes {
+				items = append(items, newDatabaseNodeOutput(node))
 				result += fmt.Sprintf("Node %d:\n", i+1)
 				result += fmt.Sprintf("  ID: %s\n", node.ID)
 				result += fmt.Sprintf("  Name: %s\n", node.Name)
@@ -386,34 +443,44 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 				result += "\n"
 			}
 		}
-		return mcp.NewToolResultText(result), nil
+		return listResult(request, result, "nodes", items), nil
 	})
 
 	// List backups tool
 	listBackupsTool := mcp.NewTool("bizflycloud_list_database_backups",
 		mcp.WithDescription("List backups for a Bizfly Cloud database instance"),
+		withCommonOptions(),
+		withListOptions(),
 		mcp.WithString("database_id",
 			mcp.Required(),
 			mcp.Description("ID of the database instance"),
 		),
 	)
 	s.AddTool(listBackupsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		databaseID, ok := request.Params.Arguments["database_id"].(string)
 		if !ok {
 			return nil, errors.New("database_id must be a string")
 		}
+		query, err := parseListQuery(request.Params.Arguments)
+		if err != nil {
+			return errorResult(ctx, "Failed to list backups", err), nil
+		}
 
 		resource := &gobizfly.CloudDatabaseBackupResource{
 			ResourceID:   databaseID,
 			ResourceType: "instance",
 		}
-		backups, err := client.CloudDatabase.Backups().List(ctx, resource, nil)
+		backups, err := services.Databases.ListBackups(ctx, resource, nil)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list backups: %v", err)), nil
+			return errorResult(ctx, "Failed to list backups", err), nil
 		}
+		backups, page := pageItems(query, backups, databaseBackupListFields)
 
 		result := "Available backups:\n\n"
+		items := []DatabaseBackupOutput{}
 		for _, backup := range backups {
+			items = append(items, newDatabaseBackupOutput(backup))
 			result += fmt.Sprintf("Backup: %s\n", backup.Name)
 			result += fmt.Sprintf("  ID: %s\n", backup.ID)
 			result += fmt.Sprintf("  Status: %s\n", backup.Status)
@@ -422,12 +489,13 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 			result += fmt.Sprintf("  Created At: %s\n", backup.Created)
 			result += "\n"
 		}
-		return mcp.NewToolResultText(result), nil
+		return pageResult(request, result, "backups", items, page), nil
 	})
 
 	// Create backup tool
 	createBackupTool := mcp.NewTool("bizflycloud_create_database_backup",
 		mcp.WithDescription("Create a backup for a Bizfly Cloud database instance"),
+		withCommonOptions(),
 		mcp.WithString("database_id",
 			mcp.Required(),
 			mcp.Description("ID of the database instance"),
@@ -438,6 +506,7 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(createBackupTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		databaseID, ok := request.Params.Arguments["database_id"].(string)
 		if !ok {
 			return nil, errors.New("database_id must be a string")
@@ -447,11 +516,11 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 			return nil, errors.New("backup_name must be a string")
 		}
 
-		backup, err := client.CloudDatabase.Backups().Create(ctx, "instance", databaseID, &gobizfly.CloudDatabaseBackupCreate{
+		backup, err := services.Databases.CreateBackup(ctx, "instance", databaseID, &gobizfly.CloudDatabaseBackupCreate{
 			Name: backupName,
 		})
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create backup: %v", err)), nil
+			return errorResult(ctx, "Failed to create backup", err), nil
 		}
 
 		result := fmt.Sprintf("Backup created successfully:\n")
@@ -459,6 +528,76 @@ func RegisterDatabaseTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += fmt.Sprintf("  ID: %s\n", backup.ID)
 		result += fmt.Sprintf("  Status: %s\n", backup.Status)
 		result += fmt.Sprintf("  Type: %s\n", backup.Type)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newDatabaseBackupOutput(backup)), nil
 	})
-} 
\ No newline at end of file
+}
+
+// previewDatabaseDeletion describes the database instance and its nodes
+func previewDatabaseDeletion(ctx context.Context, services *Services, args map[string]interface{}) (string, error) {
+	databaseID, err := stringArg(args, "database_id")
+	if err != nil {
+		return "", err
+	}
+	db, err := services.Databases.GetInstance(ctx, databaseID)
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("Database: %s\n", db.Name)
+	result += fmt.Sprintf("  ID: %s\n", db.ID)
+	result += fmt.Sprintf("  Status: %s\n", db.Status)
+	result += fmt.Sprintf("  DataStore: %s %s\n", db.Datastore.Type, db.Datastore.VersionName)
+	for _, node := range db.Nodes {
+		result += fmt.Sprintf("  Node: %s (%s, %s)\n", node.Name, node.Role, node.Status)
+	}
+	result += fmt.Sprintf("  Created At: %s\n", db.CreatedAt)
+	return result, nil
+}
+
+// waitForDatabase polls the database instance until it is ACTIVE or has failed
+func waitForDatabase(ctx context.Context, services *Services, request mcp.CallToolRequest, database *gobizfly.CloudDatabaseInstance, timeout time.Duration) (*gobizfly.CloudDatabaseInstance, WaitOutput, error) {
+	var current *gobizfly.CloudDatabaseInstance
+	poll := statusPoll{
+		Kind:     "database",
+		Name:     database.Name,
+		Ready:    []string{"ACTIVE"},
+		Failed:   []string{"ERROR", "FAILED"},
+		Timeout:  timeout,
+		Interval: waitPollInterval,
+		Get: func(ctx context.Context) (string, error) {
+			instance, err := services.Databases.GetInstance(ctx, database.ID)
+			if err != nil {
+				return "", err
+			}
+			current = instance
+			return instance.Status, nil
+		},
+	}
+	wait, err := poll.Run(ctx, newProgressReporter(ctx, request))
+	return current, wait, err
+}
+
+// validateDatabaseCreate checks a database create request before it is sent
+func validateDatabaseCreate(req *gobizfly.CloudDatabaseInstanceCreate) *requestValidation {
+	v := &requestValidation{}
+	v.check(req.Name != "", "name is set")
+	v.check(req.Datastore.Type != "", "datastore type is set")
+	v.check(req.Datastore.ID != "", "datastore version is set")
+	v.check(req.FlavorName != "", "flavor is set")
+	v.check(req.VolumeSize > 0, "volume size is positive")
+	v.check(req.AvailabilityZone != "", "availability zone is set")
+	return v
+}
+
+// databaseListFields are the fields list_databases filters and sorts on
+func databaseListFields(db *gobizfly.CloudDatabaseInstance) listFields {
+	if db == nil {
+		return listFields{}
+	}
+	return listFields{Name: db.Name, Status: db.Status, CreatedAt: db.CreatedAt}
+}
+
+// databaseBackupListFields are the fields list_database_backups filters and sorts on
+func databaseBackupListFields(backup *gobizfly.CloudDatabaseBackup) listFields {
+	return listFields{Name: backup.Name, Status: backup.Status, CreatedAt: backup.Created}
+}
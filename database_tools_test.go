@@ -57,6 +57,16 @@ func TestCreateDatabaseTool(t *testing.T) {
 			t.Error("Invalid parameters")
 		}
 	})
+
+	t.Run("create database without an availability zone", func(t *testing.T) {
+		// The mock profile has no default availability zone
+		_, s := newMockServer(t, testCloudState())
+		result := callTool(t, s, "bizflycloud_create_database", map[string]interface{}{
+			"name": "db-1", "type": "mysql", "version": "8.0", "flavor": "db.s1.small", "volume_size": 50.0,
+		})
+		verifyToolError(t, result, "Failed to create database: availability_zone is required")
+		verifyToolError(t, result, "Error code: "+string(ErrorValidation))
+	})
 }
 
 func TestGetDatabaseTool(t *testing.T) {
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/bizflycloud/gobizfly"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -16,27 +15,36 @@ func RegisterDNSTools(s *server.MCPServer, client *gobizfly.Client) {
 	// List DNS zones tool
 	listZonesTool := mcp.NewTool("bizflycloud_list_dns_zones",
 		mcp.WithDescription("List all Bizfly Cloud DNS zones"),
+		withCommonOptions(),
+		withListOptions(),
 	)
 	s.AddTool(listZonesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		zones, err := client.DNS.ListZones(ctx, &gobizfly.ListOptions{})
+		services := servicesFromContext(ctx, client)
+		query, err := parseListQuery(request.Params.Arguments)
 		if err != nil {
-			// Check if error is 404 or service not available
-			errStr := strings.ToLower(err.Error())
-			if strings.Contains(errStr, "404") || 
-			   strings.Contains(errStr, "not found") || 
-			   strings.Contains(errStr, "resource not found") ||
-			   strings.Contains(errStr, "<svg") ||
-			   strings.Contains(errStr, "<html") {
-				return mcp.NewToolResultText("Available DNS zones:\n\n(No DNS zones found or DNS service is not enabled)"), nil
+			return errorResult(ctx, "Failed to list DNS zones", err), nil
+		}
+		zones, err := services.DNS.ListZones(ctx, &gobizfly.ListOptions{})
+		if err != nil {
+			// When the service isn't enabled for the account there is nothing to list
+			if isServiceUnavailable(ctx, err) {
+				return listResult(request, "Available DNS zones:\n\n(No DNS zones found or DNS service is not enabled)", "zones", []DNSZoneOutput{}), nil
 			}
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list DNS zones: %v", err)), nil
+			return errorResult(ctx, "Failed to list DNS zones", err), nil
+		}
+		var page listPage
+		if zones != nil {
+			zones.Zones, page = pageItems(query, zones.Zones, dnsZoneListFields)
 		}
 
 		result := "Available DNS zones:\n\n"
+		items := []DNSZoneOutput{}
 		if zones == nil || zones.Zones == nil || len(zones.Zones) == 0 {
 			result += "(No DNS zones found)\n"
 		} else {
-			for _, zone := range zones.Zones {
+			for i := range zones.Zones {
+				zone := &zones.Zones[i]
+				items = append(items, newDNSZoneOutput(zone))
 				result += fmt.Sprintf("Zone: %s\n", zone.Name)
 				result += fmt.Sprintf("  ID: %s\n", zone.ID)
 				result += fmt.Sprintf("  Active: %v\n", zone.Active)
@@ -48,12 +56,13 @@ func RegisterDNSTools(s *server.MCPServer, client *gobizfly.Client) {
 				result += "\n"
 			}
 		}
-		return mcp.NewToolResultText(result), nil
+		return pageResult(request, result, "zones", items, page), nil
 	})
 
 	// Create DNS zone tool
 	createZoneTool := mcp.NewTool("bizflycloud_create_dns_zone",
 		mcp.WithDescription("Create a new Bizfly Cloud DNS zone"),
+		withCommonOptions(),
 		mcp.WithString("name",
 			mcp.Required(),
 			mcp.Description("Name of the DNS zone (e.g., example.com)"),
@@ -63,18 +72,19 @@ func RegisterDNSTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(createZoneTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		name, ok := request.Params.Arguments["name"].(string)
 		if !ok {
 			return nil, errors.New("name must be a string")
 		}
 		description, _ := request.Params.Arguments["description"].(string)
 
-		zone, err := client.DNS.CreateZone(ctx, &gobizfly.CreateZonePayload{
+		zone, err := services.DNS.CreateZone(ctx, &gobizfly.CreateZonePayload{
 			Name:        name,
 			Description: description,
 		})
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create DNS zone: %v", err)), nil
+			return errorResult(ctx, "Failed to create DNS zone", err), nil
 		}
 
 		result := fmt.Sprintf("DNS zone created successfully:\n")
@@ -82,25 +92,27 @@ func RegisterDNSTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += fmt.Sprintf("  ID: %s\n", zone.ID)
 		result += fmt.Sprintf("  Active: %v\n", zone.Active)
 		result += fmt.Sprintf("  TTL: %d\n", zone.TTL)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newExtendedDNSZoneOutput(zone)), nil
 	})
 
 	// Get DNS zone tool
 	getZoneTool := mcp.NewTool("bizflycloud_get_dns_zone",
 		mcp.WithDescription("Get details of a Bizfly Cloud DNS zone"),
+		withCommonOptions(),
 		mcp.WithString("zone_id",
 			mcp.Required(),
 			mcp.Description("ID of the DNS zone"),
 		),
 	)
 	s.AddTool(getZoneTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		zoneID, ok := request.Params.Arguments["zone_id"].(string)
 		if !ok {
 			return nil, errors.New("zone_id must be a string")
 		}
-		zone, err := client.DNS.GetZone(ctx, zoneID)
+		zone, err := services.DNS.GetZone(ctx, zoneID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get DNS zone: %v", err)), nil
+			return errorResult(ctx, "Failed to get DNS zone", err), nil
 		}
 
 		result := fmt.Sprintf("DNS Zone Details:\n\n")
@@ -114,32 +126,36 @@ func RegisterDNSTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += fmt.Sprintf("Records Count: %d\n", len(zone.RecordsSet))
 		result += fmt.Sprintf("Created At: %s\n", zone.CreatedAt)
 		result += fmt.Sprintf("Updated At: %s\n", zone.UpdatedAt)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newExtendedDNSZoneOutput(zone)), nil
 	})
 
 	// Delete DNS zone tool
 	deleteZoneTool := mcp.NewTool("bizflycloud_delete_dns_zone",
 		mcp.WithDescription("Delete a Bizfly Cloud DNS zone"),
+		withCommonOptions(),
+		withConfirmation(),
 		mcp.WithString("zone_id",
 			mcp.Required(),
 			mcp.Description("ID of the DNS zone to delete"),
 		),
 	)
 	s.AddTool(deleteZoneTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		zoneID, ok := request.Params.Arguments["zone_id"].(string)
 		if !ok {
 			return nil, errors.New("zone_id must be a string")
 		}
-		err := client.DNS.DeleteZone(ctx, zoneID)
+		err := services.DNS.DeleteZone(ctx, zoneID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete DNS zone: %v", err)), nil
+			return errorResult(ctx, "Failed to delete DNS zone", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("DNS zone %s deleted successfully", zoneID)), nil
+		return actionResult(request, fmt.Sprintf("DNS zone %s deleted successfully", zoneID), ActionOutput{Action: "delete", ResourceType: "dns_zone", ResourceID: zoneID}), nil
 	})
 
 	// Create DNS record tool
 	createRecordTool := mcp.NewTool("bizflycloud_create_dns_record",
 		mcp.WithDescription("Create a DNS record in a Bizfly Cloud DNS zone"),
+		withCommonOptions(),
 		mcp.WithString("zone_id",
 			mcp.Required(),
 			mcp.Description("ID of the DNS zone"),
@@ -161,6 +177,7 @@ func RegisterDNSTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(createRecordTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		zoneID, ok := request.Params.Arguments["zone_id"].(string)
 		if !ok {
 			return nil, errors.New("zone_id must be a string")
@@ -193,9 +210,9 @@ func RegisterDNSTools(s *server.MCPServer, client *gobizfly.Client) {
 			Data: []string{dataStr},
 		}
 
-		record, err := client.DNS.CreateRecord(ctx, zoneID, payload)
+		record, err := services.DNS.CreateRecord(ctx, zoneID, payload)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create DNS record: %v", err)), nil
+			return errorResult(ctx, "Failed to create DNS record", err), nil
 		}
 
 		result := fmt.Sprintf("DNS record created successfully:\n")
@@ -203,25 +220,27 @@ func RegisterDNSTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += fmt.Sprintf("  ID: %s\n", record.ID)
 		result += fmt.Sprintf("  Type: %s\n", record.Type)
 		result += fmt.Sprintf("  TTL: %d\n", record.TTL)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newDNSRecordOutput(record)), nil
 	})
 
 	// Get DNS record tool
 	getRecordTool := mcp.NewTool("bizflycloud_get_dns_record",
 		mcp.WithDescription("Get details of a Bizfly Cloud DNS record"),
+		withCommonOptions(),
 		mcp.WithString("record_id",
 			mcp.Required(),
 			mcp.Description("ID of the DNS record"),
 		),
 	)
 	s.AddTool(getRecordTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		recordID, ok := request.Params.Arguments["record_id"].(string)
 		if !ok {
 			return nil, errors.New("record_id must be a string")
 		}
-		record, err := client.DNS.GetRecord(ctx, recordID)
+		record, err := services.DNS.GetRecord(ctx, recordID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get DNS record: %v", err)), nil
+			return errorResult(ctx, "Failed to get DNS record", err), nil
 		}
 
 		result := fmt.Sprintf("DNS Record Details:\n\n")
@@ -229,27 +248,79 @@ func RegisterDNSTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += fmt.Sprintf("ID: %s\n", record.ID)
 		result += fmt.Sprintf("Type: %s\n", record.Type)
 		result += fmt.Sprintf("TTL: %d\n", record.TTL)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newDNSRecordOutput(record)), nil
 	})
 
 	// Delete DNS record tool
 	deleteRecordTool := mcp.NewTool("bizflycloud_delete_dns_record",
 		mcp.WithDescription("Delete a Bizfly Cloud DNS record"),
+		withCommonOptions(),
+		withConfirmation(),
 		mcp.WithString("record_id",
 			mcp.Required(),
 			mcp.Description("ID of the DNS record to delete"),
 		),
 	)
 	s.AddTool(deleteRecordTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		recordID, ok := request.Params.Arguments["record_id"].(string)
 		if !ok {
 			return nil, errors.New("record_id must be a string")
 		}
-		err := client.DNS.DeleteRecord(ctx, recordID)
+		err := services.DNS.DeleteRecord(ctx, recordID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete DNS record: %v", err)), nil
+			return errorResult(ctx, "Failed to delete DNS record", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("DNS record %s deleted successfully", recordID)), nil
+		return actionResult(request, fmt.Sprintf("DNS record %s deleted successfully", recordID), ActionOutput{Action: "delete", ResourceType: "dns_record", ResourceID: recordID}), nil
 	})
 }
 
+// previewDNSZoneDeletion describes the zone and the records deleted with it
+func previewDNSZoneDeletion(ctx context.Context, services *Services, args map[string]interface{}) (string, error) {
+	zoneID, err := stringArg(args, "zone_id")
+	if err != nil {
+		return "", err
+	}
+	zone, err := services.DNS.GetZone(ctx, zoneID)
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("DNS Zone: %s\n", zone.Name)
+	result += fmt.Sprintf("  ID: %s\n", zone.ID)
+	result += fmt.Sprintf("  Active: %v\n", zone.Active)
+	result += fmt.Sprintf("  Records: %d\n", len(zone.RecordsSet))
+	for _, record := range zone.RecordsSet {
+		result += fmt.Sprintf("    - %s %s\n", record.Type, record.Name)
+	}
+	return result, nil
+}
+
+// previewDNSRecordDeletion describes the record
+func previewDNSRecordDeletion(ctx context.Context, services *Services, args map[string]interface{}) (string, error) {
+	recordID, err := stringArg(args, "record_id")
+	if err != nil {
+		return "", err
+	}
+	record, err := services.DNS.GetRecord(ctx, recordID)
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("DNS Record: %s\n", record.Name)
+	result += fmt.Sprintf("  ID: %s\n", record.ID)
+	result += fmt.Sprintf("  Type: %s\n", record.Type)
+	result += fmt.Sprintf("  Zone ID: %s\n", record.ZoneID)
+	result += fmt.Sprintf("  TTL: %d\n", record.TTL)
+	result += fmt.Sprintf("  Data: %v\n", record.Data)
+	return result, nil
+}
+
+// dnsZoneListFields are the fields list_dns_zones filters and sorts on; a zone's status is active or inactive
+func dnsZoneListFields(zone gobizfly.Zone) listFields {
+	status := "inactive"
+	if zone.Active {
+		status = "active"
+	}
+	return listFields{Name: zone.Name, Status: status, CreatedAt: zone.CreatedAt}
+}
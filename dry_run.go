@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// dryRunArg is the argument that makes a create or update tool validate its request without sending it
+const dryRunArg = "dry_run"
+
+// validationCheck is one rule a request payload was checked against
+type validationCheck struct {
+	description string
+	passed      bool
+}
+
+// requestValidation collects the checks run against a request payload
+type requestValidation struct {
+	checks []validationCheck
+}
+
+// check records whether the payload satisfies the described rule
+func (v *requestValidation) check(passed bool, description string) {
+	v.checks = append(v.checks, validationCheck{description: description, passed: passed})
+}
+
+// failures returns the rules the payload does not satisfy
+func (v *requestValidation) failures() []string {
+	var failed []string
+	for _, c := range v.checks {
+		if !c.passed {
+			failed = append(failed, c.description)
+		}
+	}
+	return failed
+}
+
+// withDryRun adds the dry_run argument to a create or update tool
+func withDryRun() mcp.ToolOption {
+	return mcp.WithBoolean(dryRunArg,
+		mcp.Description("Validate the request and return the resolved payload without sending it (default: false)"),
+	)
+}
+
+// isDryRun reports whether the tool was called with dry_run set
+func isDryRun(request mcp.CallToolRequest) bool {
+	dryRun, _ := request.Params.Arguments[dryRunArg].(bool)
+	return dryRun
+}
+
+// DryRunOutput is the JSON result of a create or update tool called with dry_run
+type DryRunOutput struct {
+	Tool        string                  `json:"tool"`
+	Target      string                  `json:"target,omitempty"`
+	RequestType string                  `json:"request_type"`
+	Request     json.RawMessage         `json:"request"`
+	Checks      []ValidationCheckOutput `json:"checks"`
+	Valid       bool                    `json:"valid"`
+}
+
+// ValidationCheckOutput is one rule a dry run checked the request against
+type ValidationCheckOutput struct {
+	Description string `json:"description"`
+	Passed      bool   `json:"passed"`
+}
+
+// dryRunResult describes the payload a tool would have sent and how it validated.
+// A payload that fails validation is returned as a tool error.
+func dryRunResult(request mcp.CallToolRequest, tool, target string, payload interface{}, validation *requestValidation) *mcp.CallToolResult {
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to encode %s request: %v", tool, err))
+	}
+	failed := validation.failures()
+
+	if outputFormat(request) == formatJSON {
+		output := DryRunOutput{
+			Tool:        tool,
+			Target:      target,
+			RequestType: payloadTypeName(payload),
+			Request:     data,
+			Checks:      []ValidationCheckOutput{},
+			Valid:       len(failed) == 0,
+		}
+		for _, c := range validation.checks {
+			output.Checks = append(output.Checks, ValidationCheckOutput{Description: c.description, Passed: c.passed})
+		}
+		result := jsonResult(output)
+		result.IsError = !output.Valid
+		return result
+	}
+
+	result := fmt.Sprintf("Dry run of %s: nothing was sent.\n\n", tool)
+	if target != "" {
+		result += fmt.Sprintf("Target: %s\n", target)
+	}
+	result += fmt.Sprintf("Request (%s):\n%s\n\n", payloadTypeName(payload), data)
+	result += "Validation:\n"
+	for _, c := range validation.checks {
+		status := "ok"
+		if !c.passed {
+			status = "failed"
+		}
+		result += fmt.Sprintf("  [%s] %s\n", status, c.description)
+	}
+
+	if len(failed) > 0 {
+		result += fmt.Sprintf("\nThe request is invalid: %d check(s) failed.\n", len(failed))
+		return mcp.NewToolResultError(result)
+	}
+	result += "\nThe request is valid. Call the tool again without dry_run to send it.\n"
+	return mcp.NewToolResultText(result)
+}
+
+// payloadTypeName names the gobizfly type of a request payload
+func payloadTypeName(payload interface{}) string {
+	t := reflect.TypeOf(payload)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return "nil"
+	}
+	return t.String()
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bizflycloud-mcp-server/internal/fakecloud"
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// callTool runs a tools/call request through the server, including its middleware
+func callTool(t *testing.T, s *server.MCPServer, name string, args map[string]interface{}) *mcp.CallToolResult {
+	t.Helper()
+	message, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]interface{}{"name": name, "arguments": args},
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode request: %v", err)
+	}
+	raw := s.HandleMessage(context.Background(), message)
+	response, ok := raw.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("Expected a result from %s, got %#v", name, raw)
+	}
+	result, ok := response.Result.(mcp.CallToolResult)
+	if !ok {
+		t.Fatalf("Expected a tool result from %s, got %T", name, response.Result)
+	}
+	return &result
+}
+
+func TestDryRunTools(t *testing.T) {
+	s := newFullTestServer(t)
+
+	// None of these calls reach the API: the gobizfly client has no credentials.
+	tests := []struct {
+		tool     string
+		args     map[string]interface{}
+		expected []string
+	}{
+		{
+			tool:     "bizflycloud_create_volume",
+			args:     map[string]interface{}{"name": "data", "size": 50.0, "volume_type": "PREMIUM-SSD1"},
+			expected: []string{"gobizfly.VolumeCreateRequest", `"size": 50`, "[ok] size is positive", "The request is valid"},
+		},
+		{
+			tool: "bizflycloud_create_database",
+			args: map[string]interface{}{
+				"name": "db", "type": "mysql", "version": "8.0", "flavor": "1c_2g",
+				"volume_size": 20.0, "availability_zone": "HN1",
+			},
+			expected: []string{"gobizfly.CloudDatabaseInstanceCreate", `"availability_zone": "HN1"`},
+		},
+		{
+			tool:     "bizflycloud_create_loadbalancer",
+			args:     map[string]interface{}{"name": "lb", "network_type": "external", "type": "medium"},
+			expected: []string{"gobizfly.LoadBalancerCreateRequest", "[ok] network type is external or internal"},
+		},
+		{
+			tool: "bizflycloud_update_kubernetes_pool",
+			args: map[string]interface{}{
+				"cluster_id": "cluster-1", "pool_id": "pool-1", "desired_size": 3.0,
+				"enable_autoscaling": true, "min_size": 1.0, "max_size": 5.0,
+			},
+			expected: []string{"Target: pool pool-1 in cluster cluster-1", `"desired_size": 3`},
+		},
+		{
+			tool:     "bizflycloud_update_loadbalancer",
+			args:     map[string]interface{}{"loadbalancer_id": "lb-1", "name": "renamed"},
+			expected: []string{"Target: load balancer lb-1", `"name": "renamed"`},
+		},
+		{
+			tool:     "bizflycloud_update_container_registry",
+			args:     map[string]interface{}{"repository_name": "app", "public": true},
+			expected: []string{"gobizfly.EditRepositoryPayload", `"public": true`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			tt.args[dryRunArg] = true
+			result := callTool(t, s, tt.tool, tt.args)
+			verifyToolResult(t, result, "nothing was sent")
+			for _, expected := range tt.expected {
+				verifyToolResult(t, result, expected)
+			}
+		})
+	}
+}
+
+func TestDryRunReportsValidationFailures(t *testing.T) {
+	s := newFullTestServer(t)
+
+	t.Run("desired capacity outside the group size", func(t *testing.T) {
+		result := callTool(t, s, "bizflycloud_create_autoscaling_group", map[string]interface{}{
+			"name": "group-1", "profile_id": "profile-123",
+			"min_size": 2.0, "max_size": 4.0, "desired_capacity": 6.0,
+			dryRunArg: true,
+		})
+		verifyToolError(t, result, "[failed] desired capacity is between min and max size")
+		verifyToolError(t, result, "1 check(s) failed")
+	})
+
+	t.Run("CDN update without a protocol changes nothing", func(t *testing.T) {
+		result := callTool(t, s, "bizflycloud_update_cdn_domain", map[string]interface{}{
+			"domain_id": "domain-1", "upstream_addrs": "10.0.0.1",
+			dryRunArg: true,
+		})
+		verifyToolError(t, result, "[failed] origin is changed")
+	})
+}
+
+func TestDryRunResolvesServerFlavor(t *testing.T) {
+	cloud, s := newMockServer(t, testCloudState())
+	args := map[string]interface{}{"name": "web-2", "flavor_name": "nix.2c_4g", "image_id": "image-1", dryRunArg: true}
+
+	result := callTool(t, s, "bizflycloud_create_server", args)
+	verifyToolResult(t, result, `[ok] flavor "nix.2c_4g" exists`)
+
+	args["flavor_name"] = "nix.64c_512g"
+	result = callTool(t, s, "bizflycloud_create_server", args)
+	verifyToolError(t, result, `[failed] flavor "nix.64c_512g" exists`)
+	verifyToolError(t, result, "1 check(s) failed")
+
+	for _, request := range cloud.Requests() {
+		if strings.HasPrefix(request, "POST ") && strings.Contains(request, "/servers") {
+			t.Errorf("Expected a dry run not to create a server, got %s", request)
+		}
+	}
+}
+
+func TestDryRunReportsUnresolvedImage(t *testing.T) {
+	cloud, s := newMockServer(t, testCloudState())
+	args := map[string]interface{}{"name": "web-2", "flavor_name": "nix.2c_4g", "os_type": "plan9", dryRunArg: true}
+
+	result := callTool(t, s, "bizflycloud_create_server", args)
+	verifyToolError(t, result, "[failed] image is resolved")
+	verifyToolError(t, result, "1 check(s) failed")
+
+	// A failed image lookup is reported the same way
+	cloud.InjectFailure(fakecloud.Failure{Method: http.MethodGet, Path: "/iaas-cloud/api/images", Status: http.StatusServiceUnavailable})
+	args["os_type"] = "ubuntu"
+	result = callTool(t, s, "bizflycloud_create_server", args)
+	verifyToolError(t, result, "[failed] image is resolved")
+	verifyToolError(t, result, "1 check(s) failed")
+}
+
+func TestValidateClusterCreate(t *testing.T) {
+	req := &gobizfly.ClusterCreateRequest{
+		Name:    "cluster",
+		Version: "1.28",
+		WorkerPools: []gobizfly.WorkerPool{
+			{Name: "default-pool", Flavor: "flavor-1", DesiredSize: 3, MinSize: 3, MaxSize: 3, AvailabilityZone: "HN1"},
+		},
+	}
+	if failed := validateClusterCreate(req).failures(); len(failed) != 0 {
+		t.Errorf("Expected a valid request, got failures %v", failed)
+	}
+
+	req.WorkerPools[0].DesiredSize = 0
+	if failed := validateClusterCreate(req).failures(); len(failed) != 2 {
+		t.Errorf("Expected worker count and size range failures, got %v", failed)
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ErrorCode classifies why a tool call failed
+type ErrorCode string
+
+// Error codes carried by tool errors
+const (
+	ErrorNotFound          ErrorCode = "not_found"
+	ErrorServiceNotEnabled ErrorCode = "service_not_enabled"
+	ErrorUnauthorized      ErrorCode = "unauthorized"
+	ErrorForbidden         ErrorCode = "forbidden"
+	ErrorQuotaExceeded     ErrorCode = "quota_exceeded"
+	ErrorValidation        ErrorCode = "validation"
+	ErrorTransient         ErrorCode = "transient"
+	ErrorCancelled         ErrorCode = "cancelled"
+	ErrorUnknown           ErrorCode = "unknown"
+)
+
+// errorCodeMeta is the _meta key of a tool error's code
+const errorCodeMeta = "error_code"
+
+// errorHints tell the caller what to do about each kind of error
+var errorHints = map[ErrorCode]string{
+	ErrorNotFound:          "Check the ID, or list the resources to find it.",
+	ErrorServiceNotEnabled: "The service may not be enabled for this account or region; enable it in the Bizfly Cloud console or try another region.",
+	ErrorUnauthorized:      "The credentials were rejected; check the profile's credentials.",
+	ErrorForbidden:         "The credentials aren't allowed to do this; check the account's permissions.",
+	ErrorQuotaExceeded:     "The account's quota is used up; free up resources or ask Bizfly Cloud for a higher quota.",
+	ErrorValidation:        "The request was rejected; check the arguments.",
+	ErrorTransient:         "This is usually temporary; try again shortly.",
+	ErrorCancelled:         "The call was cancelled before it finished; check whether the operation took place before calling it again.",
+}
+
+// quotaPattern matches the messages the APIs use when a quota is used up
+var quotaPattern = regexp.MustCompile(`(?i)quota|limit exceeded|exceeds? the limit|insufficient (balance|credit)`)
+
+// ToolError is a classified tool failure
+type ToolError struct {
+	Code   ErrorCode
+	Status int
+	Err    error
+}
+
+func (e *ToolError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// invalidArgument marks err as a problem with the arguments of a tool call
+func invalidArgument(err error) error {
+	return &ToolError{Code: ErrorValidation, Err: err}
+}
+
+type apiStatusContextKey struct{}
+
+// withAPIStatus returns a context that records the status of the last failed Bizfly API response
+func withAPIStatus(ctx context.Context) context.Context {
+	return context.WithValue(ctx, apiStatusContextKey{}, new(atomic.Int32))
+}
+
+// recordAPIStatus records the status of a Bizfly API response made with ctx
+func recordAPIStatus(ctx context.Context, status int) {
+	if recorded, ok := ctx.Value(apiStatusContextKey{}).(*atomic.Int32); ok && status >= http.StatusBadRequest {
+		recorded.Store(int32(status))
+	}
+}
+
+// apiStatusFromContext returns the status of the last failed Bizfly API response, or 0
+func apiStatusFromContext(ctx context.Context) int {
+	if recorded, ok := ctx.Value(apiStatusContextKey{}).(*atomic.Int32); ok {
+		return int(recorded.Load())
+	}
+	return 0
+}
+
+// classifyError works out why a call failed from the error gobizfly returned and
+// the status of the API response that caused it
+func classifyError(ctx context.Context, err error) *ToolError {
+	var classified *ToolError
+	if errors.As(err, &classified) {
+		return classified
+	}
+
+	classified = &ToolError{Code: ErrorUnknown, Err: err}
+	switch {
+	case errors.Is(err, context.Canceled):
+		classified.Code = ErrorCancelled
+		return classified
+	case errors.Is(err, context.DeadlineExceeded) || isTransientNetError(err):
+		classified.Code = ErrorTransient
+		return classified
+	case errors.Is(err, gobizfly.ErrNotFound):
+		classified.Status = http.StatusNotFound
+	case errors.Is(err, gobizfly.ErrPermissionDenied):
+		classified.Status = http.StatusForbidden
+	case errors.Is(err, gobizfly.ErrCommon):
+		classified.Status = apiStatusFromContext(ctx)
+	}
+
+	message := err.Error()
+	switch status := classified.Status; {
+	case status == http.StatusNotFound:
+		// A missing endpoint is answered with the portal's HTML page, a missing resource with JSON
+		classified.Code = ErrorNotFound
+		if isHTMLError(message) {
+			classified.Code = ErrorServiceNotEnabled
+		}
+	case quotaPattern.MatchString(message):
+		classified.Code = ErrorQuotaExceeded
+	case status == http.StatusUnauthorized:
+		classified.Code = ErrorUnauthorized
+	case status == http.StatusForbidden:
+		classified.Code = ErrorForbidden
+	case status == http.StatusTooManyRequests || status >= http.StatusInternalServerError:
+		classified.Code = ErrorTransient
+	case status >= http.StatusBadRequest:
+		classified.Code = ErrorValidation
+	}
+	return classified
+}
+
+// isTransientNetError reports whether err is a network failure that may not
+// happen again: a timeout, or a connection that was refused or reset. A bad
+// certificate, an unknown host or a malformed URL fails the same way every time.
+func isTransientNetError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// isServiceUnavailable reports whether err means the service or its endpoint
+// doesn't exist for the account, which the list tools report as an empty list
+func isServiceUnavailable(ctx context.Context, err error) bool {
+	code := classifyError(ctx, err).Code
+	return code == ErrorNotFound || code == ErrorServiceNotEnabled
+}
+
+// isHTMLError reports whether an error carries an HTML page instead of an API message
+func isHTMLError(message string) bool {
+	lower := strings.ToLower(message)
+	return strings.Contains(lower, "<html") || strings.Contains(lower, "<svg") || strings.Contains(lower, "<!doctype")
+}
+
+// errorResult returns the tool error for a failed action, e.g. "Failed to get server".
+// The message ends with the error code and a hint, and the code is also set as the
+// result's error_code metadata so clients can act on it.
+func errorResult(ctx context.Context, action string, err error) *mcp.CallToolResult {
+	classified := classifyError(ctx, err)
+	message := err.Error()
+	if isHTMLError(message) {
+		message = fmt.Sprintf("the API answered with an HTML error page (HTTP %d)", classified.Status)
+	}
+
+	text := fmt.Sprintf("%s: %s\n\nError code: %s", action, message, classified.Code)
+	if hint := errorHints[classified.Code]; hint != "" {
+		text += ". " + hint
+	}
+	result := mcp.NewToolResultError(text)
+	result.Meta = map[string]interface{}{errorCodeMeta: string(classified.Code)}
+	return result
+}
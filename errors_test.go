@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/bizflycloud-mcp-server/internal/fakecloud"
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// apiError builds the error gobizfly returns for a response body, with the response status recorded
+func apiError(status int, body string) (context.Context, error) {
+	ctx := withAPIStatus(context.Background())
+	recordAPIStatus(ctx, status)
+	switch status {
+	case http.StatusNotFound:
+		return ctx, fmt.Errorf("%s: %w", body, gobizfly.ErrNotFound)
+	case http.StatusForbidden:
+		return ctx, fmt.Errorf("%s: %w", body, gobizfly.ErrPermissionDenied)
+	}
+	return ctx, fmt.Errorf("%s: %w", body, gobizfly.ErrCommon)
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected ErrorCode
+	}{
+		{"missing resource", 404, `{"message": "Server not found"}`, ErrorNotFound},
+		{"missing endpoint", 404, `<html><body><svg></svg></body></html>`, ErrorServiceNotEnabled},
+		{"rejected credentials", 401, `{"message": "The request you have made requires authentication"}`, ErrorUnauthorized},
+		{"permission denied", 403, `{"message": "Policy doesn't allow this"}`, ErrorForbidden},
+		{"quota used up", 403, `{"message": "Quota exceeded for instances"}`, ErrorQuotaExceeded},
+		{"quota on create", 400, `{"message": "Volume size exceeds the limit"}`, ErrorQuotaExceeded},
+		{"bad request", 400, `{"message": "Invalid flavor"}`, ErrorValidation},
+		{"conflict", 409, `{"message": "Name already in use"}`, ErrorValidation},
+		{"rate limited", 429, `{"message": "Too many requests"}`, ErrorTransient},
+		{"server error", 503, `Service Unavailable`, ErrorTransient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := apiError(tt.status, tt.body)
+			if code := classifyError(ctx, err).Code; code != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, code)
+			}
+		})
+	}
+
+	t.Run("timeouts are transient", func(t *testing.T) {
+		err := fmt.Errorf("Get servers: %w", context.DeadlineExceeded)
+		if code := classifyError(context.Background(), err).Code; code != ErrorTransient {
+			t.Errorf("Expected %s, got %s", ErrorTransient, code)
+		}
+	})
+
+	t.Run("network errors", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			err      error
+			expected ErrorCode
+		}{
+			{"refused connection", &url.Error{Op: "Get", URL: "https://manage.bizflycloud.vn", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}, ErrorTransient},
+			{"reset connection", &url.Error{Op: "Get", URL: "https://manage.bizflycloud.vn", Err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}}, ErrorTransient},
+			{"timeout", &url.Error{Op: "Get", URL: "https://manage.bizflycloud.vn", Err: &net.DNSError{Err: "i/o timeout", IsTimeout: true}}, ErrorTransient},
+			{"unknown host", &url.Error{Op: "Get", URL: "https://manage.bizflycloud.vn", Err: &net.DNSError{Err: "no such host", Name: "manage.bizflycloud.vn", IsNotFound: true}}, ErrorUnknown},
+			{"bad certificate", &url.Error{Op: "Get", URL: "https://manage.bizflycloud.vn", Err: x509.UnknownAuthorityError{}}, ErrorUnknown},
+			{"cancelled call", &url.Error{Op: "Get", URL: "https://manage.bizflycloud.vn", Err: context.Canceled}, ErrorCancelled},
+		}
+		for _, tt := range tests {
+			if code := classifyError(context.Background(), tt.err).Code; code != tt.expected {
+				t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, code)
+			}
+		}
+	})
+
+	t.Run("invalid arguments are validation errors", func(t *testing.T) {
+		_, err := parseListQuery(map[string]interface{}{sortByArg: "size"})
+		if code := classifyError(context.Background(), err).Code; code != ErrorValidation {
+			t.Errorf("Expected %s, got %s", ErrorValidation, code)
+		}
+	})
+
+	t.Run("other errors are unknown", func(t *testing.T) {
+		if code := classifyError(context.Background(), errors.New("boom")).Code; code != ErrorUnknown {
+			t.Errorf("Expected %s, got %s", ErrorUnknown, code)
+		}
+	})
+}
+
+func TestErrorResult(t *testing.T) {
+	ctx, err := apiError(http.StatusNotFound, `<html><body><svg></svg></body></html>`)
+	result := errorResult(ctx, "Failed to list clusters", err)
+
+	verifyToolError(t, result, "Failed to list clusters: the API answered with an HTML error page (HTTP 404)")
+	verifyToolError(t, result, "Error code: service_not_enabled. The service may not be enabled")
+	if strings.Contains(getTextFromResult(result), "<svg") {
+		t.Error("Expected the HTML page to be left out of the message")
+	}
+	if result.Meta[errorCodeMeta] != string(ErrorServiceNotEnabled) {
+		t.Errorf("Expected the error code in the metadata, got %v", result.Meta)
+	}
+
+	t.Run("json mode carries the code", func(t *testing.T) {
+		handler := formatMiddleware()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return result, nil
+		})
+		wrapped, _ := handler(context.Background(), createTestMCPRequest("bizflycloud_list_kubernetes_clusters", map[string]interface{}{formatArg: formatJSON}))
+		var output ErrorOutput
+		if err := json.Unmarshal([]byte(getTextFromResult(wrapped)), &output); err != nil {
+			t.Fatalf("Expected a JSON error, got %q", getTextFromResult(wrapped))
+		}
+		if output.Code != string(ErrorServiceNotEnabled) {
+			t.Errorf("Expected code %s, got %q", ErrorServiceNotEnabled, output.Code)
+		}
+	})
+}
+
+// statusTransport answers every request with a fixed status
+type statusTransport int
+
+func (s statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: int(s), Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+}
+
+func TestTokenManagerRecordsAPIStatus(t *testing.T) {
+	m := NewTokenManager(&Credentials{})
+	m.next = statusTransport(http.StatusConflict)
+
+	ctx := withAPIStatus(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://manage.bizflycloud.vn/iaas-cloud/api/volumes", nil)
+	resp, err := m.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if status := apiStatusFromContext(ctx); status != http.StatusConflict {
+		t.Errorf("Expected the 409 to be recorded, got %d", status)
+	}
+}
+
+func TestErrorCodesThroughGobizfly(t *testing.T) {
+	cloud, s := newMockServer(t, testCloudState())
+
+	tests := []struct {
+		status int
+		code   ErrorCode
+	}{
+		{http.StatusServiceUnavailable, ErrorTransient},
+		{http.StatusTooManyRequests, ErrorTransient},
+		{http.StatusBadRequest, ErrorValidation},
+		{http.StatusUnauthorized, ErrorUnauthorized},
+	}
+	for _, tt := range tests {
+		cloud.ClearFailures()
+		cloud.InjectFailure(fakecloud.Failure{Method: http.MethodGet, Path: "/iaas-cloud/api/servers", Status: tt.status})
+		result := callTool(t, s, "bizflycloud_list_servers", nil)
+		if !result.IsError {
+			t.Errorf("%d: expected an error, got %s", tt.status, getTextFromResult(result))
+			continue
+		}
+		if code := result.Meta[errorCodeMeta]; code != string(tt.code) {
+			t.Errorf("%d: expected error code %s, got %v: %s", tt.status, tt.code, code, getTextFromResult(result))
+		}
+	}
+}
+
+func TestErrorCodesOfLookupsAndPreviews(t *testing.T) {
+	cloud := fakecloud.New(testCloudState())
+	pool := NewClientPool(NewMockConfig())
+	pool.SetTransport(cloud)
+	s := server.NewMCPServer("BizflyCloud MCP Test", "1.0.0",
+		server.WithToolHandlerMiddleware(pool.Middleware()),
+		server.WithToolHandlerMiddleware(NewConfirmationStore(time.Minute).Middleware()),
+	)
+	registerTools(s, newMockClient(t, pool), pool, nil)
+
+	tests := []struct {
+		name    string
+		failure *fakecloud.Failure
+		tool    string
+		args    map[string]interface{}
+		code    ErrorCode
+	}{
+		{"preview of a missing server", nil, "bizflycloud_delete_server", map[string]interface{}{"server_id": "missing"}, ErrorNotFound},
+		{"image lookup failure", &fakecloud.Failure{Method: http.MethodGet, Path: "/iaas-cloud/api/images", Status: http.StatusServiceUnavailable},
+			"bizflycloud_create_server", map[string]interface{}{"name": "web-2", "flavor_name": "nix.2c_4g"}, ErrorTransient},
+		{"unknown image", nil, "bizflycloud_create_server", map[string]interface{}{"name": "web-2", "flavor_name": "nix.2c_4g", "os_type": "plan9"}, ErrorValidation},
+	}
+	for _, tt := range tests {
+		cloud.ClearFailures()
+		if tt.failure != nil {
+			cloud.InjectFailure(*tt.failure)
+		}
+		result := callTool(t, s, tt.tool, tt.args)
+		if !result.IsError {
+			t.Errorf("%s: expected an error, got %s", tt.name, getTextFromResult(result))
+			continue
+		}
+		if code := result.Meta[errorCodeMeta]; code != string(tt.code) {
+			t.Errorf("%s: expected error code %s, got %v: %s", tt.name, tt.code, code, getTextFromResult(result))
+		}
+	}
+}
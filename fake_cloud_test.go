@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bizflycloud-mcp-server/internal/fakecloud"
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// testCloudState is a small account with one of most resource kinds
+func testCloudState() fakecloud.State {
+	volumeType := "PREMIUM-SSD1"
+	state := fakecloud.State{
+		Servers: []*gobizfly.Server{{
+			ID: "srv-1", Name: "web-1", Status: "ACTIVE", FlavorName: "nix.2c_4g",
+			AvailabilityZone: "HN1",
+		}},
+		Volumes: []*gobizfly.Volume{{
+			ID: "vol-1", Name: "data-1", Size: 20, VolumeType: volumeType, Status: "available",
+		}},
+		Flavors: []*fakecloud.Flavor{
+			{ID: "flavor-1", Name: "nix.2c_4g", VCPUs: 2, RAM: 4096, Category: "premium"},
+			{ID: "flavor-2", Name: "nix.4c_8g", VCPUs: 4, RAM: 8192, Category: "premium"},
+		},
+		Clusters: []*gobizfly.FullCluster{{}},
+		Repositories: []*gobizfly.TagRepository{{
+			Repository: gobizfly.Repository{Name: "app"},
+			Tags:       []gobizfly.RepositoryTag{{Name: "v1"}, {Name: "v2"}},
+		}},
+	}
+	state.Clusters[0].UID = "cluster-1"
+	state.Clusters[0].Name = "prod"
+	state.Clusters[0].ClusterStatus = "HEALTHY"
+	return state
+}
+
+// newFakeCloudServer starts a fake cloud and registers every tool against a
+// client pool that authenticates with it the way the server does for real
+func newFakeCloudServer(t *testing.T, state fakecloud.State) (*fakecloud.Cloud, *server.MCPServer) {
+	t.Helper()
+	cloud := fakecloud.New(state)
+	ts := httptest.NewServer(cloud)
+	t.Cleanup(ts.Close)
+
+	pool := NewClientPool(&Config{
+		DefaultProfile: "fake",
+		Profiles: map[string]*Profile{
+			"fake": {
+				Name:        "fake",
+				Region:      "HaNoi",
+				APIURL:      ts.URL,
+				Credentials: &Credentials{Method: authMethodPassword, Username: "user", Password: "secret"},
+			},
+		},
+	})
+	client, err := pool.Client(context.Background(), "", "")
+	if err != nil {
+		t.Fatalf("Failed to authenticate with the fake cloud: %v", err)
+	}
+	s := server.NewMCPServer("BizflyCloud MCP Test", "1.0.0", server.WithToolHandlerMiddleware(pool.Middleware()))
+	registerTools(s, client, pool, nil)
+	return cloud, s
+}
+
+func TestFakeCloudServesTools(t *testing.T) {
+	cloud, s := newFakeCloudServer(t, testCloudState())
+
+	tests := []struct {
+		tool     string
+		args     map[string]interface{}
+		expected string
+	}{
+		{"bizflycloud_list_servers", map[string]interface{}{}, "web-1"},
+		{"bizflycloud_get_server", map[string]interface{}{"server_id": "srv-1"}, "nix.2c_4g"},
+		{"bizflycloud_resize_server", map[string]interface{}{"server_id": "srv-1", "flavor_name": "nix.4c_8g"}, "srv-1"},
+		{"bizflycloud_attach_volume", map[string]interface{}{"volume_id": "vol-1", "server_id": "srv-1"}, "vol-1"},
+		{"bizflycloud_get_kubernetes_cluster", map[string]interface{}{"cluster_id": "cluster-1"}, "prod"},
+		{"bizflycloud_create_dns_zone", map[string]interface{}{"name": "example.com"}, "example.com"},
+		{"bizflycloud_delete_container_registry_tag", map[string]interface{}{"repository_name": "app", "tag_name": "v1"}, "v1"},
+	}
+	for _, tt := range tests {
+		result := callTool(t, s, tt.tool, tt.args)
+		if result.IsError {
+			t.Fatalf("%s failed: %s", tt.tool, getTextFromResult(result))
+		}
+		if text := getTextFromResult(result); !strings.Contains(text, tt.expected) {
+			t.Errorf("%s: expected %q in:\n%s", tt.tool, tt.expected, text)
+		}
+	}
+
+	state := cloud.State()
+	if got := state.Servers[0].FlavorName; got != "nix.4c_8g" {
+		t.Errorf("Expected the server to be resized to nix.4c_8g, got %s", got)
+	}
+	if got := state.Volumes[0].Status; got != "in-use" {
+		t.Errorf("Expected the attached volume to be in-use, got %s", got)
+	}
+	if len(state.Servers[0].AttachedVolumes) != 1 || state.Servers[0].AttachedVolumes[0].ID != "vol-1" {
+		t.Errorf("Expected vol-1 to be attached to the server, got %+v", state.Servers[0].AttachedVolumes)
+	}
+	if len(state.DNSZones) != 1 || state.DNSZones[0].Name != "example.com" {
+		t.Errorf("Expected the example.com zone to be created, got %+v", state.DNSZones)
+	}
+	if tags := state.Repositories[0].Tags; len(tags) != 1 || tags[0].Name != "v2" {
+		t.Errorf("Expected only tag v2 to remain, got %+v", tags)
+	}
+}
+
+func TestFakeCloudRejectsInvalidChanges(t *testing.T) {
+	cloud, s := newFakeCloudServer(t, testCloudState())
+
+	callTool(t, s, "bizflycloud_attach_volume", map[string]interface{}{"volume_id": "vol-1", "server_id": "srv-1"})
+	result := callTool(t, s, "bizflycloud_delete_volume", map[string]interface{}{"volume_id": "vol-1"})
+	if !result.IsError {
+		t.Fatalf("Expected deleting an attached volume to fail, got: %s", getTextFromResult(result))
+	}
+
+	callTool(t, s, "bizflycloud_detach_volume", map[string]interface{}{"volume_id": "vol-1", "server_id": "srv-1"})
+	result = callTool(t, s, "bizflycloud_delete_volume", map[string]interface{}{"volume_id": "vol-1"})
+	if result.IsError {
+		t.Fatalf("Expected deleting a detached volume to succeed: %s", getTextFromResult(result))
+	}
+	if volumes := cloud.State().Volumes; len(volumes) != 0 {
+		t.Errorf("Expected no volumes left, got %+v", volumes)
+	}
+}
+
+func TestFakeCloudNotFound(t *testing.T) {
+	_, s := newFakeCloudServer(t, testCloudState())
+
+	result := callTool(t, s, "bizflycloud_get_server", map[string]interface{}{"server_id": "missing"})
+	if !result.IsError {
+		t.Fatalf("Expected an error for a missing server")
+	}
+	if code := result.Meta[errorCodeMeta]; code != string(ErrorNotFound) {
+		t.Errorf("Expected error code %s, got %v", ErrorNotFound, code)
+	}
+}
+
+func TestFakeCloudInjectedFailure(t *testing.T) {
+	cloud, s := newFakeCloudServer(t, testCloudState())
+	cloud.InjectFailure(fakecloud.Failure{Method: http.MethodGet, Path: "/iaas-cloud/api/servers", Status: http.StatusServiceUnavailable, Times: 1})
+
+	result := callTool(t, s, "bizflycloud_list_servers", map[string]interface{}{})
+	if !result.IsError {
+		t.Fatalf("Expected the injected failure, got: %s", getTextFromResult(result))
+	}
+	if text := getTextFromResult(result); !strings.Contains(text, "Service Unavailable") {
+		t.Errorf("Expected the injected error message, got: %s", text)
+	}
+
+	result = callTool(t, s, "bizflycloud_list_servers", map[string]interface{}{})
+	if result.IsError {
+		t.Fatalf("Expected the failure to be used up: %s", getTextFromResult(result))
+	}
+}
@@ -3,8 +3,10 @@ module github.com/bizflycloud-mcp-server
 go 1.23.2
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/bizflycloud/gobizfly v1.1.19
 	github.com/mark3labs/mcp-go v0.21.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
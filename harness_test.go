@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var updateGolden = flag.Bool("update", false, "Rewrite the golden tool schemas in testdata/tool_schemas")
+
+// pipeTransport is an MCP client transport that talks JSON-RPC to a server
+// over in-memory pipes, the way a client talks to the stdio transport
+type pipeTransport struct {
+	writer io.WriteCloser
+	reader *bufio.Reader
+
+	mu        sync.Mutex
+	writeMu   sync.Mutex
+	responses map[int64]chan *transport.JSONRPCResponse
+	notify    func(mcp.JSONRPCNotification)
+	done      chan struct{}
+}
+
+func (p *pipeTransport) Start(ctx context.Context) error {
+	go p.readLoop()
+	return nil
+}
+
+// readLoop routes responses to the requests waiting for them and hands
+// notifications to the notification handler
+func (p *pipeTransport) readLoop() {
+	defer close(p.done)
+	for {
+		line, err := p.reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		var message struct {
+			ID     *int64 `json:"id"`
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal(line, &message); err != nil {
+			continue
+		}
+		if message.ID == nil {
+			var notification mcp.JSONRPCNotification
+			if err := json.Unmarshal(line, &notification); err != nil {
+				continue
+			}
+			p.mu.Lock()
+			notify := p.notify
+			p.mu.Unlock()
+			if notify != nil {
+				notify(notification)
+			}
+			continue
+		}
+		var response transport.JSONRPCResponse
+		if err := json.Unmarshal(line, &response); err != nil {
+			continue
+		}
+		p.mu.Lock()
+		ch, ok := p.responses[*message.ID]
+		delete(p.responses, *message.ID)
+		p.mu.Unlock()
+		if ok {
+			ch <- &response
+		}
+	}
+}
+
+func (p *pipeTransport) write(message interface{}) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	_, err = p.writer.Write(append(data, '\n'))
+	return err
+}
+
+func (p *pipeTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	ch := make(chan *transport.JSONRPCResponse, 1)
+	p.mu.Lock()
+	p.responses[request.ID] = ch
+	p.mu.Unlock()
+	if err := p.write(request); err != nil {
+		return nil, err
+	}
+	select {
+	case response := <-ch:
+		return response, nil
+	case <-p.done:
+		return nil, fmt.Errorf("server closed the connection")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *pipeTransport) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	return p.write(notification)
+}
+
+func (p *pipeTransport) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.notify = handler
+}
+
+func (p *pipeTransport) Close() error {
+	return p.writer.Close()
+}
+
+// mcpHarness is an initialized MCP client connected to a server over pipes
+type mcpHarness struct {
+	*client.Client
+
+	mu            sync.Mutex
+	notifications []mcp.JSONRPCNotification
+}
+
+// startHarness serves s over in-memory pipes with the stdio transport and
+// returns an initialized client for it. The server stops with the test.
+func startHarness(t *testing.T, s *server.MCPServer) *mcpHarness {
+	t.Helper()
+	return startSubscriptionHarness(t, s, nil)
+}
+
+// startSubscriptionHarness is startHarness with resource subscriptions
+func startSubscriptionHarness(t *testing.T, s *server.MCPServer, subscriptions *ResourceSubscriptions) *mcpHarness {
+	t.Helper()
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		_ = serveStdio(ctx, s, subscriptions, serverReader, serverWriter)
+		serverWriter.Close()
+	}()
+
+	pipe := &pipeTransport{
+		writer:    clientWriter,
+		reader:    bufio.NewReader(clientReader),
+		responses: make(map[int64]chan *transport.JSONRPCResponse),
+		done:      make(chan struct{}),
+	}
+	h := &mcpHarness{Client: client.NewClient(pipe)}
+	t.Cleanup(func() {
+		h.Close()
+		cancel()
+		<-stopped
+	})
+	if err := h.Start(ctx); err != nil {
+		t.Fatalf("Failed to start the client: %v", err)
+	}
+	h.OnNotification(func(notification mcp.JSONRPCNotification) {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.notifications = append(h.notifications, notification)
+	})
+
+	initialize := mcp.InitializeRequest{}
+	initialize.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initialize.Params.ClientInfo = mcp.Implementation{Name: "harness", Version: "1.0.0"}
+	if _, err := h.Initialize(h.context(t), initialize); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	return h
+}
+
+// context bounds a request so a hung server fails the test instead of blocking it
+func (h *mcpHarness) context(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// call calls a tool through tools/call and fails the test on a protocol error
+func (h *mcpHarness) call(t *testing.T, name string, args map[string]interface{}) *mcp.CallToolResult {
+	t.Helper()
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+	result, err := h.CallTool(h.context(t), request)
+	if err != nil {
+		t.Fatalf("tools/call %s failed: %v", name, err)
+	}
+	return result
+}
+
+// tools lists the tools through tools/list, sorted by name
+func (h *mcpHarness) tools(t *testing.T) []mcp.Tool {
+	t.Helper()
+	result, err := h.ListTools(h.context(t), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("tools/list failed: %v", err)
+	}
+	sort.Slice(result.Tools, func(i, j int) bool { return result.Tools[i].Name < result.Tools[j].Name })
+	return result.Tools
+}
+
+// receivedNotifications returns the notifications the server sent so far
+func (h *mcpHarness) receivedNotifications() []mcp.JSONRPCNotification {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]mcp.JSONRPCNotification{}, h.notifications...)
+}
+
+func TestHarnessListTools(t *testing.T) {
+	s := newFullTestServer(t)
+	h := startHarness(t, s)
+
+	tools := h.tools(t)
+	if got, want := len(tools), len(registeredToolNames(s)); got != want {
+		t.Fatalf("Expected tools/list to return %d tools, got %d", want, got)
+	}
+	for _, tool := range tools {
+		if tool.Description == "" {
+			t.Errorf("Tool %s has no description", tool.Name)
+		}
+		if tool.InputSchema.Type != "object" {
+			t.Errorf("Tool %s: expected an object input schema, got %q", tool.Name, tool.InputSchema.Type)
+		}
+		for _, required := range tool.InputSchema.Required {
+			if _, ok := tool.InputSchema.Properties[required]; !ok {
+				t.Errorf("Tool %s requires %s, which is not a property", tool.Name, required)
+			}
+		}
+	}
+}
+
+func TestHarnessCallTool(t *testing.T) {
+	cloud, s := newMockServer(t, testCloudState())
+	h := startHarness(t, s)
+
+	result := h.call(t, "bizflycloud_list_servers", map[string]interface{}{"format": "json"})
+	if result.IsError {
+		t.Fatalf("list_servers failed: %s", getTextFromResult(result))
+	}
+	var listed struct {
+		Servers []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"servers"`
+	}
+	if err := json.Unmarshal([]byte(getTextFromResult(result)), &listed); err != nil {
+		t.Fatalf("Expected JSON output, got %s: %v", getTextFromResult(result), err)
+	}
+	if len(listed.Servers) != 1 || listed.Servers[0].ID != "srv-1" || listed.Servers[0].Name != "web-1" {
+		t.Errorf("Expected server srv-1 web-1, got %+v", listed.Servers)
+	}
+
+	result = h.call(t, "bizflycloud_stop_server", map[string]interface{}{"server_id": "srv-1"})
+	if result.IsError {
+		t.Fatalf("stop_server failed: %s", getTextFromResult(result))
+	}
+	if status := cloud.State().Servers[0].Status; status != "SHUTOFF" {
+		t.Errorf("Expected the server to be SHUTOFF, got %s", status)
+	}
+
+	result = h.call(t, "bizflycloud_get_server", map[string]interface{}{"server_id": "missing"})
+	if !result.IsError {
+		t.Fatalf("Expected an error for a missing server, got: %s", getTextFromResult(result))
+	}
+	if code := result.Meta[errorCodeMeta]; code != string(ErrorNotFound) {
+		t.Errorf("Expected error code %s to survive the round trip, got %v", ErrorNotFound, code)
+	}
+
+	// Unknown tools are protocol errors rather than tool errors
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "bizflycloud_no_such_tool"
+	if _, err := h.CallTool(h.context(t), request); err == nil {
+		t.Error("Expected an error calling an unknown tool")
+	}
+}
+
+// TestToolSchemas compares what tools/list returns for every tool with the
+// golden files in testdata/tool_schemas. Run with -update after changing a
+// tool's arguments or description and review the diff.
+func TestToolSchemas(t *testing.T) {
+	h := startHarness(t, newFullTestServer(t))
+	dir := filepath.Join("testdata", "tool_schemas")
+
+	if *updateGolden {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, tool := range h.tools(t) {
+		got, err := json.MarshalIndent(tool, "", "  ")
+		if err != nil {
+			t.Fatalf("Failed to encode %s: %v", tool.Name, err)
+		}
+		got = append(got, '\n')
+		file := filepath.Join(dir, tool.Name+".json")
+		seen[tool.Name+".json"] = true
+
+		if *updateGolden {
+			if err := os.WriteFile(file, got, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(file)
+		if err != nil {
+			t.Errorf("No golden schema for %s; run go test -run TestToolSchemas -update", tool.Name)
+			continue
+		}
+		if string(got) != string(want) {
+			t.Errorf("Schema of %s changed; run go test -run TestToolSchemas -update if intended.\ngot:\n%s\nwant:\n%s", tool.Name, got, want)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if !seen[entry.Name()] {
+			t.Errorf("Golden schema %s has no registered tool; remove it", entry.Name())
+		}
+	}
+}
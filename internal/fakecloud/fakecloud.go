@@ -0,0 +1,1931 @@
+// Package fakecloud is an in-memory Bizfly Cloud for the server's mock mode
+// and its tests. Only the mock mode wiring of the server imports it.
+package fakecloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bizflycloud/gobizfly"
+	"github.com/bizflycloud/gobizfly/constants"
+)
+
+// Path prefixes of the services the fake cloud serves, keyed by the
+// canonical name gobizfly looks them up by in the service catalog
+var fakeCloudServices = map[string]string{
+	"cloud_server":           "/iaas-cloud/api",
+	"kubernetes_engine":      "/api/kubernetes-engine",
+	"cloud_database":         "/api/cloud-database",
+	"load_balancer":          "/api/loadbalancers",
+	"dns":                    "/api/dns",
+	"cdn":                    "/api/cdn",
+	"key_management_service": "/api/ssl",
+	"container_registry":     "/api/container-registry",
+	"auto_scaling":           "/api/auto-scaling",
+	"alert":                  "/api/alert",
+}
+
+// fakeCloudToken is the only token the fake cloud issues and accepts
+const fakeCloudToken = "fake-cloud-token"
+
+// authTokenHeader carries the Keystone token of an API request
+const authTokenHeader = "X-Auth-Token"
+
+// Flavor is a server flavor in the wire format of the flavors API
+type Flavor struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	VCPUs    int    `json:"vcpus"`
+	RAM      int    `json:"ram"`
+	Disk     int    `json:"disk"`
+	Category string `json:"category"`
+}
+
+// OSImage is an OS distribution and its image versions in the wire format of the images API
+type OSImage struct {
+	OSDistribution string           `json:"os"`
+	Version        []OSImageVersion `json:"versions"`
+}
+
+// OSImageVersion is one image of an OS distribution
+type OSImageVersion struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// State is the content of a fake cloud. Every resource is in the
+// Bizfly API's wire format, so a state can be loaded from a JSON fixture.
+type State struct {
+	Servers           []*gobizfly.Server                         `json:"servers,omitempty"`
+	Volumes           []*gobizfly.Volume                         `json:"volumes,omitempty"`
+	Snapshots         []*gobizfly.Snapshot                       `json:"snapshots,omitempty"`
+	Flavors           []*Flavor                                  `json:"flavors,omitempty"`
+	OSImages          []OSImage                                  `json:"os_images,omitempty"`
+	CustomImages      []*gobizfly.CustomImage                    `json:"custom_images,omitempty"`
+	Clusters          []*gobizfly.FullCluster                    `json:"clusters,omitempty"`
+	Databases         []*gobizfly.CloudDatabaseInstance          `json:"databases,omitempty"`
+	DatabaseBackups   map[string][]*gobizfly.CloudDatabaseBackup `json:"database_backups,omitempty"`
+	DatabaseEngines   []*gobizfly.CloudDatabaseEngine            `json:"database_engines,omitempty"`
+	LoadBalancers     []*gobizfly.LoadBalancer                   `json:"load_balancers,omitempty"`
+	DNSZones          []*gobizfly.ExtendedZone                   `json:"dns_zones,omitempty"`
+	CDNDomains        []*gobizfly.Domain                         `json:"cdn_domains,omitempty"`
+	Certificates      []*gobizfly.KMSCertificateGetResponse      `json:"certificates,omitempty"`
+	Repositories      []*gobizfly.TagRepository                  `json:"repositories,omitempty"`
+	AutoScalingGroups []*gobizfly.AutoScalingGroup               `json:"autoscaling_groups,omitempty"`
+	Alarms            []*gobizfly.Alarms                         `json:"alarms,omitempty"`
+	Receivers         []*gobizfly.Receivers                      `json:"receivers,omitempty"`
+}
+
+// Failure makes the fake cloud answer matching requests with an error
+type Failure struct {
+	// Method is the request method to match; empty matches any method
+	Method string
+	// Path is a path.Match pattern for the request path, such as
+	// "/iaas-cloud/api/servers/*"; empty matches any path
+	Path string
+	// Status is the HTTP status of the error response
+	Status int
+	// Message is the error body; it defaults to the status text
+	Message string
+	// Times is how many matching requests fail; 0 fails all of them
+	Times int
+}
+
+// Cloud is an in-memory stand-in for the Bizfly Cloud REST API. It
+// answers the token and service catalog endpoints, so a gobizfly client built
+// with WithAPIURL pointing at it authenticates normally, and serves the
+// endpoints the tools call from a State that mutating calls change.
+// Serve it with httptest.NewServer.
+type Cloud struct {
+	mu       sync.Mutex
+	state    State
+	mux      *http.ServeMux
+	failures []*Failure
+	// requests are only kept once a test turns recording on, so a mock
+	// mode server that runs for days doesn't grow with every call
+	recording bool
+	requests  []string
+	tasks     map[string]fakeTask
+	nextID    int
+	now       func() time.Time
+
+	provisionReads int
+	failNames      map[string]bool
+	provisioning   map[string]*fakeProvisioning
+}
+
+// fakeProvisioning is a created resource that isn't ready yet
+type fakeProvisioning struct {
+	remaining int
+	finish    func()
+}
+
+// fakeTask is an operation started through the fake cloud
+type fakeTask struct {
+	action   string
+	serverID string
+}
+
+// New returns a fake cloud seeded with the given state
+func New(state State) *Cloud {
+	c := &Cloud{
+		state:        state,
+		mux:          http.NewServeMux(),
+		tasks:        make(map[string]fakeTask),
+		now:          time.Now,
+		failNames:    make(map[string]bool),
+		provisioning: make(map[string]*fakeProvisioning),
+	}
+	if c.state.DatabaseBackups == nil {
+		c.state.DatabaseBackups = make(map[string][]*gobizfly.CloudDatabaseBackup)
+	}
+	c.routes()
+	return c
+}
+
+// SetProvisioningReads makes servers, Kubernetes clusters and databases created
+// from now on report a transitional status (BUILD or PROVISIONING) until they
+// have been read n times with their get call. Rebooted servers (REBOOT or
+// HARD_REBOOT) and updated load balancers (PENDING_UPDATE) do the same. With 0,
+// the default, they are ready as soon as they are created or changed.
+func (c *Cloud) SetProvisioningReads(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.provisionReads = n
+}
+
+// FailProvisioning makes servers, clusters and databases created from now on
+// with the given name end up in an error status instead of becoming ready
+func (c *Cloud) FailProvisioning(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failNames[name] = true
+}
+
+// InjectFailure makes requests matching the failure answer with its status
+func (c *Cloud) InjectFailure(failure Failure) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures = append(c.failures, &failure)
+}
+
+// ClearFailures removes all injected failures
+func (c *Cloud) ClearFailures() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures = nil
+}
+
+// RecordRequests makes the cloud keep the requests it serves from now on for Requests
+func (c *Cloud) RecordRequests() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recording = true
+}
+
+// Requests returns the requests served since RecordRequests as "METHOD path"
+func (c *Cloud) Requests() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string{}, c.requests...)
+}
+
+// State returns a copy of the current state
+func (c *Cloud) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var state State
+	data, err := json.Marshal(c.state)
+	if err == nil {
+		err = json.Unmarshal(data, &state)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("fake cloud state does not round-trip through JSON: %v", err))
+	}
+	return state
+}
+
+// ServeHTTP implements http.Handler. Requests are served one at a time.
+func (c *Cloud) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.recording {
+		c.requests = append(c.requests, r.Method+" "+r.URL.Path)
+	}
+
+	if failure := c.matchFailure(r); failure != nil {
+		message := failure.Message
+		if message == "" {
+			message = http.StatusText(failure.Status)
+		}
+		fakeError(w, failure.Status, message)
+		return
+	}
+	if !isAuthRequest(r) && r.Header.Get(authTokenHeader) != fakeCloudToken {
+		fakeError(w, http.StatusUnauthorized, "The request you have made requires authentication.")
+		return
+	}
+	c.mux.ServeHTTP(w, r)
+}
+
+// RoundTrip implements http.RoundTripper by serving the request in process,
+// so a client can talk to the fake cloud without a listener
+func (c *Cloud) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil {
+		req.Body = http.NoBody
+	}
+	recorder := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+	c.ServeHTTP(recorder, req)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorder.status, http.StatusText(recorder.status)),
+		StatusCode:    recorder.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorder.header,
+		Body:          io.NopCloser(&recorder.body),
+		ContentLength: int64(recorder.body.Len()),
+		Request:       req,
+	}, nil
+}
+
+// responseRecorder keeps the response to a request served in process
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header { return r.header }
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(b)
+}
+
+// isAuthRequest reports whether the request targets the token or service catalog endpoints
+func isAuthRequest(r *http.Request) bool {
+	p := strings.TrimSuffix(r.URL.Path, "/")
+	return strings.HasSuffix(p, "/api/token") || strings.HasSuffix(p, "/api/auth/service")
+}
+
+// regions returns the regions gobizfly knows, each once
+func regions() []string {
+	seen := make(map[string]bool)
+	regions := []string{}
+	for _, region := range constants.RegionMapping {
+		if !seen[region] {
+			seen[region] = true
+			regions = append(regions, region)
+		}
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// baseURL returns the scheme and host the request was sent to
+func baseURL(r *http.Request) string {
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+	return "http://" + host
+}
+
+// matchFailure returns the injected failure the request triggers, if any
+func (c *Cloud) matchFailure(r *http.Request) *Failure {
+	for i, failure := range c.failures {
+		if failure.Method != "" && failure.Method != r.Method {
+			continue
+		}
+		if failure.Path != "" {
+			if ok, _ := path.Match(failure.Path, r.URL.Path); !ok {
+				continue
+			}
+		}
+		if failure.Times > 0 {
+			failure.Times--
+			if failure.Times == 0 {
+				c.failures = append(c.failures[:i:i], c.failures[i+1:]...)
+			}
+		}
+		return failure
+	}
+	return nil
+}
+
+func (c *Cloud) routes() {
+	c.mux.HandleFunc("POST /api/token", c.createToken)
+	c.mux.HandleFunc("GET /api/auth/service", c.listServices)
+	c.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fakeError(w, http.StatusNotImplemented, fmt.Sprintf("%s %s is not implemented by the fake cloud", r.Method, r.URL.Path))
+	})
+
+	server := fakeCloudServices["cloud_server"]
+	c.mux.HandleFunc("GET "+server+"/servers", c.listServers)
+	c.mux.HandleFunc("POST "+server+"/servers", c.createServers)
+	c.mux.HandleFunc("GET "+server+"/servers/{id}", c.getServer)
+	c.mux.HandleFunc("DELETE "+server+"/servers/{id}", c.deleteServer)
+	c.mux.HandleFunc("POST "+server+"/servers/{id}/action", c.serverAction)
+	c.mux.HandleFunc("GET "+server+"/tasks/{id}", c.getTask)
+	c.mux.HandleFunc("GET "+server+"/flavors", c.listFlavors)
+	c.mux.HandleFunc("GET "+server+"/images", c.listOSImages)
+	c.mux.HandleFunc("GET "+server+"/user/images", c.listCustomImages)
+	c.mux.HandleFunc("GET "+server+"/volumes", c.listVolumes)
+	c.mux.HandleFunc("POST "+server+"/volumes", c.createVolume)
+	c.mux.HandleFunc("GET "+server+"/volumes/{id}", c.getVolume)
+	c.mux.HandleFunc("DELETE "+server+"/volumes/{id}", c.deleteVolume)
+	c.mux.HandleFunc("POST "+server+"/volumes/{id}/action", c.volumeAction)
+	c.mux.HandleFunc("GET "+server+"/snapshots", c.listSnapshots)
+	c.mux.HandleFunc("POST "+server+"/snapshots", c.createSnapshot)
+	c.mux.HandleFunc("GET "+server+"/snapshots/{id}", c.getSnapshot)
+	c.mux.HandleFunc("DELETE "+server+"/snapshots/{id}", c.deleteSnapshot)
+
+	k8s := fakeCloudServices["kubernetes_engine"]
+	c.mux.HandleFunc("GET "+k8s+"/_/{$}", c.listClusters)
+	c.mux.HandleFunc("POST "+k8s+"/_/{$}", c.createCluster)
+	c.mux.HandleFunc("GET "+k8s+"/_/{id}", c.getCluster)
+	c.mux.HandleFunc("DELETE "+k8s+"/_/{id}", c.deleteCluster)
+	c.mux.HandleFunc("GET "+k8s+"/_/{id}/kubeconfig", c.getKubeConfig)
+	c.mux.HandleFunc("GET "+k8s+"/_/{id}/{pool}", c.getWorkerPool)
+	c.mux.HandleFunc("PATCH "+k8s+"/_/{id}/{pool}", c.updateWorkerPool)
+	c.mux.HandleFunc("DELETE "+k8s+"/_/{id}/{pool}", c.deleteWorkerPool)
+
+	db := fakeCloudServices["cloud_database"]
+	c.mux.HandleFunc("GET "+db+"/instances", c.listDatabases)
+	c.mux.HandleFunc("POST "+db+"/instances", c.createDatabase)
+	c.mux.HandleFunc("GET "+db+"/instances/{id}", c.getDatabase)
+	c.mux.HandleFunc("DELETE "+db+"/instances/{id}", c.deleteDatabase)
+	c.mux.HandleFunc("GET "+db+"/instances/{id}/nodes", c.listDatabaseNodes)
+	c.mux.HandleFunc("GET "+db+"/{kind}/{id}/backups", c.listDatabaseBackups)
+	c.mux.HandleFunc("POST "+db+"/{kind}/{id}/backups", c.createDatabaseBackup)
+	c.mux.HandleFunc("GET "+db+"/backups", c.listAllDatabaseBackups)
+	c.mux.HandleFunc("GET "+db+"/engines", c.listDatabaseEngines)
+
+	lb := fakeCloudServices["load_balancer"]
+	c.mux.HandleFunc("GET "+lb+"/loadbalancers", c.listLoadBalancers)
+	c.mux.HandleFunc("POST "+lb+"/loadbalancers", c.createLoadBalancer)
+	c.mux.HandleFunc("GET "+lb+"/loadbalancer/{id}", c.getLoadBalancer)
+	c.mux.HandleFunc("PUT "+lb+"/loadbalancer/{id}", c.updateLoadBalancer)
+	c.mux.HandleFunc("DELETE "+lb+"/loadbalancer/{id}", c.deleteLoadBalancer)
+
+	dns := fakeCloudServices["dns"]
+	c.mux.HandleFunc("GET "+dns+"/zones", c.listZones)
+	c.mux.HandleFunc("POST "+dns+"/zones", c.createZone)
+	c.mux.HandleFunc("GET "+dns+"/zone/{id}", c.getZone)
+	c.mux.HandleFunc("DELETE "+dns+"/zone/{id}", c.deleteZone)
+	c.mux.HandleFunc("POST "+dns+"/zone/{id}/record", c.createRecord)
+	c.mux.HandleFunc("GET "+dns+"/record/{id}", c.getRecord)
+	c.mux.HandleFunc("DELETE "+dns+"/record/{id}", c.deleteRecord)
+
+	cdn := fakeCloudServices["cdn"]
+	c.mux.HandleFunc("GET "+cdn+"/users/domains", c.listDomains)
+	c.mux.HandleFunc("POST "+cdn+"/clients/domains", c.createDomain)
+	c.mux.HandleFunc("GET "+cdn+"/clients/domains/{id}", c.getDomain)
+	c.mux.HandleFunc("PUT "+cdn+"/clients/domains/{id}", c.updateDomain)
+	c.mux.HandleFunc("DELETE "+cdn+"/clients/domains/{id}", c.deleteDomain)
+
+	kms := fakeCloudServices["key_management_service"]
+	c.mux.HandleFunc("GET "+kms+"/certificate_container", c.listCertificates)
+	c.mux.HandleFunc("POST "+kms+"/certificate_container", c.createCertificate)
+	c.mux.HandleFunc("GET "+kms+"/certificate_container/{id}", c.getCertificate)
+	c.mux.HandleFunc("DELETE "+kms+"/certificate_container/{id}", c.deleteCertificate)
+
+	registry := fakeCloudServices["container_registry"]
+	c.mux.HandleFunc("GET "+registry+"/_/{$}", c.listRepositories)
+	c.mux.HandleFunc("POST "+registry+"/_/{$}", c.createRepository)
+	c.mux.HandleFunc("GET "+registry+"/_/{repo}", c.getRepository)
+	c.mux.HandleFunc("PATCH "+registry+"/_/{repo}", c.editRepository)
+	c.mux.HandleFunc("DELETE "+registry+"/_/{repo}", c.deleteRepository)
+	c.mux.HandleFunc("GET "+registry+"/_/{repo}/tag/{tag}", c.getTag)
+	c.mux.HandleFunc("DELETE "+registry+"/_/{repo}/tag/{tag}", c.deleteTag)
+
+	autoScaling := fakeCloudServices["auto_scaling"]
+	c.mux.HandleFunc("POST "+autoScaling+"/quotas", c.checkQuotas)
+	c.mux.HandleFunc("GET "+autoScaling+"/groups", c.listAutoScalingGroups)
+	c.mux.HandleFunc("POST "+autoScaling+"/groups", c.createAutoScalingGroup)
+	c.mux.HandleFunc("GET "+autoScaling+"/groups/{id}", c.getAutoScalingGroup)
+	c.mux.HandleFunc("DELETE "+autoScaling+"/groups/{id}", c.deleteAutoScalingGroup)
+
+	alert := fakeCloudServices["alert"]
+	c.mux.HandleFunc("GET "+alert+"/alarms", c.listAlarms)
+	c.mux.HandleFunc("GET "+alert+"/alarms/{id}", c.getAlarm)
+	c.mux.HandleFunc("GET "+alert+"/receivers", c.listReceivers)
+	c.mux.HandleFunc("GET "+alert+"/receivers/{id}", c.getReceiver)
+}
+
+// fakeJSON writes v as the JSON response body
+func fakeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// fakeError writes an error response the way the Bizfly API does
+func fakeError(w http.ResponseWriter, status int, message string) {
+	fakeJSON(w, status, map[string]string{"message": message})
+}
+
+// fakeNotFound writes the 404 for a missing resource
+func fakeNotFound(w http.ResponseWriter, kind, id string) {
+	fakeError(w, http.StatusNotFound, fmt.Sprintf("%s %s could not be found", kind, id))
+}
+
+// decodeBody decodes the JSON request body into v, answering 400 when it can't
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		fakeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+// newID returns a fresh resource ID such as "server-0007"
+func (c *Cloud) newID(kind string) string {
+	c.nextID++
+	return fmt.Sprintf("%s-%04d", kind, c.nextID)
+}
+
+// timestamp returns the current time the way the API formats it
+func (c *Cloud) timestamp() string {
+	return c.now().UTC().Format(time.RFC3339)
+}
+
+// newTask records an operation and returns its task ID
+func (c *Cloud) newTask(action, serverID string) string {
+	id := c.newID("task")
+	c.tasks[id] = fakeTask{action: action, serverID: serverID}
+	return id
+}
+
+// startProvisioning puts a created or changed resource in its pending state until it has
+// been read provisionReads times, then in its ready or failed state
+func (c *Cloud) startProvisioning(id, name string, pending, ready, failed func()) {
+	finish := ready
+	if c.failNames[name] {
+		finish = failed
+	}
+	if c.provisionReads <= 0 {
+		finish()
+		return
+	}
+	pending()
+	c.provisioning[id] = &fakeProvisioning{remaining: c.provisionReads, finish: finish}
+}
+
+// readProvisioning counts a read of the resource, finishing its provisioning on the last one
+func (c *Cloud) readProvisioning(id string) {
+	p, ok := c.provisioning[id]
+	if !ok {
+		return
+	}
+	p.remaining--
+	if p.remaining <= 0 {
+		p.finish()
+		delete(c.provisioning, id)
+	}
+}
+
+// findByID returns the index of the item whose ID is id, or -1
+func findByID[T any](items []T, id string, idOf func(T) string) int {
+	for i, item := range items {
+		if idOf(item) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeAt returns items without the element at i
+func removeAt[T any](items []T, i int) []T {
+	return append(items[:i:i], items[i+1:]...)
+}
+
+// nonNil turns a nil slice into an empty one, so it is encoded as []
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// Authentication
+
+func (c *Cloud) createToken(w http.ResponseWriter, r *http.Request) {
+	var req gobizfly.TokenCreateRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	fakeJSON(w, http.StatusOK, gobizfly.Token{
+		ExpiresAt:     c.now().Add(time.Hour).UTC().Format(time.RFC3339),
+		KeystoneToken: fakeCloudToken,
+		ProjectID:     "fake-project",
+		ProjectName:   "fake-project",
+	})
+}
+
+func (c *Cloud) listServices(w http.ResponseWriter, r *http.Request) {
+	services := []*gobizfly.Service{}
+	for _, region := range regions() {
+		for name, prefix := range fakeCloudServices {
+			services = append(services, &gobizfly.Service{
+				Name:          name,
+				CanonicalName: name,
+				Region:        region,
+				Enabled:       true,
+				ServiceURL:    baseURL(r) + prefix,
+			})
+		}
+	}
+	fakeJSON(w, http.StatusOK, gobizfly.ServiceList{Services: services})
+}
+
+// Cloud servers
+
+func serverKey(s *gobizfly.Server) string { return s.ID }
+
+func (c *Cloud) server(id string) *gobizfly.Server {
+	if i := findByID(c.state.Servers, id, serverKey); i >= 0 {
+		return c.state.Servers[i]
+	}
+	return nil
+}
+
+func (c *Cloud) listServers(w http.ResponseWriter, r *http.Request) {
+	name, status := r.URL.Query().Get("name"), r.URL.Query().Get("status")
+	servers := []*gobizfly.Server{}
+	for _, s := range c.state.Servers {
+		if (name == "" || strings.Contains(s.Name, name)) && (status == "" || strings.EqualFold(s.Status, status)) {
+			servers = append(servers, s)
+		}
+	}
+	fakeJSON(w, http.StatusOK, servers)
+}
+
+// flavor returns the flavor with the given ID or name
+func (c *Cloud) flavor(idOrName string) *Flavor {
+	for _, flavor := range c.state.Flavors {
+		if flavor.ID == idOrName || flavor.Name == idOrName {
+			return flavor
+		}
+	}
+	return nil
+}
+
+func (c *Cloud) createServers(w http.ResponseWriter, r *http.Request) {
+	var reqs []*gobizfly.ServerCreateRequest
+	if !decodeBody(w, r, &reqs) {
+		return
+	}
+	tasks := []string{}
+	for _, req := range reqs {
+		if req.Name == "" {
+			fakeError(w, http.StatusBadRequest, "name is required")
+			return
+		}
+		flavor := c.flavor(req.FlavorName)
+		if flavor == nil && len(c.state.Flavors) > 0 {
+			fakeError(w, http.StatusBadRequest, fmt.Sprintf("flavor %s does not exist", req.FlavorName))
+			return
+		}
+		if flavor == nil {
+			flavor = &Flavor{ID: req.FlavorName, Name: req.FlavorName}
+		}
+		quantity := req.Quantity
+		if quantity < 1 {
+			quantity = 1
+		}
+		for n := 0; n < quantity; n++ {
+			server := c.newServer(req, flavor)
+			c.state.Servers = append(c.state.Servers, server)
+			c.startProvisioning(server.ID, server.Name,
+				func() { server.Status, server.Progress = "BUILD", 0 },
+				func() { server.Status, server.Progress = "ACTIVE", 100 },
+				func() { server.Status = "ERROR" },
+			)
+			tasks = append(tasks, c.newTask("create", server.ID))
+		}
+	}
+	fakeJSON(w, http.StatusAccepted, gobizfly.ServerCreateResponse{Task: tasks})
+}
+
+// newServer builds a server for the create request, with its root disk volume
+func (c *Cloud) newServer(req *gobizfly.ServerCreateRequest, flavor *Flavor) *gobizfly.Server {
+	id := c.newID("server")
+	host := c.nextID % 250
+	server := &gobizfly.Server{
+		ID:               id,
+		Name:             req.Name,
+		KeyName:          req.SSHKey,
+		CreatedAt:        c.timestamp(),
+		UpdatedAt:        c.timestamp(),
+		Status:           "ACTIVE",
+		Flavor:           gobizfly.Flavor{ID: flavor.ID, Name: flavor.Name, Ram: flavor.RAM, VCPU: flavor.VCPUs},
+		FlavorName:       flavor.Name,
+		Progress:         100,
+		AvailabilityZone: req.AvailabilityZone,
+		Category:         req.Type,
+		NetworkPlan:      req.NetworkPlan,
+		BillingPlan:      req.BillingPlan,
+		IsAvailable:      true,
+		IPAddresses: gobizfly.IPAddress{
+			LanAddresses:   []gobizfly.IP{{Version: 4, Address: fmt.Sprintf("10.20.0.%d", host+2), Type: "fixed"}},
+			WanV4Addresses: []gobizfly.IP{{Version: 4, Address: fmt.Sprintf("103.56.156.%d", host+2), Type: "floating"}},
+		},
+	}
+	if req.RootDisk != nil && req.RootDisk.Size > 0 {
+		volume := &gobizfly.Volume{
+			ID:               c.newID("volume"),
+			Name:             req.Name + "-rootdisk",
+			Size:             req.RootDisk.Size,
+			Bootable:         true,
+			AvailabilityZone: req.AvailabilityZone,
+			Status:           "available",
+			CreatedAt:        c.timestamp(),
+			UpdatedAt:        c.timestamp(),
+			Category:         req.Type,
+		}
+		if req.RootDisk.VolumeType != nil {
+			volume.VolumeType = *req.RootDisk.VolumeType
+		}
+		c.state.Volumes = append(c.state.Volumes, volume)
+		c.attachVolume(volume, server, "rootdisk")
+	}
+	return server
+}
+
+func (c *Cloud) getServer(w http.ResponseWriter, r *http.Request) {
+	c.readProvisioning(r.PathValue("id"))
+	server := c.server(r.PathValue("id"))
+	if server == nil {
+		fakeNotFound(w, "Server", r.PathValue("id"))
+		return
+	}
+	fakeJSON(w, http.StatusOK, server)
+}
+
+func (c *Cloud) deleteServer(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	i := findByID(c.state.Servers, id, serverKey)
+	if i < 0 {
+		fakeNotFound(w, "Server", id)
+		return
+	}
+	var req gobizfly.DeletedVolumes
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	server := c.state.Servers[i]
+	for _, attached := range server.AttachedVolumes {
+		volume := c.volume(attached.ID)
+		if volume == nil {
+			continue
+		}
+		c.detachVolume(volume, server)
+		for _, deleted := range req.IDs {
+			if deleted == volume.ID {
+				c.state.Volumes = removeAt(c.state.Volumes, findByID(c.state.Volumes, volume.ID, volumeKey))
+			}
+		}
+	}
+	c.state.Servers = removeAt(c.state.Servers, i)
+	fakeJSON(w, http.StatusAccepted, gobizfly.ServerTask{TaskID: c.newTask("delete", id)})
+}
+
+func (c *Cloud) serverAction(w http.ResponseWriter, r *http.Request) {
+	server := c.server(r.PathValue("id"))
+	if server == nil {
+		fakeNotFound(w, "Server", r.PathValue("id"))
+		return
+	}
+	var req gobizfly.ServerAction
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	server.UpdatedAt = c.timestamp()
+	switch req.Action {
+	case "start":
+		server.Status = "ACTIVE"
+		fakeJSON(w, http.StatusOK, server)
+	case "stop":
+		server.Status = "SHUTOFF"
+		fakeJSON(w, http.StatusOK, server)
+	case "soft_reboot", "hard_reboot":
+		rebooting := map[string]string{"soft_reboot": "REBOOT", "hard_reboot": "HARD_REBOOT"}[req.Action]
+		active := func() { server.Status = "ACTIVE" }
+		c.startProvisioning(server.ID, "", func() { server.Status = rebooting }, active, active)
+		fakeJSON(w, http.StatusOK, gobizfly.ServerMessageResponse{Message: fmt.Sprintf("Server %s is rebooting", server.ID)})
+	case "resize":
+		flavor := c.flavor(req.FlavorName)
+		if flavor == nil {
+			fakeError(w, http.StatusBadRequest, fmt.Sprintf("flavor %s does not exist", req.FlavorName))
+			return
+		}
+		server.Flavor = gobizfly.Flavor{ID: flavor.ID, Name: flavor.Name, Ram: flavor.RAM, VCPU: flavor.VCPUs}
+		server.FlavorName = flavor.Name
+		fakeJSON(w, http.StatusAccepted, gobizfly.ServerTask{TaskID: c.newTask("resize", server.ID)})
+	default:
+		fakeError(w, http.StatusBadRequest, fmt.Sprintf("unsupported action %q", req.Action))
+	}
+}
+
+func (c *Cloud) getTask(w http.ResponseWriter, r *http.Request) {
+	task, ok := c.tasks[r.PathValue("id")]
+	if !ok {
+		fakeNotFound(w, "Task", r.PathValue("id"))
+		return
+	}
+	result := gobizfly.ServerTaskResult{Action: task.action, Progress: 100, Success: true}
+	if server := c.server(task.serverID); server != nil {
+		result.Server = *server
+	}
+	fakeJSON(w, http.StatusOK, gobizfly.ServerTaskResponse{Ready: true, Result: result})
+}
+
+func (c *Cloud) listFlavors(w http.ResponseWriter, r *http.Request) {
+	fakeJSON(w, http.StatusOK, nonNil(c.state.Flavors))
+}
+
+func (c *Cloud) listOSImages(w http.ResponseWriter, r *http.Request) {
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"os_images": nonNil(c.state.OSImages)})
+}
+
+func (c *Cloud) listCustomImages(w http.ResponseWriter, r *http.Request) {
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"images": nonNil(c.state.CustomImages)})
+}
+
+// Volumes and snapshots
+
+func volumeKey(v *gobizfly.Volume) string { return v.ID }
+
+func (c *Cloud) volume(id string) *gobizfly.Volume {
+	if i := findByID(c.state.Volumes, id, volumeKey); i >= 0 {
+		return c.state.Volumes[i]
+	}
+	return nil
+}
+
+// attachVolume attaches the volume to the server on both sides
+func (c *Cloud) attachVolume(volume *gobizfly.Volume, server *gobizfly.Server, attachedType string) {
+	volume.Status = "in-use"
+	volume.AttachedType = attachedType
+	volume.UpdatedAt = c.timestamp()
+	volume.Attachments = append(volume.Attachments, gobizfly.VolumeAttachment{
+		ServerID:     server.ID,
+		VolumeID:     volume.ID,
+		ID:           volume.ID,
+		AttachmentID: c.newID("attachment"),
+		Device:       fmt.Sprintf("/dev/vd%c", 'a'+len(server.AttachedVolumes)),
+	})
+	server.AttachedVolumes = append(server.AttachedVolumes, gobizfly.AttachedVolume{
+		ID:           volume.ID,
+		Name:         volume.Name,
+		Size:         volume.Size,
+		AttachedType: attachedType,
+		Type:         volume.VolumeType,
+		Category:     volume.Category,
+	})
+}
+
+// detachVolume detaches the volume from the server on both sides
+func (c *Cloud) detachVolume(volume *gobizfly.Volume, server *gobizfly.Server) {
+	for i, attachment := range volume.Attachments {
+		if attachment.ServerID == server.ID {
+			volume.Attachments = removeAt(volume.Attachments, i)
+			break
+		}
+	}
+	for i, attached := range server.AttachedVolumes {
+		if attached.ID == volume.ID {
+			server.AttachedVolumes = removeAt(server.AttachedVolumes, i)
+			break
+		}
+	}
+	if len(volume.Attachments) == 0 {
+		volume.Status = "available"
+		volume.AttachedType = ""
+	}
+	volume.UpdatedAt = c.timestamp()
+}
+
+func (c *Cloud) listVolumes(w http.ResponseWriter, r *http.Request) {
+	fakeJSON(w, http.StatusOK, nonNil(c.state.Volumes))
+}
+
+func (c *Cloud) createVolume(w http.ResponseWriter, r *http.Request) {
+	var req gobizfly.VolumeCreateRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Name == "" || req.Size <= 0 {
+		fakeError(w, http.StatusBadRequest, "name and a positive size are required")
+		return
+	}
+	var server *gobizfly.Server
+	if req.ServerID != "" {
+		if server = c.server(req.ServerID); server == nil {
+			fakeNotFound(w, "Server", req.ServerID)
+			return
+		}
+	}
+	volume := &gobizfly.Volume{
+		ID:               c.newID("volume"),
+		Name:             req.Name,
+		Description:      req.Description,
+		Size:             req.Size,
+		VolumeType:       req.VolumeType,
+		Category:         req.VolumeCategory,
+		AvailabilityZone: req.AvailabilityZone,
+		SnapshotID:       req.SnapshotID,
+		BillingPlan:      req.BillingPlan,
+		Status:           "available",
+		CreatedAt:        c.timestamp(),
+		UpdatedAt:        c.timestamp(),
+	}
+	c.state.Volumes = append(c.state.Volumes, volume)
+	if server != nil {
+		c.attachVolume(volume, server, "datadisk")
+	}
+	fakeJSON(w, http.StatusAccepted, volume)
+}
+
+func (c *Cloud) getVolume(w http.ResponseWriter, r *http.Request) {
+	volume := c.volume(r.PathValue("id"))
+	if volume == nil {
+		fakeNotFound(w, "Volume", r.PathValue("id"))
+		return
+	}
+	fakeJSON(w, http.StatusOK, volume)
+}
+
+func (c *Cloud) deleteVolume(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	i := findByID(c.state.Volumes, id, volumeKey)
+	if i < 0 {
+		fakeNotFound(w, "Volume", id)
+		return
+	}
+	if c.state.Volumes[i].Status == "in-use" {
+		fakeError(w, http.StatusBadRequest, fmt.Sprintf("Volume %s is attached to a server and can't be deleted", id))
+		return
+	}
+	c.state.Volumes = removeAt(c.state.Volumes, i)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *Cloud) volumeAction(w http.ResponseWriter, r *http.Request) {
+	volume := c.volume(r.PathValue("id"))
+	if volume == nil {
+		fakeNotFound(w, "Volume", r.PathValue("id"))
+		return
+	}
+	var req gobizfly.VolumeAction
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	switch req.Type {
+	case "extend":
+		if req.NewSize <= volume.Size {
+			fakeError(w, http.StatusBadRequest, fmt.Sprintf("new size must be larger than the current size of %d GB", volume.Size))
+			return
+		}
+		volume.Size = req.NewSize
+		volume.UpdatedAt = c.timestamp()
+		fakeJSON(w, http.StatusAccepted, gobizfly.Task{TaskID: c.newTask("extend", "")})
+	case "attach":
+		server := c.server(req.ServerID)
+		if server == nil {
+			fakeNotFound(w, "Server", req.ServerID)
+			return
+		}
+		if volume.Status != "available" {
+			fakeError(w, http.StatusBadRequest, fmt.Sprintf("Volume %s is %s and can't be attached", volume.ID, volume.Status))
+			return
+		}
+		c.attachVolume(volume, server, "datadisk")
+		fakeJSON(w, http.StatusOK, gobizfly.VolumeAttachDetachResponse{Message: "Attached volume", VolumeDetail: *volume})
+	case "detach":
+		server := c.server(req.ServerID)
+		if server == nil {
+			fakeNotFound(w, "Server", req.ServerID)
+			return
+		}
+		attached := false
+		for _, attachment := range volume.Attachments {
+			attached = attached || attachment.ServerID == server.ID
+		}
+		if !attached {
+			fakeError(w, http.StatusBadRequest, fmt.Sprintf("Volume %s is not attached to server %s", volume.ID, server.ID))
+			return
+		}
+		c.detachVolume(volume, server)
+		fakeJSON(w, http.StatusOK, gobizfly.VolumeAttachDetachResponse{Message: "Detached volume", VolumeDetail: *volume})
+	default:
+		fakeError(w, http.StatusBadRequest, fmt.Sprintf("unsupported volume action %q", req.Type))
+	}
+}
+
+func snapshotKey(s *gobizfly.Snapshot) string { return s.ID }
+
+func (c *Cloud) listSnapshots(w http.ResponseWriter, r *http.Request) {
+	volume := r.URL.Query().Get("volume_id")
+	snapshots := []*gobizfly.Snapshot{}
+	for _, snapshot := range c.state.Snapshots {
+		if volume == "" || snapshot.VolumeID == volume {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	fakeJSON(w, http.StatusOK, snapshots)
+}
+
+func (c *Cloud) createSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req gobizfly.SnapshotCreateRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	volume := c.volume(req.VolumeID)
+	if volume == nil {
+		fakeNotFound(w, "Volume", req.VolumeID)
+		return
+	}
+	if volume.Status == "in-use" && !req.Force {
+		fakeError(w, http.StatusBadRequest, fmt.Sprintf("Volume %s is in use; set force to snapshot it", volume.ID))
+		return
+	}
+	snapshot := &gobizfly.Snapshot{
+		ID:           c.newID("snapshot"),
+		Name:         req.Name,
+		Status:       "available",
+		VolumeID:     volume.ID,
+		VolumeTypeID: volume.VolumeType,
+		Size:         volume.Size,
+		Progress:     "100%",
+		CreateAt:     c.timestamp(),
+		UpdatedAt:    c.timestamp(),
+		FromVolume:   *volume,
+		Category:     volume.Category,
+		ZoneName:     volume.AvailabilityZone,
+	}
+	c.state.Snapshots = append(c.state.Snapshots, snapshot)
+	fakeJSON(w, http.StatusAccepted, snapshot)
+}
+
+func (c *Cloud) getSnapshot(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.Snapshots, r.PathValue("id"), snapshotKey)
+	if i < 0 {
+		fakeNotFound(w, "Snapshot", r.PathValue("id"))
+		return
+	}
+	fakeJSON(w, http.StatusOK, c.state.Snapshots[i])
+}
+
+func (c *Cloud) deleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.Snapshots, r.PathValue("id"), snapshotKey)
+	if i < 0 {
+		fakeNotFound(w, "Snapshot", r.PathValue("id"))
+		return
+	}
+	c.state.Snapshots = removeAt(c.state.Snapshots, i)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Kubernetes Engine
+
+func clusterKey(cl *gobizfly.FullCluster) string { return cl.UID }
+
+func (c *Cloud) cluster(id string) *gobizfly.FullCluster {
+	if i := findByID(c.state.Clusters, id, clusterKey); i >= 0 {
+		return c.state.Clusters[i]
+	}
+	return nil
+}
+
+// workerPool returns the cluster's worker pool with the given ID
+func workerPool(cluster *gobizfly.FullCluster, id string) *gobizfly.ExtendedWorkerPool {
+	for i := range cluster.WorkerPools {
+		if cluster.WorkerPools[i].UID == id {
+			return &cluster.WorkerPools[i]
+		}
+	}
+	return nil
+}
+
+func (c *Cloud) listClusters(w http.ResponseWriter, r *http.Request) {
+	clusters := []gobizfly.Cluster{}
+	for _, cluster := range c.state.Clusters {
+		clusters = append(clusters, cluster.Cluster)
+	}
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"clusters": clusters})
+}
+
+func (c *Cloud) createCluster(w http.ResponseWriter, r *http.Request) {
+	var req gobizfly.ClusterCreateRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Name == "" || len(req.WorkerPools) == 0 {
+		fakeError(w, http.StatusBadRequest, "name and at least one worker pool are required")
+		return
+	}
+	cluster := &gobizfly.FullCluster{}
+	cluster.UID = c.newID("cluster")
+	cluster.Name = req.Name
+	cluster.Version = gobizfly.ControllerVersion{ID: req.Version, Name: req.Version, K8SVersion: req.Version}
+	cluster.ClusterPackage = gobizfly.KubernetesPackage{ID: req.Package, Name: req.Package}
+	cluster.VPCNetworkID = req.VPCNetworkID
+	cluster.AutoUpgrade = req.AutoUpgrade
+	cluster.Tags = req.Tags
+	cluster.ProvisionStatus = "PROVISIONED"
+	cluster.ClusterStatus = "HEALTHY"
+	cluster.ProvisionType = req.ProvisionType
+	cluster.CNIPlugin = req.CNIPlugin
+	cluster.LocalDNS = req.LocalDNS
+	cluster.CreatedAt = c.timestamp()
+	for _, pool := range req.WorkerPools {
+		cluster.WorkerPools = append(cluster.WorkerPools, gobizfly.ExtendedWorkerPool{
+			WorkerPool:      pool,
+			UID:             c.newID("pool"),
+			ProvisionStatus: "PROVISIONED",
+			CreatedAt:       c.timestamp(),
+		})
+	}
+	c.updateClusterStat(cluster)
+	c.state.Clusters = append(c.state.Clusters, cluster)
+	c.startProvisioning(cluster.UID, cluster.Name,
+		func() { cluster.ProvisionStatus, cluster.ClusterStatus = "PROVISIONING", "PROVISIONING" },
+		func() { cluster.ProvisionStatus, cluster.ClusterStatus = "PROVISIONED", "HEALTHY" },
+		func() { cluster.ProvisionStatus, cluster.ClusterStatus = "FAILED", "UNHEALTHY" },
+	)
+	fakeJSON(w, http.StatusAccepted, map[string]interface{}{"cluster": cluster.ExtendedCluster})
+}
+
+// updateClusterStat recounts the cluster's worker pools
+func (c *Cloud) updateClusterStat(cluster *gobizfly.FullCluster) {
+	cluster.WorkerPoolsCount = len(cluster.WorkerPools)
+	cluster.Stat.WorkerPoolCount = len(cluster.WorkerPools)
+}
+
+func (c *Cloud) getCluster(w http.ResponseWriter, r *http.Request) {
+	c.readProvisioning(r.PathValue("id"))
+	cluster := c.cluster(r.PathValue("id"))
+	if cluster == nil {
+		fakeNotFound(w, "Cluster", r.PathValue("id"))
+		return
+	}
+	fakeJSON(w, http.StatusOK, cluster)
+}
+
+func (c *Cloud) deleteCluster(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.Clusters, r.PathValue("id"), clusterKey)
+	if i < 0 {
+		fakeNotFound(w, "Cluster", r.PathValue("id"))
+		return
+	}
+	c.state.Clusters = removeAt(c.state.Clusters, i)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *Cloud) getKubeConfig(w http.ResponseWriter, r *http.Request) {
+	cluster := c.cluster(r.PathValue("id"))
+	if cluster == nil {
+		fakeNotFound(w, "Cluster", r.PathValue("id"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	fmt.Fprintf(w, `apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: https://%[2]s.k8s.fake.bizflycloud.vn:6443
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: %[1]s-admin
+current-context: %[1]s
+users:
+- name: %[1]s-admin
+  user:
+    token: fake-kubernetes-token
+`, cluster.Name, cluster.UID)
+}
+
+func (c *Cloud) getWorkerPool(w http.ResponseWriter, r *http.Request) {
+	cluster := c.cluster(r.PathValue("id"))
+	if cluster == nil {
+		fakeNotFound(w, "Cluster", r.PathValue("id"))
+		return
+	}
+	pool := workerPool(cluster, r.PathValue("pool"))
+	if pool == nil {
+		fakeNotFound(w, "Worker pool", r.PathValue("pool"))
+		return
+	}
+	nodes := []gobizfly.PoolNode{}
+	for n := 1; n <= pool.DesiredSize; n++ {
+		nodes = append(nodes, gobizfly.PoolNode{
+			ID:          fmt.Sprintf("%s-node-%d", pool.UID, n),
+			Name:        fmt.Sprintf("%s-%s-%d", cluster.Name, pool.Name, n),
+			PhysicalID:  fmt.Sprintf("%s-server-%d", pool.UID, n),
+			IPAddresses: []string{fmt.Sprintf("10.30.0.%d", n+1)},
+			Status:      "ACTIVE",
+		})
+	}
+	fakeJSON(w, http.StatusOK, gobizfly.WorkerPoolWithNodes{ExtendedWorkerPool: *pool, Nodes: nodes})
+}
+
+func (c *Cloud) updateWorkerPool(w http.ResponseWriter, r *http.Request) {
+	cluster := c.cluster(r.PathValue("id"))
+	if cluster == nil {
+		fakeNotFound(w, "Cluster", r.PathValue("id"))
+		return
+	}
+	pool := workerPool(cluster, r.PathValue("pool"))
+	if pool == nil {
+		fakeNotFound(w, "Worker pool", r.PathValue("pool"))
+		return
+	}
+	var req gobizfly.UpdateWorkerPoolRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.DesiredSize > 0 {
+		pool.DesiredSize = req.DesiredSize
+	}
+	pool.EnableAutoScaling = req.EnableAutoScaling
+	if req.MinSize > 0 {
+		pool.MinSize = req.MinSize
+	}
+	if req.MaxSize > 0 {
+		pool.MaxSize = req.MaxSize
+	}
+	if req.Labels != nil {
+		pool.Labels = req.Labels
+	}
+	if req.Taints != nil {
+		pool.Taints = req.Taints
+	}
+	fakeJSON(w, http.StatusAccepted, map[string]string{"message": "Worker pool is being updated"})
+}
+
+func (c *Cloud) deleteWorkerPool(w http.ResponseWriter, r *http.Request) {
+	cluster := c.cluster(r.PathValue("id"))
+	if cluster == nil {
+		fakeNotFound(w, "Cluster", r.PathValue("id"))
+		return
+	}
+	for i, pool := range cluster.WorkerPools {
+		if pool.UID == r.PathValue("pool") {
+			cluster.WorkerPools = removeAt(cluster.WorkerPools, i)
+			c.updateClusterStat(cluster)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	fakeNotFound(w, "Worker pool", r.PathValue("pool"))
+}
+
+// Cloud database
+
+func databaseKey(db *gobizfly.CloudDatabaseInstance) string { return db.ID }
+
+func (c *Cloud) database(id string) *gobizfly.CloudDatabaseInstance {
+	if i := findByID(c.state.Databases, id, databaseKey); i >= 0 {
+		return c.state.Databases[i]
+	}
+	return nil
+}
+
+func (c *Cloud) listDatabases(w http.ResponseWriter, r *http.Request) {
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"instances": nonNil(c.state.Databases)})
+}
+
+func (c *Cloud) createDatabase(w http.ResponseWriter, r *http.Request) {
+	var req gobizfly.CloudDatabaseInstanceCreate
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Name == "" || req.FlavorName == "" || req.VolumeSize <= 0 {
+		fakeError(w, http.StatusBadRequest, "name, flavor_name and volume_size are required")
+		return
+	}
+	id := c.newID("database")
+	instanceType := req.InstanceType
+	if instanceType == "" {
+		instanceType = "Standalone"
+	}
+	node := gobizfly.CloudDatabaseNode{
+		ID:               c.newID("node"),
+		Name:             req.Name + "-primary",
+		InstanceID:       id,
+		AvailabilityZone: req.AvailabilityZone,
+		CreatedAt:        c.timestamp(),
+		Datastore:        req.Datastore,
+		Flavor:           req.FlavorName,
+		Role:             "primary",
+		NodeType:         "primary",
+		OperatingStatus:  "ACTIVE",
+		Status:           "ACTIVE",
+		Volume:           gobizfly.CloudDatabaseVolume{Size: req.VolumeSize},
+		Addresses: gobizfly.CloudDatabaseAddresses{
+			Private: []gobizfly.CloudDatabaseAddressesDetail{{IPAddress: fmt.Sprintf("10.40.0.%d", c.nextID%250+2), Network: "default", Port: 3306}},
+		},
+	}
+	instance := &gobizfly.CloudDatabaseInstance{
+		ID:             id,
+		Name:           req.Name,
+		CreatedAt:      c.timestamp(),
+		Datastore:      req.Datastore,
+		EnableFailover: req.EnableFailover,
+		InstanceType:   instanceType,
+		PublicAccess:   req.PublicAccess,
+		Status:         "ACTIVE",
+		Volume:         gobizfly.CloudDatabaseVolume{Size: req.VolumeSize},
+		DNS:            gobizfly.CloudDatabaseDNS{Private: id + ".db.fake.bizflycloud.vn"},
+		Nodes:          []gobizfly.CloudDatabaseNode{node},
+	}
+	c.state.Databases = append(c.state.Databases, instance)
+	c.startProvisioning(instance.ID, instance.Name,
+		func() { setDatabaseStatus(instance, "BUILD") },
+		func() { setDatabaseStatus(instance, "ACTIVE") },
+		func() { setDatabaseStatus(instance, "ERROR") },
+	)
+	fakeJSON(w, http.StatusAccepted, instance)
+}
+
+// setDatabaseStatus sets the status of the instance and its nodes
+func setDatabaseStatus(instance *gobizfly.CloudDatabaseInstance, status string) {
+	instance.Status = status
+	for i := range instance.Nodes {
+		instance.Nodes[i].Status = status
+		instance.Nodes[i].OperatingStatus = status
+	}
+}
+
+func (c *Cloud) getDatabase(w http.ResponseWriter, r *http.Request) {
+	c.readProvisioning(r.PathValue("id"))
+	instance := c.database(r.PathValue("id"))
+	if instance == nil {
+		fakeNotFound(w, "Instance", r.PathValue("id"))
+		return
+	}
+	fakeJSON(w, http.StatusOK, instance)
+}
+
+func (c *Cloud) deleteDatabase(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	i := findByID(c.state.Databases, id, databaseKey)
+	if i < 0 {
+		fakeNotFound(w, "Instance", id)
+		return
+	}
+	c.state.Databases = removeAt(c.state.Databases, i)
+	delete(c.state.DatabaseBackups, id)
+	fakeJSON(w, http.StatusAccepted, gobizfly.CloudDatabaseMessageResponse{
+		Message: fmt.Sprintf("Instance %s is being deleted", id),
+		TaskID:  c.newTask("delete", ""),
+	})
+}
+
+func (c *Cloud) listDatabaseNodes(w http.ResponseWriter, r *http.Request) {
+	instance := c.database(r.PathValue("id"))
+	if instance == nil {
+		fakeNotFound(w, "Instance", r.PathValue("id"))
+		return
+	}
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"nodes": nonNil(instance.Nodes)})
+}
+
+// backupOwner returns the database instance a backup path refers to, either
+// directly or through one of its nodes
+func (c *Cloud) backupOwner(kind, id string) *gobizfly.CloudDatabaseInstance {
+	switch kind {
+	case "instance", "instances":
+		return c.database(id)
+	case "node", "nodes":
+		for _, instance := range c.state.Databases {
+			for _, node := range instance.Nodes {
+				if node.ID == id {
+					return instance
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Cloud) listDatabaseBackups(w http.ResponseWriter, r *http.Request) {
+	kind, id := r.PathValue("kind"), r.PathValue("id")
+	instance := c.backupOwner(kind, id)
+	if instance == nil {
+		fakeNotFound(w, "Resource", id)
+		return
+	}
+	backups := []*gobizfly.CloudDatabaseBackup{}
+	for _, backup := range c.state.DatabaseBackups[instance.ID] {
+		if kind == "instance" || kind == "instances" || backup.NodeID == id {
+			backups = append(backups, backup)
+		}
+	}
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"backups": backups})
+}
+
+func (c *Cloud) createDatabaseBackup(w http.ResponseWriter, r *http.Request) {
+	kind, id := r.PathValue("kind"), r.PathValue("id")
+	instance := c.backupOwner(kind, id)
+	if instance == nil {
+		fakeNotFound(w, "Resource", id)
+		return
+	}
+	var req gobizfly.CloudDatabaseBackupCreate
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		fakeError(w, http.StatusBadRequest, "backup_name is required")
+		return
+	}
+	nodeID := req.NodeID
+	if nodeID == "" && len(instance.Nodes) > 0 {
+		nodeID = instance.Nodes[0].ID
+	}
+	backup := &gobizfly.CloudDatabaseBackup{
+		ID:        c.newID("backup"),
+		Name:      req.Name,
+		NodeID:    nodeID,
+		ParentID:  req.ParentID,
+		Datastore: instance.Datastore,
+		Status:    "COMPLETED",
+		Type:      "manual",
+		Created:   c.timestamp(),
+		Updated:   c.timestamp(),
+		Size:      0.1,
+	}
+	c.state.DatabaseBackups[instance.ID] = append(c.state.DatabaseBackups[instance.ID], backup)
+	fakeJSON(w, http.StatusAccepted, backup)
+}
+
+func (c *Cloud) listAllDatabaseBackups(w http.ResponseWriter, r *http.Request) {
+	backups := []*gobizfly.CloudDatabaseBackup{}
+	for _, instance := range c.state.Databases {
+		backups = append(backups, c.state.DatabaseBackups[instance.ID]...)
+	}
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"backups": backups})
+}
+
+func (c *Cloud) listDatabaseEngines(w http.ResponseWriter, r *http.Request) {
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"engines": nonNil(c.state.DatabaseEngines)})
+}
+
+// Load balancers
+
+func loadBalancerKey(lb *gobizfly.LoadBalancer) string { return lb.ID }
+
+func (c *Cloud) listLoadBalancers(w http.ResponseWriter, r *http.Request) {
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"loadbalancers": nonNil(c.state.LoadBalancers)})
+}
+
+func (c *Cloud) createLoadBalancer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		LoadBalancer gobizfly.LoadBalancerCreateRequest `json:"loadbalancer"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.LoadBalancer.Name == "" {
+		fakeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	lb := &gobizfly.LoadBalancer{
+		ID:                 c.newID("loadbalancer"),
+		Name:               req.LoadBalancer.Name,
+		Description:        req.LoadBalancer.Description,
+		NetworkType:        req.LoadBalancer.NetworkType,
+		Type:               req.LoadBalancer.Type,
+		VipNetworkID:       req.LoadBalancer.VPCNetworkID,
+		VipAddress:         fmt.Sprintf("103.56.157.%d", c.nextID%250+2),
+		AdminStateUp:       true,
+		OperatingStatus:    "ONLINE",
+		ProvisioningStatus: "ACTIVE",
+		CreatedAt:          c.timestamp(),
+		UpdatedAt:          c.timestamp(),
+	}
+	c.state.LoadBalancers = append(c.state.LoadBalancers, lb)
+	fakeJSON(w, http.StatusAccepted, map[string]interface{}{"loadbalancer": lb})
+}
+
+func (c *Cloud) getLoadBalancer(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.LoadBalancers, r.PathValue("id"), loadBalancerKey)
+	if i < 0 {
+		fakeNotFound(w, "Load balancer", r.PathValue("id"))
+		return
+	}
+	c.readProvisioning(r.PathValue("id"))
+	fakeJSON(w, http.StatusOK, c.state.LoadBalancers[i])
+}
+
+func (c *Cloud) updateLoadBalancer(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.LoadBalancers, r.PathValue("id"), loadBalancerKey)
+	if i < 0 {
+		fakeNotFound(w, "Load balancer", r.PathValue("id"))
+		return
+	}
+	var req struct {
+		LoadBalancer gobizfly.LoadBalancerUpdateRequest `json:"loadbalancer"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	lb := c.state.LoadBalancers[i]
+	if req.LoadBalancer.Name != nil {
+		lb.Name = *req.LoadBalancer.Name
+	}
+	if req.LoadBalancer.Description != nil {
+		lb.Description = *req.LoadBalancer.Description
+	}
+	if req.LoadBalancer.AdminStateUp != nil {
+		lb.AdminStateUp = *req.LoadBalancer.AdminStateUp
+	}
+	lb.UpdatedAt = c.timestamp()
+	active := func() { lb.ProvisioningStatus = "ACTIVE" }
+	c.startProvisioning(lb.ID, "", func() { lb.ProvisioningStatus = "PENDING_UPDATE" }, active, active)
+	fakeJSON(w, http.StatusAccepted, map[string]interface{}{"loadbalancer": lb})
+}
+
+func (c *Cloud) deleteLoadBalancer(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.LoadBalancers, r.PathValue("id"), loadBalancerKey)
+	if i < 0 {
+		fakeNotFound(w, "Load balancer", r.PathValue("id"))
+		return
+	}
+	c.state.LoadBalancers = removeAt(c.state.LoadBalancers, i)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DNS
+
+func zoneKey(z *gobizfly.ExtendedZone) string { return z.ID }
+
+func (c *Cloud) zone(id string) *gobizfly.ExtendedZone {
+	if i := findByID(c.state.DNSZones, id, zoneKey); i >= 0 {
+		return c.state.DNSZones[i]
+	}
+	return nil
+}
+
+func (c *Cloud) listZones(w http.ResponseWriter, r *http.Request) {
+	zones := []gobizfly.Zone{}
+	for _, zone := range c.state.DNSZones {
+		zones = append(zones, zone.Zone)
+	}
+	fakeJSON(w, http.StatusOK, gobizfly.ListZoneResp{
+		Zones: zones,
+		Meta:  gobizfly.Meta{MaxResults: len(zones), Total: len(zones), Page: 1},
+	})
+}
+
+func (c *Cloud) createZone(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Zones gobizfly.CreateZonePayload `json:"zones"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Zones.Name == "" {
+		fakeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	for _, zone := range c.state.DNSZones {
+		if zone.Name == req.Zones.Name {
+			fakeError(w, http.StatusBadRequest, fmt.Sprintf("Zone %s already exists", zone.Name))
+			return
+		}
+	}
+	zone := &gobizfly.ExtendedZone{
+		Zone: gobizfly.Zone{
+			ID:         c.newID("zone"),
+			Name:       req.Zones.Name,
+			CreatedAt:  c.timestamp(),
+			UpdatedAt:  c.timestamp(),
+			NameServer: []string{"ns1.bizflycloud.vn", "ns2.bizflycloud.vn"},
+			TTL:        3600,
+			Active:     true,
+		},
+		RecordsSet: []gobizfly.Record{},
+	}
+	c.state.DNSZones = append(c.state.DNSZones, zone)
+	fakeJSON(w, http.StatusCreated, zone)
+}
+
+func (c *Cloud) getZone(w http.ResponseWriter, r *http.Request) {
+	zone := c.zone(r.PathValue("id"))
+	if zone == nil {
+		fakeNotFound(w, "Zone", r.PathValue("id"))
+		return
+	}
+	fakeJSON(w, http.StatusOK, zone)
+}
+
+func (c *Cloud) deleteZone(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.DNSZones, r.PathValue("id"), zoneKey)
+	if i < 0 {
+		fakeNotFound(w, "Zone", r.PathValue("id"))
+		return
+	}
+	c.state.DNSZones = removeAt(c.state.DNSZones, i)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// record returns the zone holding the record and the record's index in it
+func (c *Cloud) record(id string) (*gobizfly.ExtendedZone, int) {
+	for _, zone := range c.state.DNSZones {
+		for i, record := range zone.RecordsSet {
+			if record.ID == id {
+				return zone, i
+			}
+		}
+	}
+	return nil, -1
+}
+
+func (c *Cloud) createRecord(w http.ResponseWriter, r *http.Request) {
+	zone := c.zone(r.PathValue("id"))
+	if zone == nil {
+		fakeNotFound(w, "Zone", r.PathValue("id"))
+		return
+	}
+	var req struct {
+		Record gobizfly.Record `json:"record"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Record.Name == "" || req.Record.Type == "" {
+		fakeError(w, http.StatusBadRequest, "name and type are required")
+		return
+	}
+	record := req.Record
+	record.ID = c.newID("record")
+	record.ZoneID = zone.ID
+	record.CreatedAt = c.timestamp()
+	record.UpdatedAt = c.timestamp()
+	zone.RecordsSet = append(zone.RecordsSet, record)
+	fakeJSON(w, http.StatusCreated, map[string]interface{}{"record": record})
+}
+
+func (c *Cloud) getRecord(w http.ResponseWriter, r *http.Request) {
+	zone, i := c.record(r.PathValue("id"))
+	if zone == nil {
+		fakeNotFound(w, "Record", r.PathValue("id"))
+		return
+	}
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"record": zone.RecordsSet[i]})
+}
+
+func (c *Cloud) deleteRecord(w http.ResponseWriter, r *http.Request) {
+	zone, i := c.record(r.PathValue("id"))
+	if zone == nil {
+		fakeNotFound(w, "Record", r.PathValue("id"))
+		return
+	}
+	zone.RecordsSet = removeAt(zone.RecordsSet, i)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CDN
+
+func domainKey(d *gobizfly.Domain) string { return d.DomainID }
+
+func (c *Cloud) listDomains(w http.ResponseWriter, r *http.Request) {
+	domains := []gobizfly.Domain{}
+	for _, domain := range c.state.CDNDomains {
+		domains = append(domains, *domain)
+	}
+	fakeJSON(w, http.StatusOK, gobizfly.DomainsResp{Domains: domains, Pages: 1, Total: len(domains)})
+}
+
+func (c *Cloud) createDomain(w http.ResponseWriter, r *http.Request) {
+	var req gobizfly.CreateDomainPayload
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Domain == "" {
+		fakeError(w, http.StatusBadRequest, "domain is required")
+		return
+	}
+	slug := strings.ReplaceAll(req.Domain, ".", "-")
+	domain := &gobizfly.Domain{
+		Domain:    req.Domain,
+		Slug:      slug,
+		DomainCDN: slug + ".cdn.fake.bizflycloud.vn",
+		DomainID:  c.newID("domain"),
+	}
+	c.state.CDNDomains = append(c.state.CDNDomains, domain)
+	fakeJSON(w, http.StatusCreated, gobizfly.CreateDomainResponse{Message: "Created domain", Domain: *domain})
+}
+
+func (c *Cloud) getDomain(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.CDNDomains, r.PathValue("id"), domainKey)
+	if i < 0 {
+		fakeNotFound(w, "Domain", r.PathValue("id"))
+		return
+	}
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"domain": c.state.CDNDomains[i]})
+}
+
+func (c *Cloud) updateDomain(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.CDNDomains, r.PathValue("id"), domainKey)
+	if i < 0 {
+		fakeNotFound(w, "Domain", r.PathValue("id"))
+		return
+	}
+	var req gobizfly.UpdateDomainPayload
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	domain := c.state.CDNDomains[i]
+	extended := gobizfly.ExtendedDomain{Domain: *domain, Slug: domain.Slug, DomainCDN: domain.DomainCDN}
+	if req.Origin != nil {
+		extended.UpstreamHost = req.Origin.UpstreamHost
+		extended.OriginAddrs = []gobizfly.OriginAddr{{Type: req.Origin.UpstreamProto, Host: req.Origin.UpstreamAddrs}}
+	}
+	fakeJSON(w, http.StatusOK, gobizfly.UpdateDomainResp{Message: "Updated domain", Domain: extended})
+}
+
+// deleteDomain deletes the domain, or purges its cache when files are given
+func (c *Cloud) deleteDomain(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.CDNDomains, r.PathValue("id"), domainKey)
+	if i < 0 {
+		fakeNotFound(w, "Domain", r.PathValue("id"))
+		return
+	}
+	var files gobizfly.Files
+	if err := json.NewDecoder(r.Body).Decode(&files); err == nil && files.Files != nil {
+		fakeJSON(w, http.StatusOK, map[string]string{"message": "Purged cache"})
+		return
+	}
+	c.state.CDNDomains = removeAt(c.state.CDNDomains, i)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// KMS certificates
+
+func certificateKey(cert *gobizfly.KMSCertificateGetResponse) string { return cert.ContainerID }
+
+func (c *Cloud) listCertificates(w http.ResponseWriter, r *http.Request) {
+	certificates := []*gobizfly.KMSCertificate{}
+	for _, cert := range c.state.Certificates {
+		certificates = append(certificates, &gobizfly.KMSCertificate{ContainerID: cert.ContainerID, Name: cert.Name})
+	}
+	fakeJSON(w, http.StatusOK, gobizfly.KMSCertificateListResponse{CertificateContainer: certificates, Total: len(certificates)})
+}
+
+func (c *Cloud) createCertificate(w http.ResponseWriter, r *http.Request) {
+	var req gobizfly.KMSCertificateContainerCreateRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.CertContainer.Name == "" {
+		fakeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	cert := &gobizfly.KMSCertificateGetResponse{
+		ContainerID: c.newID("certificate"),
+		Name:        req.CertContainer.Name,
+		Certificate: req.CertContainer.Certificate.Payload,
+	}
+	c.state.Certificates = append(c.state.Certificates, cert)
+	fakeJSON(w, http.StatusCreated, gobizfly.KMSCertificateCreateResponse{
+		CertificateHref: baseURL(r) + fakeCloudServices["key_management_service"] + "/certificate_container/" + cert.ContainerID,
+	})
+}
+
+func (c *Cloud) getCertificate(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.Certificates, r.PathValue("id"), certificateKey)
+	if i < 0 {
+		fakeNotFound(w, "Certificate container", r.PathValue("id"))
+		return
+	}
+	fakeJSON(w, http.StatusOK, c.state.Certificates[i])
+}
+
+func (c *Cloud) deleteCertificate(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.Certificates, r.PathValue("id"), certificateKey)
+	if i < 0 {
+		fakeNotFound(w, "Certificate container", r.PathValue("id"))
+		return
+	}
+	c.state.Certificates = removeAt(c.state.Certificates, i)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Container registry
+
+func repositoryKey(repo *gobizfly.TagRepository) string { return repo.Repository.Name }
+
+func (c *Cloud) listRepositories(w http.ResponseWriter, r *http.Request) {
+	repositories := []gobizfly.Repository{}
+	for _, repo := range c.state.Repositories {
+		repositories = append(repositories, repo.Repository)
+	}
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"repositories": repositories})
+}
+
+func (c *Cloud) createRepository(w http.ResponseWriter, r *http.Request) {
+	var req gobizfly.CreateRepositoryPayload
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		fakeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if findByID(c.state.Repositories, req.Name, repositoryKey) >= 0 {
+		fakeError(w, http.StatusBadRequest, fmt.Sprintf("Repository %s already exists", req.Name))
+		return
+	}
+	c.state.Repositories = append(c.state.Repositories, &gobizfly.TagRepository{
+		Repository: gobizfly.Repository{Name: req.Name, Public: req.Public, CreatedAt: c.timestamp()},
+		Tags:       []gobizfly.RepositoryTag{},
+	})
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (c *Cloud) getRepository(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.Repositories, r.PathValue("repo"), repositoryKey)
+	if i < 0 {
+		fakeNotFound(w, "Repository", r.PathValue("repo"))
+		return
+	}
+	fakeJSON(w, http.StatusOK, c.state.Repositories[i])
+}
+
+func (c *Cloud) editRepository(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.Repositories, r.PathValue("repo"), repositoryKey)
+	if i < 0 {
+		fakeNotFound(w, "Repository", r.PathValue("repo"))
+		return
+	}
+	var req gobizfly.EditRepositoryPayload
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	c.state.Repositories[i].Repository.Public = req.Public
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *Cloud) deleteRepository(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.Repositories, r.PathValue("repo"), repositoryKey)
+	if i < 0 {
+		fakeNotFound(w, "Repository", r.PathValue("repo"))
+		return
+	}
+	c.state.Repositories = removeAt(c.state.Repositories, i)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tag returns the repository and the index of its tag, or -1 when the tag doesn't exist
+func (c *Cloud) tag(repo, tag string) (*gobizfly.TagRepository, int) {
+	i := findByID(c.state.Repositories, repo, repositoryKey)
+	if i < 0 {
+		return nil, -1
+	}
+	repository := c.state.Repositories[i]
+	for j, t := range repository.Tags {
+		if t.Name == tag {
+			return repository, j
+		}
+	}
+	return repository, -1
+}
+
+func (c *Cloud) getTag(w http.ResponseWriter, r *http.Request) {
+	repository, i := c.tag(r.PathValue("repo"), r.PathValue("tag"))
+	if i < 0 {
+		fakeNotFound(w, "Tag", r.PathValue("repo")+":"+r.PathValue("tag"))
+		return
+	}
+	fakeJSON(w, http.StatusOK, gobizfly.Image{
+		Repository:      repository.Repository,
+		Tag:             repository.Tags[i],
+		Vulnerabilities: []gobizfly.Vulnerability{},
+	})
+}
+
+func (c *Cloud) deleteTag(w http.ResponseWriter, r *http.Request) {
+	repository, i := c.tag(r.PathValue("repo"), r.PathValue("tag"))
+	if i < 0 {
+		fakeNotFound(w, "Tag", r.PathValue("repo")+":"+r.PathValue("tag"))
+		return
+	}
+	repository.Tags = removeAt(repository.Tags, i)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AutoScaling
+
+func autoScalingGroupKey(g *gobizfly.AutoScalingGroup) string { return g.ID }
+
+// checkQuotas accepts every group: gobizfly checks quotas before creating one
+func (c *Cloud) checkQuotas(w http.ResponseWriter, r *http.Request) {
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"message": map[string]bool{"valid": true}})
+}
+
+func (c *Cloud) listAutoScalingGroups(w http.ResponseWriter, r *http.Request) {
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"clusters": nonNil(c.state.AutoScalingGroups)})
+}
+
+func (c *Cloud) createAutoScalingGroup(w http.ResponseWriter, r *http.Request) {
+	var req gobizfly.AutoScalingGroupCreateRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Name == "" || req.ProfileID == "" {
+		fakeError(w, http.StatusBadRequest, "name and profile_id are required")
+		return
+	}
+	if req.MinSize > req.DesiredCapacity || req.DesiredCapacity > req.MaxSize {
+		fakeError(w, http.StatusBadRequest, "desired_capacity must be between min_size and max_size")
+		return
+	}
+	group := &gobizfly.AutoScalingGroup{
+		ID:              c.newID("autoscaling"),
+		Name:            req.Name,
+		ProfileID:       req.ProfileID,
+		MinSize:         req.MinSize,
+		MaxSize:         req.MaxSize,
+		DesiredCapacity: req.DesiredCapacity,
+		NodeIDs:         []string{},
+		Status:          "ACTIVE",
+		Created:         c.timestamp(),
+		Updated:         c.timestamp(),
+	}
+	c.state.AutoScalingGroups = append(c.state.AutoScalingGroups, group)
+	fakeJSON(w, http.StatusAccepted, group)
+}
+
+func (c *Cloud) getAutoScalingGroup(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.AutoScalingGroups, r.PathValue("id"), autoScalingGroupKey)
+	if i < 0 {
+		fakeNotFound(w, "AutoScaling group", r.PathValue("id"))
+		return
+	}
+	fakeJSON(w, http.StatusOK, c.state.AutoScalingGroups[i])
+}
+
+func (c *Cloud) deleteAutoScalingGroup(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.AutoScalingGroups, r.PathValue("id"), autoScalingGroupKey)
+	if i < 0 {
+		fakeNotFound(w, "AutoScaling group", r.PathValue("id"))
+		return
+	}
+	c.state.AutoScalingGroups = removeAt(c.state.AutoScalingGroups, i)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CloudWatcher
+
+func alarmKey(a *gobizfly.Alarms) string       { return a.ID }
+func receiverKey(r *gobizfly.Receivers) string { return r.ReceiverID }
+
+func (c *Cloud) listAlarms(w http.ResponseWriter, r *http.Request) {
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"_items": nonNil(c.state.Alarms)})
+}
+
+func (c *Cloud) getAlarm(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.Alarms, r.PathValue("id"), alarmKey)
+	if i < 0 {
+		fakeNotFound(w, "Alarm", r.PathValue("id"))
+		return
+	}
+	fakeJSON(w, http.StatusOK, c.state.Alarms[i])
+}
+
+func (c *Cloud) listReceivers(w http.ResponseWriter, r *http.Request) {
+	fakeJSON(w, http.StatusOK, map[string]interface{}{"_items": nonNil(c.state.Receivers)})
+}
+
+func (c *Cloud) getReceiver(w http.ResponseWriter, r *http.Request) {
+	i := findByID(c.state.Receivers, r.PathValue("id"), receiverKey)
+	if i < 0 {
+		fakeNotFound(w, "Receiver", r.PathValue("id"))
+		return
+	}
+	fakeJSON(w, http.StatusOK, c.state.Receivers[i])
+}
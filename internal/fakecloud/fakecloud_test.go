@@ -0,0 +1,94 @@
+package fakecloud
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bizflycloud/gobizfly"
+)
+
+func testState() State {
+	return State{
+		Servers: []*gobizfly.Server{{ID: "srv-1", Name: "web-1", Status: "ACTIVE"}},
+		Flavors: []*Flavor{{ID: "flavor-1", Name: "nix.2c_4g", VCPUs: 2, RAM: 4096}},
+	}
+}
+
+func TestCloudRequiresToken(t *testing.T) {
+	ts := httptest.NewServer(New(testState()))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/iaas-cloud/api/servers")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", resp.StatusCode)
+	}
+}
+
+func TestCloudRoundTrip(t *testing.T) {
+	client := &http.Client{Transport: New(testState())}
+
+	resp, err := client.Post("http://fake.invalid/api/token", "application/json", strings.NewReader(`{"username": "user", "password": "secret"}`))
+	if err != nil {
+		t.Fatalf("Token request failed: %v", err)
+	}
+	var token gobizfly.Token
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	resp.Body.Close()
+	if err != nil || token.KeystoneToken != fakeCloudToken {
+		t.Fatalf("Expected the fake token, got %+v (%v)", token, err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://fake.invalid/iaas-cloud/api/servers", nil)
+	req.Header.Set(authTokenHeader, token.KeystoneToken)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "web-1") {
+		t.Errorf("Expected the servers in process, got %d: %s", resp.StatusCode, body)
+	}
+	if resp.Request != req {
+		t.Error("Expected the response to point at its request")
+	}
+
+	resp, err = client.Get("http://fake.invalid/iaas-cloud/api/servers/missing")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", resp.StatusCode)
+	}
+}
+
+func TestCloudRecordsRequestsOnlyWhenAsked(t *testing.T) {
+	cloud := New(testState())
+	client := &http.Client{Transport: cloud}
+	get := func() {
+		resp, err := client.Get("http://fake.invalid/iaas-cloud/api/servers")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	get()
+	if requests := cloud.Requests(); len(requests) != 0 {
+		t.Errorf("Expected no requests kept before RecordRequests, got %v", requests)
+	}
+	cloud.RecordRequests()
+	get()
+	if requests := cloud.Requests(); len(requests) != 1 || requests[0] != "GET /iaas-cloud/api/servers" {
+		t.Errorf("Expected the request after RecordRequests, got %v", requests)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"strings"
 
 	"github.com/bizflycloud/gobizfly"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -17,8 +16,15 @@ func RegisterKMSTools(s *server.MCPServer, client *gobizfly.Client) {
 	// List KMS certificates tool
 	listCertificatesTool := mcp.NewTool("bizflycloud_list_kms_certificates",
 		mcp.WithDescription("List all Bizfly Cloud KMS certificates"),
+		withCommonOptions(),
+		withListOptions(),
 	)
 	s.AddTool(listCertificatesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
+		query, err := parseListQuery(request.Params.Arguments)
+		if err != nil {
+			return errorResult(ctx, "Failed to list KMS certificates", err), nil
+		}
 		defer func() {
 			if r := recover(); r != nil {
 				log.Printf("[PANIC] Recovered from panic in listCertificatesTool: %v", r)
@@ -27,66 +33,71 @@ func RegisterKMSTools(s *server.MCPServer, client *gobizfly.Client) {
 
 		log.Printf("[DEBUG] KMS Certificates List tool called")
 		
-		if client == nil || client.KMS == nil || client.KMS.Certificates() == nil {
+		if services == nil || services.KMS == nil {
 			log.Printf("[ERROR] KMS service is not available")
-			return mcp.NewToolResultText("Available KMS certificates:\n\n(KMS service is not available)"), nil
+			return listResult(request, "Available KMS certificates:\n\n(KMS service is not available)", "certificates", []KMSCertificateOutput{}), nil
 		}
 
-		certificates, err := client.KMS.Certificates().List(ctx)
+		certificates, err := services.KMS.List(ctx)
 		if err != nil {
 			log.Printf("[ERROR] Failed to list KMS certificates: %v", err)
-			errStr := strings.ToLower(err.Error())
-			if strings.Contains(errStr, "404") ||
-				strings.Contains(errStr, "not found") ||
-				strings.Contains(errStr, "resource not found") ||
-				strings.Contains(errStr, "<svg") ||
-				strings.Contains(errStr, "<html") {
-				return mcp.NewToolResultText("Available KMS certificates:\n\n(No certificates found or KMS service is not enabled)"), nil
+			if isServiceUnavailable(ctx, err) {
+				return listResult(request, "Available KMS certificates:\n\n(No certificates found or KMS service is not enabled)", "certificates", []KMSCertificateOutput{}), nil
 			}
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list KMS certificates: %v", err)), nil
+			return errorResult(ctx, "Failed to list KMS certificates", err), nil
 		}
+		certificates, page := pageItems(query, certificates, kmsCertificateListFields)
 
 		result := "Available KMS certificates:\n\n"
+		items := []KMSCertificateOutput{}
 		if len(certificates) == 0 {
 			result += "(No certificates found)\n"
 		} else {
 			for _, cert := range certificates {
+				items = append(items, KMSCertificateOutput{ContainerID: cert.ContainerID, Name: cert.Name})
 				result += fmt.Sprintf("Certificate: %s\n", cert.Name)
 				result += fmt.Sprintf("  Container ID: %s\n", cert.ContainerID)
 				result += "\n"
 			}
 		}
-		return mcp.NewToolResultText(result), nil
+		return pageResult(request, result, "certificates", items, page), nil
 	})
 
 	// Get KMS certificate tool
 	getCertificateTool := mcp.NewTool("bizflycloud_get_kms_certificate",
 		mcp.WithDescription("Get details of a Bizfly Cloud KMS certificate"),
+		withCommonOptions(),
 		mcp.WithString("certificate_id",
 			mcp.Required(),
 			mcp.Description("Container ID of the KMS certificate"),
 		),
 	)
 	s.AddTool(getCertificateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		certificateID, ok := request.Params.Arguments["certificate_id"].(string)
 		if !ok {
 			return nil, errors.New("certificate_id must be a string")
 		}
-		cert, err := client.KMS.Certificates().Get(ctx, certificateID)
+		cert, err := services.KMS.Get(ctx, certificateID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get KMS certificate: %v", err)), nil
+			return errorResult(ctx, "Failed to get KMS certificate", err), nil
 		}
 
 		result := fmt.Sprintf("KMS Certificate Details:\n\n")
 		result += fmt.Sprintf("Name: %s\n", cert.Name)
 		result += fmt.Sprintf("Container ID: %s\n", cert.ContainerID)
 		result += fmt.Sprintf("Certificate: %s\n", cert.Certificate)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, KMSCertificateOutput{
+			ContainerID: cert.ContainerID,
+			Name:        cert.Name,
+			Certificate: cert.Certificate,
+		}), nil
 	})
 
 	// Create KMS certificate tool
 	createCertificateTool := mcp.NewTool("bizflycloud_create_kms_certificate",
 		mcp.WithDescription("Create a new Bizfly Cloud KMS certificate"),
+		withCommonOptions(),
 		mcp.WithString("name",
 			mcp.Required(),
 			mcp.Description("Name of the certificate container"),
@@ -115,6 +126,7 @@ func RegisterKMSTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(createCertificateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		name, ok := request.Params.Arguments["name"].(string)
 		if !ok {
 			return nil, errors.New("name must be a string")
@@ -159,34 +171,60 @@ func RegisterKMSTools(s *server.MCPServer, client *gobizfly.Client) {
 			}
 		}
 
-		resp, err := client.KMS.Certificates().Create(ctx, req)
+		resp, err := services.KMS.Create(ctx, req)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create KMS certificate: %v", err)), nil
+			return errorResult(ctx, "Failed to create KMS certificate", err), nil
 		}
 
 		result := fmt.Sprintf("KMS certificate created successfully:\n")
 		result += fmt.Sprintf("  Certificate Href: %s\n", resp.CertificateHref)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, KMSCertificateOutput{
+			Name:            name,
+			CertificateHref: resp.CertificateHref,
+		}), nil
 	})
 
 	// Delete KMS certificate tool
 	deleteCertificateTool := mcp.NewTool("bizflycloud_delete_kms_certificate",
 		mcp.WithDescription("Delete a Bizfly Cloud KMS certificate"),
+		withCommonOptions(),
+		withConfirmation(),
 		mcp.WithString("certificate_id",
 			mcp.Required(),
 			mcp.Description("Container ID of the KMS certificate to delete"),
 		),
 	)
 	s.AddTool(deleteCertificateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		certificateID, ok := request.Params.Arguments["certificate_id"].(string)
 		if !ok {
 			return nil, errors.New("certificate_id must be a string")
 		}
-		err := client.KMS.Certificates().Delete(ctx, certificateID)
+		err := services.KMS.Delete(ctx, certificateID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete KMS certificate: %v", err)), nil
+			return errorResult(ctx, "Failed to delete KMS certificate", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("KMS certificate %s deleted successfully", certificateID)), nil
+		return actionResult(request, fmt.Sprintf("KMS certificate %s deleted successfully", certificateID), ActionOutput{Action: "delete", ResourceType: "kms_certificate", ResourceID: certificateID}), nil
 	})
 }
 
+// previewKMSCertificateDeletion describes the certificate container
+func previewKMSCertificateDeletion(ctx context.Context, services *Services, args map[string]interface{}) (string, error) {
+	certificateID, err := stringArg(args, "certificate_id")
+	if err != nil {
+		return "", err
+	}
+	cert, err := services.KMS.Get(ctx, certificateID)
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("Certificate: %s\n", cert.Name)
+	result += fmt.Sprintf("  Container ID: %s\n", cert.ContainerID)
+	return result, nil
+}
+
+// kmsCertificateListFields are the fields list_kms_certificates filters and sorts on
+func kmsCertificateListFields(cert *gobizfly.KMSCertificate) listFields {
+	return listFields{Name: cert.Name}
+}
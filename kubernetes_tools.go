@@ -6,11 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"strings"
+	"time"
 
 	"github.com/bizflycloud/gobizfly"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
 )
 
 // RegisterKubernetesTools registers all Kubernetes-related tools with the MCP server
@@ -18,26 +19,27 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 	// List clusters tool
 	listClustersTool := mcp.NewTool("bizflycloud_list_kubernetes_clusters",
 		mcp.WithDescription("List all Bizfly Cloud Kubernetes clusters"),
+		withCommonOptions(),
+		withListOptions(),
 	)
 	s.AddTool(listClustersTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
+		query, err := parseListQuery(request.Params.Arguments)
+		if err != nil {
+			return errorResult(ctx, "Failed to list clusters", err), nil
+		}
 		log.Printf("[DEBUG] Kubernetes List tool called")
 		log.Printf("[DEBUG] Context: %v", ctx)
 		log.Printf("[DEBUG] Calling KubernetesEngine.List with options: %+v", &gobizfly.ListOptions{})
 		
-		// Check if client has token
-		if client == nil {
+		if services == nil {
 			return mcp.NewToolResultError("Client is nil"), nil
 		}
 		
-		clusters, err := client.KubernetesEngine.List(ctx, &gobizfly.ListOptions{})
+		clusters, err := services.Kubernetes.List(ctx, &gobizfly.ListOptions{})
 		if err != nil {
 			log.Printf("[ERROR] Failed to list clusters: %v", err)
-			// Check if it's a 404 error (API endpoint not found)
-			errStr := err.Error()
-			if strings.Contains(errStr, "404") || strings.Contains(errStr, "<svg") || strings.Contains(errStr, "Resource not found") {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to list clusters: Kubernetes Engine service may not be enabled or the API endpoint is not available.\n\nError: %v\n\nPlease check:\n- Kubernetes Engine service is enabled on your account\n- Your credentials have permission to access Kubernetes Engine\n- The API endpoint is correct", err)), nil
-			}
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list clusters: %v", err)), nil
+			return errorResult(ctx, "Failed to list clusters", err), nil
 		}
 
 		// Debug: Log raw response
@@ -47,12 +49,14 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 
 		if len(clusters) == 0 {
 			log.Printf("[DEBUG] No clusters found in response")
-			return mcp.NewToolResultText("Available Kubernetes clusters:\n\n(No clusters found)\n\nNote: If you have clusters but they're not listed, please check:\n- Your credentials are correct\n- The clusters are in the correct project/region\n- Your account has permission to list Kubernetes clusters"), nil
+			return listResult(request, "Available Kubernetes clusters:\n\n(No clusters found)\n\nNote: If you have clusters but they're not listed, please check:\n- Your credentials are correct\n- The clusters are in the correct project/region\n- Your account has permission to list Kubernetes clusters", "clusters", []ClusterOutput{}), nil
 		}
 
 		log.Printf("[DEBUG] Processing %d clusters", len(clusters))
+		clusters, page := pageItems(query, clusters, clusterListFields)
 
 		result := "Available Kubernetes clusters:\n\n"
+		items := []ClusterOutput{}
 		for i, c := range clusters {
 			log.Printf("[DEBUG] Processing cluster %d: Name=%s, UID=%s, Status=%s", i+1, c.Name, c.UID, c.ClusterStatus)
 			// Display basic info from List response first
@@ -78,14 +82,16 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 			// Try to get full cluster details for worker pools info
 			// If Get fails, we still have the basic info from List
 			log.Printf("[DEBUG] Fetching full details for cluster %s (UID: %s)", c.Name, c.UID)
-			cluster, err := client.KubernetesEngine.Get(ctx, c.UID)
+			cluster, err := services.Kubernetes.Get(ctx, c.UID)
 			if err != nil {
 				// Log the error but continue with other clusters
 				log.Printf("[WARN] Failed to get full details for cluster %s: %v", c.UID, err)
 				result += fmt.Sprintf("  Warning: Could not fetch full details: %v\n", err)
 				result += "\n"
+				items = append(items, newClusterOutput(c))
 				continue
 			}
+			items = append(items, newExtendedClusterOutput(&cluster.ExtendedCluster))
 			log.Printf("[DEBUG] Successfully fetched full details for cluster %s, worker pools: %d", c.UID, len(cluster.WorkerPools))
 
 			if len(cluster.WorkerPools) > 0 {
@@ -103,12 +109,15 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 			}
 			result += "\n"
 		}
-		return mcp.NewToolResultText(result), nil
+		return pageResult(request, result, "clusters", items, page), nil
 	})
 
 	// Create cluster tool
 	createClusterTool := mcp.NewTool("bizflycloud_create_kubernetes_cluster",
 		mcp.WithDescription("Create a new Bizfly Cloud Kubernetes cluster"),
+		withCommonOptions(),
+		withDryRun(),
+		withWait(),
 		mcp.WithString("name",
 			mcp.Required(),
 			mcp.Description("Name of the cluster"),
@@ -127,6 +136,7 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(createClusterTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		name, ok := request.Params.Arguments["name"].(string)
 		if !ok {
 			return nil, errors.New("name must be a string")
@@ -145,9 +155,9 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 		}
 
 		// Get the flavor ID from the name
-		flavors, err := client.CloudServer.Flavors().List(ctx)
+		flavors, err := catalogLookup(ctx, catalogFromContext(ctx), clientFromContext(ctx, client), catalogFlavors, services.Servers.ListFlavors)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get flavors: %v", err)), nil
+			return errorResult(ctx, "Failed to get flavors", err), nil
 		}
 
 		var flavorID string
@@ -161,7 +171,7 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 			return mcp.NewToolResultError(fmt.Sprintf("Flavor '%s' not found", workerFlavor)), nil
 		}
 
-		cluster, err := client.KubernetesEngine.Create(ctx, &gobizfly.ClusterCreateRequest{
+		createReq := &gobizfly.ClusterCreateRequest{
 			Name:    name,
 			Version: version,
 			WorkerPools: []gobizfly.WorkerPool{
@@ -177,46 +187,89 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 					MaxSize:           int(workerCount),
 					NetworkPlan:       "free_plan",
 					BillingPlan:       "on_demand",
-					AvailabilityZone:  "HN1",
+					AvailabilityZone:  availabilityZoneFromContext(ctx, "HN1"),
 				},
 			},
-		})
+		}
+		timeout, err := waitTimeout(request)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create cluster: %v", err)), nil
+			return errorResult(ctx, "Failed to create cluster", err), nil
+		}
+		if isDryRun(request) {
+			return dryRunResult(request, "bizflycloud_create_kubernetes_cluster", "", createReq, validateClusterCreate(createReq)), nil
 		}
 
+		cluster, err := services.Kubernetes.Create(ctx, createReq)
+		if err != nil {
+			return errorResult(ctx, "Failed to create cluster", err), nil
+		}
+		recordTask(ctx, services, TaskSpec{Action: "create", ResourceType: "cluster", ResourceID: cluster.UID, ResourceName: cluster.Name, Done: []string{"PROVISIONED"}})
+
 		result := fmt.Sprintf("Cluster created successfully:\n")
 		result += fmt.Sprintf("  Name: %s\n", cluster.Name)
 		result += fmt.Sprintf("  ID: %s\n", cluster.UID)
 		result += fmt.Sprintf("  Status: %s\n", cluster.ClusterStatus)
 		result += fmt.Sprintf("  Version: %s\n", cluster.Version)
 		result += fmt.Sprintf("  Node Pools Count: %d\n", cluster.WorkerPoolsCount)
-		return mcp.NewToolResultText(result), nil
+		output := ClusterCreationOutput{ClusterOutput: newExtendedClusterOutput(cluster)}
+		if timeout == 0 {
+			return formatResult(request, result, output), nil
+		}
+
+		current, wait, err := waitForCluster(ctx, services, request, cluster, timeout)
+		if err != nil {
+			return errorResult(ctx, "Cluster was created but waiting for it failed", err), nil
+		}
+		output.Wait = &wait
+		if current != nil {
+			output.ClusterOutput = newExtendedClusterOutput(&current.ExtendedCluster)
+		}
+		if wait.Ready {
+			output.APIEndpoint = clusterAPIEndpoint(ctx, services, cluster.UID)
+			addPoolNodes(ctx, services, cluster.UID, output.WorkerPools)
+			if output.APIEndpoint != "" {
+				result += fmt.Sprintf("  API Endpoint: %s\n", output.APIEndpoint)
+			}
+			for _, pool := range output.WorkerPools {
+				for _, node := range pool.Nodes {
+					result += fmt.Sprintf("  Node %s (%s): %v\n", node.Name, pool.Name, node.IPAddresses)
+				}
+			}
+		}
+		result += describeWait("cluster", wait)
+		final := formatResult(request, result, output)
+		final.IsError = wait.Failed
+		return final, nil
 	})
 
 	// Delete cluster tool
 	deleteClusterTool := mcp.NewTool("bizflycloud_delete_kubernetes_cluster",
 		mcp.WithDescription("Delete a Bizfly Cloud Kubernetes cluster"),
+		withCommonOptions(),
+		withConfirmation(),
 		mcp.WithString("cluster_id",
 			mcp.Required(),
 			mcp.Description("ID of the cluster to delete"),
 		),
 	)
 	s.AddTool(deleteClusterTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		clusterID, ok := request.Params.Arguments["cluster_id"].(string)
 		if !ok {
 			return nil, errors.New("cluster_id must be a string")
 		}
-		err := client.KubernetesEngine.Delete(ctx, clusterID)
+		err := services.Kubernetes.Delete(ctx, clusterID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete cluster: %v", err)), nil
+			return errorResult(ctx, "Failed to delete cluster", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Cluster %s deleted successfully", clusterID)), nil
+		recordTask(ctx, services, TaskSpec{Action: "delete", ResourceType: "cluster", ResourceID: clusterID, Done: []string{statusDeleted}})
+		return actionResult(request, fmt.Sprintf("Cluster %s deleted successfully", clusterID), ActionOutput{Action: "delete", ResourceType: "kubernetes_cluster", ResourceID: clusterID}), nil
 	})
 
 	// List cluster nodes tool
 	listClusterNodesTool := mcp.NewTool("bizflycloud_list_kubernetes_nodes",
 		mcp.WithDescription("List nodes in a Bizfly Cloud Kubernetes cluster"),
+		withCommonOptions(),
 		mcp.WithString("cluster_id",
 			mcp.Required(),
 			mcp.Description("ID of the cluster"),
@@ -227,6 +280,7 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(listClusterNodesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		clusterID, ok := request.Params.Arguments["cluster_id"].(string)
 		if !ok {
 			return nil, errors.New("cluster_id must be a string")
@@ -237,9 +291,9 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 		}
 
 		// Get cluster details to find the pool
-		cluster, err := client.KubernetesEngine.Get(ctx, clusterID)
+		cluster, err := services.Kubernetes.Get(ctx, clusterID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get cluster: %v", err)), nil
+			return errorResult(ctx, "Failed to get cluster", err), nil
 		}
 
 		// Find the pool
@@ -278,36 +332,41 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += "\n"
 
 		// Get nodes in the pool
-		nodes, err := client.KubernetesEngine.GetClusterWorkerPool(ctx, clusterID, poolID)
+		nodes, err := services.Kubernetes.GetClusterWorkerPool(ctx, clusterID, poolID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list nodes: %v", err)), nil
+			return errorResult(ctx, "Failed to list nodes", err), nil
 		}
 
+		output := newWorkerPoolOutput(pool)
+		output.Nodes = []PoolNodeOutput{}
 		result += fmt.Sprintf("Nodes in pool %s:\n\n", pool.Name)
 		for _, node := range nodes.Nodes {
+			output.Nodes = append(output.Nodes, newPoolNodeOutput(node))
 			result += fmt.Sprintf("Node: %s\n", node.Name)
 			result += fmt.Sprintf("  Status: %s\n", node.Status)
 			result += "\n"
 		}
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, output), nil
 	})
 
 	// Get cluster tool
 	getClusterTool := mcp.NewTool("bizflycloud_get_kubernetes_cluster",
 		mcp.WithDescription("Get details of a Bizfly Cloud Kubernetes cluster"),
+		withCommonOptions(),
 		mcp.WithString("cluster_id",
 			mcp.Required(),
 			mcp.Description("ID of the cluster to get details for"),
 		),
 	)
 	s.AddTool(getClusterTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		clusterID, ok := request.Params.Arguments["cluster_id"].(string)
 		if !ok {
 			return nil, errors.New("cluster_id must be a string")
 		}
-		cluster, err := client.KubernetesEngine.Get(ctx, clusterID)
+		cluster, err := services.Kubernetes.Get(ctx, clusterID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get cluster: %v", err)), nil
+			return errorResult(ctx, "Failed to get cluster", err), nil
 		}
 
 		result := fmt.Sprintf("Cluster Details:\n\n")
@@ -331,12 +390,14 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 			result += fmt.Sprintf("    Auto Scaling: %v\n", pool.EnableAutoScaling)
 			result += "\n"
 		}
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newExtendedClusterOutput(&cluster.ExtendedCluster)), nil
 	})
 
 	// Update pool tool
 	updatePoolTool := mcp.NewTool("bizflycloud_update_kubernetes_pool",
 		mcp.WithDescription("Update a worker pool in a Bizfly Cloud Kubernetes cluster"),
+		withCommonOptions(),
+		withDryRun(),
 		mcp.WithString("cluster_id",
 			mcp.Required(),
 			mcp.Description("ID of the cluster"),
@@ -359,6 +420,7 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(updatePoolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		clusterID, ok := request.Params.Arguments["cluster_id"].(string)
 		if !ok {
 			return nil, errors.New("cluster_id must be a string")
@@ -381,17 +443,25 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 		if maxSize, ok := request.Params.Arguments["max_size"].(float64); ok {
 			req.MaxSize = int(maxSize)
 		}
+		if isDryRun(request) {
+			target := fmt.Sprintf("pool %s in cluster %s", poolID, clusterID)
+			return dryRunResult(request, "bizflycloud_update_kubernetes_pool", target, req, validatePoolUpdate(req)), nil
+		}
 
-		err := client.KubernetesEngine.UpdateClusterWorkerPool(ctx, clusterID, poolID, req)
+		err := services.Kubernetes.UpdateClusterWorkerPool(ctx, clusterID, poolID, req)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to update pool: %v", err)), nil
+			return errorResult(ctx, "Failed to update pool", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Pool %s in cluster %s updated successfully", poolID, clusterID)), nil
+		return actionResult(request, fmt.Sprintf("Pool %s in cluster %s updated successfully", poolID, clusterID), ActionOutput{
+			Action: "update", ResourceType: "kubernetes_pool", ResourceID: poolID,
+			Details: map[string]string{"cluster_id": clusterID},
+		}), nil
 	})
 
 	// Resize pool tool (uses update with desired_size)
 	resizePoolTool := mcp.NewTool("bizflycloud_resize_kubernetes_pool",
 		mcp.WithDescription("Resize a worker pool in a Bizfly Cloud Kubernetes cluster"),
+		withCommonOptions(),
 		mcp.WithString("cluster_id",
 			mcp.Required(),
 			mcp.Description("ID of the cluster"),
@@ -406,6 +476,7 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(resizePoolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		clusterID, ok := request.Params.Arguments["cluster_id"].(string)
 		if !ok {
 			return nil, errors.New("cluster_id must be a string")
@@ -422,16 +493,21 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 		req := &gobizfly.UpdateWorkerPoolRequest{
 			DesiredSize: int(desiredSize),
 		}
-		err := client.KubernetesEngine.UpdateClusterWorkerPool(ctx, clusterID, poolID, req)
+		err := services.Kubernetes.UpdateClusterWorkerPool(ctx, clusterID, poolID, req)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to resize pool: %v", err)), nil
+			return errorResult(ctx, "Failed to resize pool", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Pool %s in cluster %s resized to %d nodes successfully", poolID, clusterID, int(desiredSize))), nil
+		return actionResult(request, fmt.Sprintf("Pool %s in cluster %s resized to %d nodes successfully", poolID, clusterID, int(desiredSize)), ActionOutput{
+			Action: "resize", ResourceType: "kubernetes_pool", ResourceID: poolID,
+			Details: map[string]string{"cluster_id": clusterID, "desired_size": fmt.Sprint(int(desiredSize))},
+		}), nil
 	})
 
 	// Delete pool tool
 	deletePoolTool := mcp.NewTool("bizflycloud_delete_kubernetes_pool",
 		mcp.WithDescription("Delete a worker pool from a Bizfly Cloud Kubernetes cluster"),
+		withCommonOptions(),
+		withConfirmation(),
 		mcp.WithString("cluster_id",
 			mcp.Required(),
 			mcp.Description("ID of the cluster"),
@@ -442,6 +518,7 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(deletePoolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		clusterID, ok := request.Params.Arguments["cluster_id"].(string)
 		if !ok {
 			return nil, errors.New("cluster_id must be a string")
@@ -450,10 +527,154 @@ func RegisterKubernetesTools(s *server.MCPServer, client *gobizfly.Client) {
 		if !ok {
 			return nil, errors.New("pool_id must be a string")
 		}
-		err := client.KubernetesEngine.DeleteClusterWorkerPool(ctx, clusterID, poolID)
+		err := services.Kubernetes.DeleteClusterWorkerPool(ctx, clusterID, poolID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete pool: %v", err)), nil
+			return errorResult(ctx, "Failed to delete pool", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Pool %s deleted from cluster %s successfully", poolID, clusterID)), nil
+		return actionResult(request, fmt.Sprintf("Pool %s deleted from cluster %s successfully", poolID, clusterID), ActionOutput{
+			Action: "delete", ResourceType: "kubernetes_pool", ResourceID: poolID,
+			Details: map[string]string{"cluster_id": clusterID},
+		}), nil
 	})
-}
\ No newline at end of file
+}
+
+// previewKubernetesClusterDeletion describes the cluster and its worker pools
+func previewKubernetesClusterDeletion(ctx context.Context, services *Services, args map[string]interface{}) (string, error) {
+	clusterID, err := stringArg(args, "cluster_id")
+	if err != nil {
+		return "", err
+	}
+	cluster, err := services.Kubernetes.Get(ctx, clusterID)
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("Cluster: %s\n", cluster.Name)
+	result += fmt.Sprintf("  ID: %s\n", cluster.UID)
+	result += fmt.Sprintf("  Status: %s\n", cluster.ClusterStatus)
+	result += fmt.Sprintf("  Version: %s\n", cluster.Version.K8SVersion)
+	for _, pool := range cluster.WorkerPools {
+		result += fmt.Sprintf("  Worker Pool: %s (%d nodes, %s)\n", pool.Name, pool.DesiredSize, pool.Flavor)
+	}
+	result += fmt.Sprintf("  Created At: %s\n", cluster.CreatedAt)
+	return result, nil
+}
+
+// previewKubernetesPoolDeletion describes the worker pool and its nodes
+func previewKubernetesPoolDeletion(ctx context.Context, services *Services, args map[string]interface{}) (string, error) {
+	clusterID, err := stringArg(args, "cluster_id")
+	if err != nil {
+		return "", err
+	}
+	poolID, err := stringArg(args, "pool_id")
+	if err != nil {
+		return "", err
+	}
+	pool, err := services.Kubernetes.GetClusterWorkerPool(ctx, clusterID, poolID)
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("Worker Pool: %s\n", pool.Name)
+	result += fmt.Sprintf("  ID: %s\n", pool.UID)
+	result += fmt.Sprintf("  Cluster ID: %s\n", clusterID)
+	result += fmt.Sprintf("  Flavor: %s\n", pool.Flavor)
+	for _, node := range pool.Nodes {
+		result += fmt.Sprintf("  Node: %s (%s)\n", node.Name, node.Status)
+	}
+	return result, nil
+}
+
+// waitForCluster polls the cluster until it is PROVISIONED or has failed
+func waitForCluster(ctx context.Context, services *Services, request mcp.CallToolRequest, cluster *gobizfly.ExtendedCluster, timeout time.Duration) (*gobizfly.FullCluster, WaitOutput, error) {
+	var current *gobizfly.FullCluster
+	poll := statusPoll{
+		Kind:     "cluster",
+		Name:     cluster.Name,
+		Ready:    []string{"PROVISIONED"},
+		Failed:   []string{"FAILED", "ERROR"},
+		Timeout:  timeout,
+		Interval: waitPollInterval,
+		Get: func(ctx context.Context) (string, error) {
+			full, err := services.Kubernetes.Get(ctx, cluster.UID)
+			if err != nil {
+				return "", err
+			}
+			current = full
+			return full.ProvisionStatus, nil
+		},
+	}
+	wait, err := poll.Run(ctx, newProgressReporter(ctx, request))
+	return current, wait, err
+}
+
+// clusterAPIEndpoint returns the API server URL from the cluster's kubeconfig,
+// or "" if it can't be read
+func clusterAPIEndpoint(ctx context.Context, services *Services, clusterID string) string {
+	kubeconfig, err := services.Kubernetes.GetKubeConfig(ctx, clusterID, nil)
+	if err != nil {
+		log.Printf("[WARN] Failed to get the kubeconfig of cluster %s: %v", clusterID, err)
+		return ""
+	}
+	var config struct {
+		Clusters []struct {
+			Cluster struct {
+				Server string `yaml:"server"`
+			} `yaml:"cluster"`
+		} `yaml:"clusters"`
+	}
+	if err := yaml.Unmarshal([]byte(kubeconfig), &config); err != nil || len(config.Clusters) == 0 {
+		return ""
+	}
+	return config.Clusters[0].Cluster.Server
+}
+
+// addPoolNodes fills in the nodes of the worker pools, skipping pools whose nodes can't be listed
+func addPoolNodes(ctx context.Context, services *Services, clusterID string, pools []WorkerPoolOutput) {
+	for i := range pools {
+		pool, err := services.Kubernetes.GetClusterWorkerPool(ctx, clusterID, pools[i].ID)
+		if err != nil {
+			log.Printf("[WARN] Failed to get the nodes of worker pool %s: %v", pools[i].ID, err)
+			continue
+		}
+		pools[i].Nodes = []PoolNodeOutput{}
+		for _, node := range pool.Nodes {
+			pools[i].Nodes = append(pools[i].Nodes, newPoolNodeOutput(node))
+		}
+	}
+}
+
+// validateClusterCreate checks a cluster create request before it is sent
+func validateClusterCreate(req *gobizfly.ClusterCreateRequest) *requestValidation {
+	v := &requestValidation{}
+	v.check(req.Name != "", "name is set")
+	v.check(req.Version != "", "version is set")
+	v.check(len(req.WorkerPools) > 0, "at least one worker pool is defined")
+	for _, pool := range req.WorkerPools {
+		v.check(pool.Flavor != "", fmt.Sprintf("pool %s flavor is resolved", pool.Name))
+		v.check(pool.DesiredSize > 0, fmt.Sprintf("pool %s has at least one worker", pool.Name))
+		v.check(pool.MinSize <= pool.DesiredSize && pool.DesiredSize <= pool.MaxSize,
+			fmt.Sprintf("pool %s desired size is between min and max size", pool.Name))
+		v.check(pool.AvailabilityZone != "", fmt.Sprintf("pool %s availability zone is set", pool.Name))
+	}
+	return v
+}
+
+// validatePoolUpdate checks a worker pool update request before it is sent
+func validatePoolUpdate(req *gobizfly.UpdateWorkerPoolRequest) *requestValidation {
+	v := &requestValidation{}
+	v.check(req.DesiredSize >= 0 && req.MinSize >= 0 && req.MaxSize >= 0, "sizes are not negative")
+	if req.EnableAutoScaling {
+		v.check(req.MinSize <= req.MaxSize, "min size is not greater than max size")
+		if req.DesiredSize > 0 {
+			v.check(req.MinSize <= req.DesiredSize && req.DesiredSize <= req.MaxSize,
+				"desired size is between min and max size")
+		}
+	}
+	return v
+}
+
+// clusterListFields are the fields list_kubernetes_clusters filters and sorts on
+func clusterListFields(cluster *gobizfly.Cluster) listFields {
+	return listFields{Name: cluster.Name, Status: cluster.ClusterStatus, CreatedAt: cluster.CreatedAt}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// List arguments shared by the list tools
+const (
+	limitArg        = "limit"
+	cursorArg       = "cursor"
+	sortByArg       = "sort_by"
+	nameContainsArg = "name_contains"
+	statusArg       = "status"
+	createdAfterArg = "created_after"
+)
+
+// Page sizes of the list tools
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// Sort keys accepted by sort_by; a leading "-" sorts in descending order
+var listSortKeys = []string{"name", "status", "created_at"}
+
+// createdAtLayouts are the timestamp formats the Bizfly Cloud APIs return
+var createdAtLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// listFields are the fields of a resource that the list arguments filter and sort on
+type listFields struct {
+	Name      string
+	Status    string
+	CreatedAt string
+}
+
+// listQuery is the filtering, sorting and paging a list tool call asked for
+type listQuery struct {
+	limit        int
+	offset       int
+	sortBy       string
+	descending   bool
+	nameContains string
+	status       string
+	createdAfter time.Time
+}
+
+// listPage describes the page of results a list tool returned
+type listPage struct {
+	Offset     int
+	Total      int
+	NextCursor string
+}
+
+// listCursor is the decoded form of the opaque cursor returned as next_cursor
+type listCursor struct {
+	Offset int `json:"offset"`
+}
+
+// withListOptions adds the paging, sorting and filtering arguments to a list tool
+func withListOptions() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		withPagingOptions()(t)
+		mcp.WithString(sortByArg,
+			mcp.Description("Sort by name, status or created_at; prefix with - for descending order (default: API order)"),
+		)(t)
+		mcp.WithString(nameContainsArg,
+			mcp.Description("Only return items whose name contains this text (case-insensitive)"),
+		)(t)
+		mcp.WithString(statusArg,
+			mcp.Description("Only return items with this status (case-insensitive)"),
+		)(t)
+		mcp.WithString(createdAfterArg,
+			mcp.Description("Only return items created after this time (RFC 3339 or YYYY-MM-DD)"),
+		)(t)
+	}
+}
+
+// withPagingOptions adds the paging arguments to a list tool
+func withPagingOptions() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithNumber(limitArg,
+			mcp.Description(fmt.Sprintf("Maximum number of items to return (default: %d, max: %d)", defaultListLimit, maxListLimit)),
+		)(t)
+		mcp.WithString(cursorArg,
+			mcp.Description("next_cursor from a previous call with the same filters, to get the next page"),
+		)(t)
+	}
+}
+
+// parseListQuery reads the list arguments of a tool call
+func parseListQuery(args map[string]interface{}) (*listQuery, error) {
+	q, err := readListQuery(args)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	return q, nil
+}
+
+// readListQuery reads and checks the list arguments
+func readListQuery(args map[string]interface{}) (*listQuery, error) {
+	q := &listQuery{limit: defaultListLimit}
+
+	if value, ok := args[limitArg]; ok {
+		limit, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a number", limitArg)
+		}
+		if limit < 1 || limit > maxListLimit {
+			return nil, fmt.Errorf("%s must be between 1 and %d", limitArg, maxListLimit)
+		}
+		q.limit = int(limit)
+	}
+
+	if cursor, _ := args[cursorArg].(string); cursor != "" {
+		offset, err := decodeListCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		q.offset = offset
+	}
+
+	if sortBy, _ := args[sortByArg].(string); sortBy != "" {
+		q.descending = strings.HasPrefix(sortBy, "-")
+		q.sortBy = strings.TrimPrefix(sortBy, "-")
+		if !slices.Contains(listSortKeys, q.sortBy) {
+			return nil, fmt.Errorf("cannot sort by %q (available: %s)", q.sortBy, strings.Join(listSortKeys, ", "))
+		}
+	}
+
+	nameContains, _ := args[nameContainsArg].(string)
+	q.nameContains = strings.ToLower(nameContains)
+	q.status, _ = args[statusArg].(string)
+
+	if createdAfter, _ := args[createdAfterArg].(string); createdAfter != "" {
+		t, ok := parseCreatedAt(createdAfter)
+		if !ok {
+			return nil, fmt.Errorf("%s must be an RFC 3339 time or a YYYY-MM-DD date, got %q", createdAfterArg, createdAfter)
+		}
+		q.createdAfter = t
+	}
+	return q, nil
+}
+
+// matches reports whether an item passes the query's filters
+func (q *listQuery) matches(f listFields) bool {
+	if q.nameContains != "" && !strings.Contains(strings.ToLower(f.Name), q.nameContains) {
+		return false
+	}
+	if q.status != "" && !strings.EqualFold(f.Status, q.status) {
+		return false
+	}
+	if !q.createdAfter.IsZero() {
+		created, ok := parseCreatedAt(f.CreatedAt)
+		if !ok || !created.After(q.createdAfter) {
+			return false
+		}
+	}
+	return true
+}
+
+// less orders two items by the query's sort key
+func (q *listQuery) less(a, b listFields) bool {
+	switch q.sortBy {
+	case "status":
+		return strings.ToLower(a.Status) < strings.ToLower(b.Status)
+	case "created_at":
+		at, aok := parseCreatedAt(a.CreatedAt)
+		bt, bok := parseCreatedAt(b.CreatedAt)
+		if aok && bok {
+			return at.Before(bt)
+		}
+		return a.CreatedAt < b.CreatedAt
+	default:
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	}
+}
+
+// pageItems filters, sorts and pages the items a list tool fetched
+func pageItems[T any](q *listQuery, items []T, fields func(T) listFields) ([]T, listPage) {
+	filtered := make([]T, 0, len(items))
+	for _, item := range items {
+		if q.matches(fields(item)) {
+			filtered = append(filtered, item)
+		}
+	}
+	if q.sortBy != "" {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			if q.descending {
+				return q.less(fields(filtered[j]), fields(filtered[i]))
+			}
+			return q.less(fields(filtered[i]), fields(filtered[j]))
+		})
+	}
+
+	page := listPage{Offset: q.offset, Total: len(filtered)}
+	if q.offset >= len(filtered) {
+		return []T{}, page
+	}
+	end := q.offset + q.limit
+	if end < len(filtered) {
+		page.NextCursor = encodeListCursor(end)
+	} else {
+		end = len(filtered)
+	}
+	return filtered[q.offset:end], page
+}
+
+// pageResult returns a page of a list as text with a paging footer, or as
+// {"<key>": items, "count": n, "total": n, "next_cursor": "..."} JSON
+func pageResult(request mcp.CallToolRequest, text, key string, items interface{}, page listPage) *mcp.CallToolResult {
+	count := sliceLen(items)
+	if outputFormat(request) != formatJSON {
+		if page.NextCursor != "" {
+			text += fmt.Sprintf("Showing %d-%d of %d. For the next page call again with %s: %s\n",
+				page.Offset+1, page.Offset+count, page.Total, cursorArg, page.NextCursor)
+		}
+		return mcp.NewToolResultText(text)
+	}
+	return jsonResult(map[string]interface{}{
+		key:           items,
+		"count":       count,
+		"total":       page.Total,
+		"next_cursor": page.NextCursor,
+	})
+}
+
+// encodeListCursor returns the opaque cursor of the page starting at offset
+func encodeListCursor(offset int) string {
+	data, _ := json.Marshal(listCursor{Offset: offset})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeListCursor returns the offset an opaque cursor points at
+func decodeListCursor(cursor string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", cursorArg, cursor)
+	}
+	var decoded listCursor
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Offset < 0 {
+		return 0, fmt.Errorf("invalid %s %q", cursorArg, cursor)
+	}
+	return decoded.Offset, nil
+}
+
+// parseCreatedAt parses a creation timestamp in any of the formats the APIs use
+func parseCreatedAt(value string) (time.Time, bool) {
+	for _, layout := range createdAtLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
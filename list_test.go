@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// testListItem is a resource as the list layer sees it
+type testListItem struct {
+	name, status, createdAt string
+}
+
+func testListItemFields(item testListItem) listFields {
+	return listFields{Name: item.name, Status: item.status, CreatedAt: item.createdAt}
+}
+
+var testListItems = []testListItem{
+	{"web-1", "ACTIVE", "2026-03-01T10:00:00Z"},
+	{"db-1", "SHUTOFF", "2026-01-15T08:30:00Z"},
+	{"web-2", "active", "2026-02-10 12:00:00"},
+	{"cache", "ERROR", "2025-12-24T00:00:00.123456"},
+}
+
+// itemNames returns the names of a page of items in order
+func itemNames(items []testListItem) []string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.name)
+	}
+	return names
+}
+
+// listPageOf runs the list layer over testListItems with the given arguments
+func listPageOf(t *testing.T, args map[string]interface{}) ([]string, listPage) {
+	t.Helper()
+	query, err := parseListQuery(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	items, page := pageItems(query, testListItems, testListItemFields)
+	return itemNames(items), page
+}
+
+func TestPageItems(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		expected []string
+	}{
+		{"keeps the API order by default", map[string]interface{}{}, []string{"web-1", "db-1", "web-2", "cache"}},
+		{"filters by name", map[string]interface{}{nameContainsArg: "WEB"}, []string{"web-1", "web-2"}},
+		{"filters by status", map[string]interface{}{statusArg: "active"}, []string{"web-1", "web-2"}},
+		{"filters by creation time", map[string]interface{}{createdAfterArg: "2026-01-31"}, []string{"web-1", "web-2"}},
+		{"sorts by name", map[string]interface{}{sortByArg: "name"}, []string{"cache", "db-1", "web-1", "web-2"}},
+		{"sorts by creation time descending", map[string]interface{}{sortByArg: "-created_at"}, []string{"web-1", "web-2", "db-1", "cache"}},
+		{"combines filters and sorting", map[string]interface{}{nameContainsArg: "web", sortByArg: "-name"}, []string{"web-2", "web-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, page := listPageOf(t, tt.args)
+			if len(names) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, names)
+			}
+			for i := range names {
+				if names[i] != tt.expected[i] {
+					t.Fatalf("Expected %v, got %v", tt.expected, names)
+				}
+			}
+			if page.Total != len(tt.expected) || page.NextCursor != "" {
+				t.Errorf("Expected a single page of %d, got %+v", len(tt.expected), page)
+			}
+		})
+	}
+}
+
+func TestPageItemsCursor(t *testing.T) {
+	args := map[string]interface{}{limitArg: 3.0, sortByArg: "name"}
+	first, page := listPageOf(t, args)
+	if len(first) != 3 || page.Total != 4 || page.NextCursor == "" {
+		t.Fatalf("Expected a first page of 3 of 4 with a cursor, got %v %+v", first, page)
+	}
+
+	args[cursorArg] = page.NextCursor
+	second, page := listPageOf(t, args)
+	if len(second) != 1 || second[0] != "web-2" || page.NextCursor != "" {
+		t.Errorf("Expected the last item and no cursor, got %v %+v", second, page)
+	}
+}
+
+func TestParseListQueryErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"limit too small", map[string]interface{}{limitArg: 0.0}},
+		{"limit too large", map[string]interface{}{limitArg: float64(maxListLimit + 1)}},
+		{"limit not a number", map[string]interface{}{limitArg: "ten"}},
+		{"unknown sort key", map[string]interface{}{sortByArg: "size"}},
+		{"invalid cursor", map[string]interface{}{cursorArg: "not a cursor"}},
+		{"invalid created_after", map[string]interface{}{createdAfterArg: "yesterday"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseListQuery(tt.args); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestPageResult(t *testing.T) {
+	page := listPage{Offset: 0, Total: 4, NextCursor: encodeListCursor(2)}
+
+	text := pageResult(createTestMCPRequest("bizflycloud_list_servers", map[string]interface{}{}), "Available servers:\n\n", "servers", []ServerOutput{{}, {}}, page)
+	verifyToolResult(t, text, "Showing 1-2 of 4")
+	verifyToolResult(t, text, page.NextCursor)
+
+	request := createTestMCPRequest("bizflycloud_list_servers", map[string]interface{}{formatArg: formatJSON})
+	var output struct {
+		Servers    []ServerOutput `json:"servers"`
+		Count      int            `json:"count"`
+		Total      int            `json:"total"`
+		NextCursor string         `json:"next_cursor"`
+	}
+	if err := json.Unmarshal([]byte(getTextFromResult(pageResult(request, "", "servers", []ServerOutput{{}, {}}, page))), &output); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output.Count != 2 || output.Total != 4 || output.NextCursor != page.NextCursor {
+		t.Errorf("Unexpected envelope %+v", output)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"strings"
 
 	"github.com/bizflycloud/gobizfly"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -17,29 +16,34 @@ func RegisterLoadBalancerTools(s *server.MCPServer, client *gobizfly.Client) {
 	// List load balancers tool
 	listLoadBalancersTool := mcp.NewTool("bizflycloud_list_loadbalancers",
 		mcp.WithDescription("List all Bizfly Cloud load balancers"),
+		withCommonOptions(),
+		withListOptions(),
 	)
 	s.AddTool(listLoadBalancersTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
+		query, err := parseListQuery(request.Params.Arguments)
+		if err != nil {
+			return errorResult(ctx, "Failed to list load balancers", err), nil
+		}
 		log.Printf("[DEBUG] Load Balancer List tool called")
-		loadbalancers, err := client.CloudLoadBalancer.List(ctx, &gobizfly.ListOptions{})
+		loadbalancers, err := services.LoadBalancers.List(ctx, &gobizfly.ListOptions{})
 		if err != nil {
 			log.Printf("[ERROR] Failed to list load balancers: %v", err)
-			// Check if error is 404 or service not available
-			errStr := strings.ToLower(err.Error())
-			if strings.Contains(errStr, "404") ||
-				strings.Contains(errStr, "not found") ||
-				strings.Contains(errStr, "resource not found") ||
-				strings.Contains(errStr, "<svg") ||
-				strings.Contains(errStr, "<html") {
-				return mcp.NewToolResultText("Available load balancers:\n\n(No load balancers found or Load Balancer service is not enabled)"), nil
+			// When the service isn't enabled for the account there is nothing to list
+			if isServiceUnavailable(ctx, err) {
+				return listResult(request, "Available load balancers:\n\n(No load balancers found or Load Balancer service is not enabled)", "load_balancers", []LoadBalancerOutput{}), nil
 			}
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list load balancers: %v", err)), nil
+			return errorResult(ctx, "Failed to list load balancers", err), nil
 		}
+		loadbalancers, page := pageItems(query, loadbalancers, loadBalancerListFields)
 
 		result := "Available load balancers:\n\n"
+		items := []LoadBalancerOutput{}
 		if len(loadbalancers) == 0 {
 			result += "(No load balancers found)\n"
 		} else {
 			for _, lb := range loadbalancers {
+				items = append(items, newLoadBalancerOutput(lb))
 				result += fmt.Sprintf("Load Balancer: %s\n", lb.Name)
 				result += fmt.Sprintf("  ID: %s\n", lb.ID)
 				result += fmt.Sprintf("  Provider Status: %s\n", lb.ProvisioningStatus)
@@ -50,12 +54,14 @@ func RegisterLoadBalancerTools(s *server.MCPServer, client *gobizfly.Client) {
 				result += "\n"
 			}
 		}
-		return mcp.NewToolResultText(result), nil
+		return pageResult(request, result, "load_balancers", items, page), nil
 	})
 
 	// Create load balancer tool
 	createLoadBalancerTool := mcp.NewTool("bizflycloud_create_loadbalancer",
 		mcp.WithDescription("Create a new Bizfly Cloud load balancer"),
+		withCommonOptions(),
+		withDryRun(),
 		mcp.WithString("name",
 			mcp.Required(),
 			mcp.Description("Name of the load balancer"),
@@ -73,6 +79,7 @@ func RegisterLoadBalancerTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(createLoadBalancerTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		name, ok := request.Params.Arguments["name"].(string)
 		if !ok {
 			return nil, errors.New("name must be a string")
@@ -87,15 +94,21 @@ func RegisterLoadBalancerTools(s *server.MCPServer, client *gobizfly.Client) {
 		}
 		description, _ := request.Params.Arguments["description"].(string)
 
-		loadbalancer, err := client.CloudLoadBalancer.Create(ctx, &gobizfly.LoadBalancerCreateRequest{
+		createReq := &gobizfly.LoadBalancerCreateRequest{
 			Name:        name,
 			NetworkType: networkType,
 			Type:        lbType,
 			Description: description,
-		})
+		}
+		if isDryRun(request) {
+			return dryRunResult(request, "bizflycloud_create_loadbalancer", "", createReq, validateLoadBalancerCreate(createReq)), nil
+		}
+
+		loadbalancer, err := services.LoadBalancers.Create(ctx, createReq)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create load balancer: %v", err)), nil
+			return errorResult(ctx, "Failed to create load balancer", err), nil
 		}
+		recordTask(ctx, services, TaskSpec{Action: "create", ResourceType: "loadbalancer", ResourceID: loadbalancer.ID, ResourceName: loadbalancer.Name, Done: []string{"ACTIVE"}})
 
 		result := fmt.Sprintf("Load balancer created successfully:\n")
 		result += fmt.Sprintf("  Name: %s\n", loadbalancer.Name)
@@ -104,48 +117,54 @@ func RegisterLoadBalancerTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += fmt.Sprintf("  Operating Status: %s\n", loadbalancer.OperatingStatus)
 		result += fmt.Sprintf("  Type: %s\n", loadbalancer.Type)
 		result += fmt.Sprintf("  Network Type: %s\n", loadbalancer.NetworkType)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newLoadBalancerOutput(loadbalancer)), nil
 	})
 
 	// Delete load balancer tool
 	deleteLoadBalancerTool := mcp.NewTool("bizflycloud_delete_loadbalancer",
 		mcp.WithDescription("Delete a Bizfly Cloud load balancer"),
+		withCommonOptions(),
+		withConfirmation(),
 		mcp.WithString("loadbalancer_id",
 			mcp.Required(),
 			mcp.Description("ID of the load balancer to delete"),
 		),
 	)
 	s.AddTool(deleteLoadBalancerTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		loadbalancerID, ok := request.Params.Arguments["loadbalancer_id"].(string)
 		if !ok {
 			return nil, errors.New("loadbalancer_id must be a string")
 		}
-		err := client.CloudLoadBalancer.Delete(ctx, &gobizfly.LoadBalancerDeleteRequest{
+		err := services.LoadBalancers.Delete(ctx, &gobizfly.LoadBalancerDeleteRequest{
 			ID:      loadbalancerID,
 			Cascade: false,
 		})
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete load balancer: %v", err)), nil
+			return errorResult(ctx, "Failed to delete load balancer", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Load balancer %s deleted successfully", loadbalancerID)), nil
+		recordTask(ctx, services, TaskSpec{Action: "delete", ResourceType: "loadbalancer", ResourceID: loadbalancerID, Done: []string{statusDeleted}})
+		return actionResult(request, fmt.Sprintf("Load balancer %s deleted successfully", loadbalancerID), ActionOutput{Action: "delete", ResourceType: "load_balancer", ResourceID: loadbalancerID}), nil
 	})
 
 	// Get load balancer tool
 	getLoadBalancerTool := mcp.NewTool("bizflycloud_get_loadbalancer",
 		mcp.WithDescription("Get details of a Bizfly Cloud load balancer"),
+		withCommonOptions(),
 		mcp.WithString("loadbalancer_id",
 			mcp.Required(),
 			mcp.Description("ID of the load balancer to get details for"),
 		),
 	)
 	s.AddTool(getLoadBalancerTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		loadbalancerID, ok := request.Params.Arguments["loadbalancer_id"].(string)
 		if !ok {
 			return nil, errors.New("loadbalancer_id must be a string")
 		}
-		lb, err := client.CloudLoadBalancer.Get(ctx, loadbalancerID)
+		lb, err := services.LoadBalancers.Get(ctx, loadbalancerID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get load balancer: %v", err)), nil
+			return errorResult(ctx, "Failed to get load balancer", err), nil
 		}
 
 		result := fmt.Sprintf("Load Balancer Details:\n\n")
@@ -160,12 +179,14 @@ func RegisterLoadBalancerTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += fmt.Sprintf("Admin State: %v\n", lb.AdminStateUp)
 		result += fmt.Sprintf("Created At: %s\n", lb.CreatedAt)
 		result += fmt.Sprintf("Updated At: %s\n", lb.UpdatedAt)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newLoadBalancerOutput(lb)), nil
 	})
 
 	// Update load balancer tool
 	updateLoadBalancerTool := mcp.NewTool("bizflycloud_update_loadbalancer",
 		mcp.WithDescription("Update a Bizfly Cloud load balancer"),
+		withCommonOptions(),
+		withDryRun(),
 		mcp.WithString("loadbalancer_id",
 			mcp.Required(),
 			mcp.Description("ID of the load balancer to update"),
@@ -181,6 +202,7 @@ func RegisterLoadBalancerTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(updateLoadBalancerTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		loadbalancerID, ok := request.Params.Arguments["loadbalancer_id"].(string)
 		if !ok {
 			return nil, errors.New("loadbalancer_id must be a string")
@@ -196,17 +218,64 @@ func RegisterLoadBalancerTools(s *server.MCPServer, client *gobizfly.Client) {
 		if adminStateUp, ok := request.Params.Arguments["admin_state_up"].(bool); ok {
 			req.AdminStateUp = &adminStateUp
 		}
+		if isDryRun(request) {
+			target := fmt.Sprintf("load balancer %s", loadbalancerID)
+			return dryRunResult(request, "bizflycloud_update_loadbalancer", target, req, validateLoadBalancerUpdate(req)), nil
+		}
 
-		lb, err := client.CloudLoadBalancer.Update(ctx, loadbalancerID, req)
+		lb, err := services.LoadBalancers.Update(ctx, loadbalancerID, req)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to update load balancer: %v", err)), nil
+			return errorResult(ctx, "Failed to update load balancer", err), nil
 		}
+		recordTask(ctx, services, TaskSpec{Action: "update", ResourceType: "loadbalancer", ResourceID: loadbalancerID, ResourceName: lb.Name, Done: []string{"ACTIVE"}, From: lb.ProvisioningStatus})
 
 		result := fmt.Sprintf("Load balancer updated successfully:\n")
 		result += fmt.Sprintf("  Name: %s\n", lb.Name)
 		result += fmt.Sprintf("  ID: %s\n", lb.ID)
 		result += fmt.Sprintf("  Description: %s\n", lb.Description)
 		result += fmt.Sprintf("  Admin State: %v\n", lb.AdminStateUp)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newLoadBalancerOutput(lb)), nil
 	})
-} 
\ No newline at end of file
+}
+
+// previewLoadBalancerDeletion describes the load balancer with its listeners and pools
+func previewLoadBalancerDeletion(ctx context.Context, services *Services, args map[string]interface{}) (string, error) {
+	loadbalancerID, err := stringArg(args, "loadbalancer_id")
+	if err != nil {
+		return "", err
+	}
+	lb, err := services.LoadBalancers.Get(ctx, loadbalancerID)
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("Load Balancer: %s\n", lb.Name)
+	result += fmt.Sprintf("  ID: %s\n", lb.ID)
+	result += fmt.Sprintf("  Status: %s\n", lb.OperatingStatus)
+	result += fmt.Sprintf("  VIP Address: %s\n", lb.VipAddress)
+	result += fmt.Sprintf("  Listeners: %d\n", len(lb.Listeners))
+	result += fmt.Sprintf("  Pools: %d\n", len(lb.Pools))
+	result += fmt.Sprintf("  Created At: %s\n", lb.CreatedAt)
+	return result, nil
+}
+
+// validateLoadBalancerCreate checks a load balancer create request before it is sent
+func validateLoadBalancerCreate(req *gobizfly.LoadBalancerCreateRequest) *requestValidation {
+	v := &requestValidation{}
+	v.check(req.Name != "", "name is set")
+	v.check(req.NetworkType == "external" || req.NetworkType == "internal", "network type is external or internal")
+	v.check(req.Type != "", "type is set")
+	return v
+}
+
+// validateLoadBalancerUpdate checks a load balancer update request before it is sent
+func validateLoadBalancerUpdate(req *gobizfly.LoadBalancerUpdateRequest) *requestValidation {
+	v := &requestValidation{}
+	v.check(req.Name != nil || req.Description != nil || req.AdminStateUp != nil, "at least one field is changed")
+	return v
+}
+
+// loadBalancerListFields are the fields list_loadbalancers filters and sorts on
+func loadBalancerListFields(lb *gobizfly.LoadBalancer) listFields {
+	return listFields{Name: lb.Name, Status: lb.ProvisioningStatus, CreatedAt: lb.CreatedAt}
+}
@@ -2,10 +2,14 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
 
-	"github.com/bizflycloud/gobizfly"
+	"github.com/bizflycloud-mcp-server/internal/fakecloud"
 	"github.com/mark3labs/mcp-go/server"
 )
 
@@ -15,71 +19,190 @@ const (
 )
 
 func main() {
-	// Load environment variables
-	username := os.Getenv("BIZFLY_USERNAME")
-	password := os.Getenv("BIZFLY_PASSWORD")
-	region := os.Getenv("BIZFLY_REGION")
-	apiURL := os.Getenv("BIZFLY_API_URL")
+	// Parse transport flags (environment variables provide the defaults)
+	transport := TransportConfig{}
+	flag.StringVar(&transport.Mode, "transport", envOrDefault("BIZFLY_MCP_TRANSPORT", transportStdio), "Transport to serve MCP over: stdio, sse or http")
+	flag.StringVar(&transport.ListenAddr, "listen", envOrDefault("BIZFLY_MCP_LISTEN", defaultListenAddr), "Listen address for the sse and http transports")
+	flag.StringVar(&transport.BaseURL, "base-url", os.Getenv("BIZFLY_MCP_BASE_URL"), "Public base URL advertised to SSE clients")
+	flag.StringVar(&transport.TLSCertFile, "tls-cert", os.Getenv("BIZFLY_MCP_TLS_CERT"), "TLS certificate file for the sse and http transports")
+	flag.StringVar(&transport.TLSKeyFile, "tls-key", os.Getenv("BIZFLY_MCP_TLS_KEY"), "TLS private key file for the sse and http transports")
+	configPath := flag.String("config", os.Getenv("BIZFLY_MCP_CONFIG"), "YAML or TOML file of named account profiles (defaults to BIZFLY_* environment variables)")
+	profileName := flag.String("profile", os.Getenv("BIZFLY_PROFILE"), "Profile used when a tool call doesn't name one (overrides default_profile)")
+	services := flag.String("services", os.Getenv("BIZFLY_MCP_SERVICES"), "Comma separated service groups to enable, e.g. server,volume,dns (default all)")
+	disableServices := flag.String("disable-services", os.Getenv("BIZFLY_MCP_DISABLE_SERVICES"), "Comma separated service groups to disable")
+	allowTools := flag.String("allow-tools", os.Getenv("BIZFLY_MCP_ALLOW_TOOLS"), "Comma separated tool name globs to enable, e.g. bizflycloud_list_* (default all)")
+	denyTools := flag.String("deny-tools", os.Getenv("BIZFLY_MCP_DENY_TOOLS"), "Comma separated tool name globs to disable, e.g. bizflycloud_delete_*")
+	readOnly := flag.Bool("read-only", envBool("BIZFLY_MCP_READ_ONLY"), "Only expose tools that don't change infrastructure")
+	confirmationTTL := flag.Duration("confirmation-ttl", defaultConfirmationTTL, "How long the confirmation token of a destructive tool preview stays valid")
+	auditLog := flag.String("audit-log", os.Getenv("BIZFLY_MCP_AUDIT_LOG"), "Write a JSON Lines audit record of every tool call to this file, or to stdout or stderr")
+	auditMaxSize := flag.Int("audit-log-max-size", defaultAuditMaxSizeMB, "Rotate the audit log file once it reaches this many megabytes (0 disables rotation)")
+	auditMaxBackups := flag.Int("audit-log-max-backups", defaultAuditMaxBackups, "Number of rotated audit log files to keep")
+	maxOutputChars := flag.Int("max-output-chars", defaultMaxOutputChars, "Truncate tool results longer than this many characters; bizflycloud_fetch_more returns the rest (0 disables truncation)")
+	catalogTTL := flag.Duration("catalog-ttl", defaultCatalogTTL, "How long flavors, images and volume types are cached between lookups (0 disables caching)")
+	maxRetries := flag.Int("max-retries", defaultMaxRetries, "Retry transient Bizfly API failures this many times with exponential backoff (0 disables retries)")
+	retryBaseDelay := flag.Duration("retry-base-delay", defaultRetryBaseDelay, "Backoff before the first retry; it doubles with every attempt")
+	rateLimit := flag.Float64("rate-limit", defaultRateLimit, "Maximum sustained Bizfly API requests per second to each service (0 disables rate limiting)")
+	rateLimitBurst := flag.Int("rate-limit-burst", defaultRateLimitBurst, "Number of Bizfly API requests to each service that may be sent at once")
+	resourcePollInterval := flag.Duration("resource-poll-interval", defaultResourcePollInterval, "How often resources a client subscribed to are checked for changes (stdio transport only)")
+	mock := flag.Bool("mock", envBool("BIZFLY_MCP_MOCK"), "Serve every tool against an in-memory simulated Bizfly Cloud instead of the real API; no credentials are needed")
+	mockFixture := flag.String("mock-fixture", os.Getenv("BIZFLY_MCP_MOCK_FIXTURE"), "JSON file of the servers, volumes, clusters, DNS zones... the simulated cloud starts with (defaults to a built-in sample account)")
+	flag.DurationVar(&transport.ShutdownTimeout, "shutdown-timeout", defaultShutdownTimeout, "How long to wait for in-flight requests on shutdown")
+	flag.Parse()
+	transport.AuthToken = os.Getenv("BIZFLY_MCP_AUTH_TOKEN")
 
-	if username == "" || password == "" {
-		log.Fatal("BIZFLY_USERNAME and BIZFLY_PASSWORD environment variables are required")
+	if err := transport.Validate(); err != nil {
+		log.Fatalf("Invalid transport configuration: %v", err)
 	}
-
-	// Set defaults if not provided
-	if region == "" {
-		region = defaultRegion
+	if *resourcePollInterval <= 0 {
+		log.Fatalf("Invalid --resource-poll-interval: must be positive")
 	}
-	if apiURL == "" {
-		apiURL = defaultAPIURL
+	if *auditLog == auditSinkStdout && transport.Mode == transportStdio {
+		log.Fatalf("Invalid --audit-log: stdout carries the MCP protocol with the stdio transport; use stderr or a file")
 	}
 
-	// Initialize Bizfly client
-	client, err := gobizfly.NewClient(
-		gobizfly.WithAPIURL(apiURL),
-		gobizfly.WithRegionName(region),
-	)
+	// Load account profiles from the config file, or a single profile from the environment.
+	// Mock mode needs neither: its only profile talks to the simulated cloud.
+	var config *Config
+	var cloud *fakecloud.Cloud
+	var err error
+	if *mock {
+		state, err := LoadMockState(*mockFixture)
+		if err != nil {
+			log.Fatalf("Invalid --mock-fixture: %v", err)
+		}
+		cloud = fakecloud.New(state)
+		cloud.SetProvisioningReads(mockProvisioningReads)
+		config = NewMockConfig()
+		log.Printf("[INFO] Mock mode: serving a simulated Bizfly Cloud seeded from the %s; changes are kept in memory only", mockFixtureName(*mockFixture))
+	} else if *configPath != "" {
+		config, err = LoadConfig(*configPath)
+	} else {
+		config, err = ConfigFromEnv(os.Getenv)
+	}
 	if err != nil {
-		log.Fatalf("Failed to create BizflyCloud client: %v", err)
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if *profileName != "" {
+		if _, err := config.Profile(*profileName); err != nil {
+			log.Fatalf("Invalid --profile: %v", err)
+		}
+		config.DefaultProfile = *profileName
+	}
+	// Flags override the tool selection from the config file
+	filter := config.Tools
+	for _, override := range []struct {
+		value  string
+		target *[]string
+	}{
+		{*services, &filter.Services},
+		{*disableServices, &filter.DisableServices},
+		{*allowTools, &filter.Allow},
+		{*denyTools, &filter.Deny},
+	} {
+		if override.value != "" {
+			*override.target = splitList(override.value)
+		}
+	}
+	if err := filter.Validate(); err != nil {
+		log.Fatalf("Invalid tool selection: %v", err)
+	}
+	defaultProfile, _ := config.Profile("")
+	log.Printf("[INFO] Loaded profiles %s; authenticating %s with %s credentials from %s",
+		strings.Join(config.ProfileNames(), ", "), defaultProfile.Name, defaultProfile.Credentials.Method, defaultProfile.Credentials.Source)
+
+	// The pool builds one client per profile and region on demand; every client
+	// has its own token manager, which refreshes the Keystone token before it
+	// expires and re-authenticates when an API call is rejected with 401. All
+	// clients share one transport that retries transient failures and rate
+	// limits the requests to each service. In mock mode the simulated cloud
+	// answers every request, the token and service catalog included, in process.
+	pool := NewClientPool(config)
+	if cloud != nil {
+		pool.SetTransport(cloud)
+	} else {
+		pool.SetTransport(NewRetryTransport(RetryPolicy{
+			MaxRetries:     *maxRetries,
+			BaseDelay:      *retryBaseDelay,
+			RateLimit:      *rateLimit,
+			RateLimitBurst: *rateLimitBurst,
+		}, http.DefaultTransport))
 	}
 
-	// Initialize token
+	// Initialize the client for the default profile and region
 	ctx := context.Background()
-	token, err := client.Token.Init(
-		ctx,
-		&gobizfly.TokenCreateRequest{
-			AuthMethod: "password",
-			Username:   username,
-			Password:   password,
-		})
+	client, err := pool.Client(ctx, "", "")
 	if err != nil {
 		log.Fatalf("Failed to authenticate: %v", err)
 	}
 
-	// Set the token
-	client.SetKeystoneToken(token)
-
-	// Create MCP server
+	// Create MCP server; the middleware swaps in the client for the profile and region a tool call asks for.
+	// In read-only mode mutating calls are refused before a client is even selected, and
+	// destructive tools only run once a preview of what they delete has been confirmed.
+	// The audit log wraps everything else so refused calls are recorded too; the format
+	// middleware sits just inside it so JSON-mode errors come back as JSON, and the
+	// output budget truncates oversized results before either sees them.
+	var options []server.ServerOption
+	if *auditLog != "" {
+		sink, err := OpenAuditSink(*auditLog, *auditMaxSize, *auditMaxBackups)
+		if err != nil {
+			log.Fatalf("Invalid --audit-log: %v", err)
+		}
+		defer sink.Close()
+		options = append(options, server.WithToolHandlerMiddleware(NewAuditLogger(sink, config).Middleware()))
+		log.Printf("[INFO] Writing the audit log to %s", *auditLog)
+	}
+	options = append(options,
+		server.WithToolHandlerMiddleware(formatMiddleware()),
+		server.WithToolHandlerMiddleware(NewOutputBudget(*maxOutputChars, defaultContinuationTTL).Middleware()),
+	)
+	if *readOnly {
+		options = append(options, server.WithToolHandlerMiddleware(readOnlyMiddleware()))
+	}
+	options = append(options,
+		server.WithToolHandlerMiddleware(pool.Middleware()),
+		server.WithToolHandlerMiddleware(NewCatalogCache(*catalogTTL).Middleware()),
+		server.WithToolHandlerMiddleware(NewTaskRegistry().Middleware()),
+		server.WithToolHandlerMiddleware(NewConfirmationStore(*confirmationTTL).Middleware()),
+		// Only the stdio transport can push the updates of subscribed resources
+		server.WithResourceCapabilities(transport.Mode == transportStdio, false),
+	)
 	s := server.NewMCPServer(
 		"BizflyCloud MCP",
 		"1.0.0",
+		options...,
 	)
 
-	// Register tools
-	RegisterServerTools(s, client)
-	RegisterVolumeTools(s, client)
-	RegisterKubernetesTools(s, client)
-	RegisterDatabaseTools(s, client)
-	RegisterLoadBalancerTools(s, client)
-	RegisterDNSTools(s, client)
-	RegisterCDNTools(s, client)
-	RegisterKMSTools(s, client)
-	RegisterContainerRegistryTools(s, client)
-	RegisterAutoScalingTools(s, client)
-	RegisterAlertTools(s, client)
-	RegisterResourceSummaryTools(s, client)
+	// Register the tools of the enabled services, minus any denied tools
+	registerTools(s, client, pool, &filter)
+	log.Printf("[INFO] Registered %d tools", len(registeredToolNames(s)))
+	// Servers, volumes, clusters, databases and DNS zones can also be attached as resources
+	registerResources(s, client, &filter)
+	var subscriptions *ResourceSubscriptions
+	if transport.Mode == transportStdio {
+		subscriptions = NewResourceSubscriptions(client, &filter, *resourcePollInterval)
+	}
 
-	// Start the stdio server for Cursor/Claude Desktop integration
-	if err := server.ServeStdio(s); err != nil {
+	if *readOnly {
+		removed := ApplyReadOnly(s)
+		log.Printf("[INFO] Read-only mode: %d mutating tools disabled", len(removed))
+	}
+
+	// Serve over stdio for Cursor/Claude Desktop integration, or over HTTP for shared deployments
+	if err := Serve(s, subscriptions, transport); err != nil {
 		log.Fatalf("Server error: %v\n", err)
 	}
-} 
\ No newline at end of file
+}
+
+// envOrDefault returns the value of the environment variable or the fallback when unset
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// envBool reports whether the environment variable is set to a true value such as "true" or "1"
+func envBool(key string) bool {
+	value, _ := strconv.ParseBool(os.Getenv(key))
+	return value
+}
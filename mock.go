@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bizflycloud-mcp-server/internal/fakecloud"
+)
+
+// The account mock mode serves when no fixture file is given
+//
+//go:embed mock-fixture.json
+var defaultMockFixture []byte
+
+const (
+	mockProfileName = "mock"
+	// mockAPIURL is never dialled: the clients of the mock profile send every
+	// request to the simulated cloud in process
+	mockAPIURL = "http://mock.bizflycloud.invalid"
+	// mockProvisioningReads is how many status reads a server, cluster or
+	// database created in mock mode takes to become ready, so that waiting
+	// create tools have something to wait for
+	mockProvisioningReads = 3
+)
+
+// LoadMockState reads a fixture in the fakecloud.State JSON format. An
+// empty path loads the built-in sample account. Unknown fields are rejected so
+// typos in a fixture don't silently drop resources.
+func LoadMockState(path string) (fakecloud.State, error) {
+	data := defaultMockFixture
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return fakecloud.State{}, fmt.Errorf("failed to read mock fixture: %w", err)
+		}
+	}
+	var state fakecloud.State
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&state); err != nil {
+		return fakecloud.State{}, fmt.Errorf("invalid mock fixture %s: %w", mockFixtureName(path), err)
+	}
+	return state, nil
+}
+
+// NewMockConfig returns a config with a single profile whose clients talk to
+// the simulated cloud. The credentials are placeholders the simulated cloud
+// accepts; nothing is sent to Bizfly Cloud.
+func NewMockConfig() *Config {
+	return &Config{
+		DefaultProfile: mockProfileName,
+		Profiles: map[string]*Profile{
+			mockProfileName: {
+				Name:       mockProfileName,
+				AuthMethod: authMethodPassword,
+				Region:     defaultRegion,
+				APIURL:     mockAPIURL,
+				Credentials: &Credentials{
+					Method:   authMethodPassword,
+					Username: mockProfileName,
+					Password: mockProfileName,
+					Source:   "mock mode",
+				},
+			},
+		},
+	}
+}
+
+// mockFixtureName names the fixture in log and error messages
+func mockFixtureName(path string) string {
+	if path == "" {
+		return "built-in sample account"
+	}
+	return path
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bizflycloud-mcp-server/internal/fakecloud"
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newMockServer wires a server the way main does in mock mode, and records
+// the requests the simulated cloud serves
+func newMockServer(t *testing.T, state fakecloud.State) (*fakecloud.Cloud, *server.MCPServer) {
+	t.Helper()
+	cloud := fakecloud.New(state)
+	cloud.RecordRequests()
+	pool := NewClientPool(NewMockConfig())
+	pool.SetTransport(cloud)
+	client := newMockClient(t, pool)
+	s := server.NewMCPServer("BizflyCloud MCP Test", "1.0.0",
+		server.WithToolHandlerMiddleware(pool.Middleware()),
+		server.WithToolHandlerMiddleware(NewTaskRegistry().Middleware()),
+	)
+	registerTools(s, client, pool, nil)
+	registerResources(s, client, nil)
+	return cloud, s
+}
+
+// newMockClient returns the client of the default mock profile
+func newMockClient(t *testing.T, pool *ClientPool) *gobizfly.Client {
+	t.Helper()
+	client, err := pool.Client(context.Background(), "", "")
+	if err != nil {
+		t.Fatalf("Failed to authenticate with the simulated cloud: %v", err)
+	}
+	return client
+}
+
+func TestLoadMockStateDefaultFixture(t *testing.T) {
+	state, err := LoadMockState("")
+	if err != nil {
+		t.Fatalf("Failed to load the built-in fixture: %v", err)
+	}
+	if len(state.Servers) == 0 || len(state.Volumes) == 0 || len(state.Clusters) == 0 || len(state.DNSZones) == 0 || len(state.Databases) == 0 {
+		t.Errorf("Expected the built-in fixture to seed servers, volumes, clusters, DNS zones and databases, got %d, %d, %d, %d, %d",
+			len(state.Servers), len(state.Volumes), len(state.Clusters), len(state.DNSZones), len(state.Databases))
+	}
+}
+
+func TestLoadMockStateFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fixture.json")
+	if err := os.WriteFile(path, []byte(`{"servers": [{"id": "srv-1", "name": "web-1", "status": "ACTIVE"}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	state, err := LoadMockState(path)
+	if err != nil {
+		t.Fatalf("Failed to load the fixture: %v", err)
+	}
+	if len(state.Servers) != 1 || state.Servers[0].Name != "web-1" {
+		t.Errorf("Expected server web-1, got %+v", state.Servers)
+	}
+
+	typo := filepath.Join(dir, "typo.json")
+	if err := os.WriteFile(typo, []byte(`{"server": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadMockState(typo); err == nil || !strings.Contains(err.Error(), "typo.json") {
+		t.Errorf("Expected an error naming the fixture for an unknown field, got %v", err)
+	}
+	if _, err := LoadMockState(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected an error for a missing fixture")
+	}
+}
+
+func TestMockModeServesFixture(t *testing.T) {
+	state, err := LoadMockState("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cloud, s := newMockServer(t, state)
+
+	result := callTool(t, s, "bizflycloud_list_servers", map[string]interface{}{})
+	if result.IsError {
+		t.Fatalf("list_servers failed: %s", getTextFromResult(result))
+	}
+	if text := getTextFromResult(result); !strings.Contains(text, "web-01") {
+		t.Errorf("Expected the fixture server web-01, got:\n%s", text)
+	}
+
+	// Mutating tools change the simulated state
+	result = callTool(t, s, "bizflycloud_create_volume", map[string]interface{}{"name": "logs", "size": float64(30), "volume_type": "PREMIUM-SSD1", "availability_zone": "HN1"})
+	if result.IsError {
+		t.Fatalf("create_volume failed: %s", getTextFromResult(result))
+	}
+	result = callTool(t, s, "bizflycloud_list_volumes", map[string]interface{}{})
+	if text := getTextFromResult(result); !strings.Contains(text, "logs") {
+		t.Errorf("Expected the created volume in the list, got:\n%s", text)
+	}
+	if got, want := len(cloud.State().Volumes), len(state.Volumes)+1; got != want {
+		t.Errorf("Expected %d volumes in the simulated cloud, got %d", want, got)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// formatArg selects between the human-readable text output and the JSON schemas in schemas.go
+const formatArg = "format"
+
+// Output formats
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// ActionOutput is the JSON result of a tool that acts on a resource without returning it,
+// such as start_server or delete_volume
+type ActionOutput struct {
+	Action       string            `json:"action"`
+	ResourceType string            `json:"resource_type"`
+	ResourceID   string            `json:"resource_id"`
+	Message      string            `json:"message"`
+	Details      map[string]string `json:"details,omitempty"`
+}
+
+// ErrorOutput is the JSON content of a tool error
+type ErrorOutput struct {
+	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+}
+
+// withFormatOption adds the format argument
+func withFormatOption() mcp.ToolOption {
+	return mcp.WithString(formatArg,
+		mcp.Description("Output format: text (default) or json. JSON results follow the schemas documented in the README"),
+		mcp.Enum(formatText, formatJSON),
+	)
+}
+
+// outputFormat returns the format a tool call asked for
+func outputFormat(request mcp.CallToolRequest) string {
+	if format, _ := request.Params.Arguments[formatArg].(string); format == formatJSON {
+		return formatJSON
+	}
+	return formatText
+}
+
+// formatMiddleware rejects unknown formats and, in JSON mode, wraps plain-text tool errors as ErrorOutput
+func formatMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			format, _ := request.Params.Arguments[formatArg].(string)
+			switch format {
+			case "", formatText, formatJSON:
+			default:
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to select format: unknown format %q (available: %s, %s)", format, formatText, formatJSON)), nil
+			}
+
+			result, err := next(ctx, request)
+			if err != nil || result == nil || !result.IsError || format != formatJSON {
+				return result, err
+			}
+			// Errors that are already JSON, such as a failed dry run, are kept as they are
+			text := resultText(result)
+			if json.Valid([]byte(text)) {
+				return result, nil
+			}
+			code, _ := result.Meta[errorCodeMeta].(string)
+			wrapped := jsonResult(ErrorOutput{Error: text, Code: code})
+			wrapped.Meta = result.Meta
+			wrapped.IsError = true
+			return wrapped, nil
+		}
+	}
+}
+
+// formatResult returns text, or data encoded as JSON when the call asked for it
+func formatResult(request mcp.CallToolRequest, text string, data interface{}) *mcp.CallToolResult {
+	if outputFormat(request) != formatJSON {
+		return mcp.NewToolResultText(text)
+	}
+	return jsonResult(data)
+}
+
+// listResult returns text, or an unpaged list as the same JSON envelope pageResult uses
+func listResult(request mcp.CallToolRequest, text, key string, items interface{}) *mcp.CallToolResult {
+	return pageResult(request, text, key, items, listPage{Total: sliceLen(items)})
+}
+
+// sliceLen returns the length of items, or 0 if it isn't a slice
+func sliceLen(items interface{}) int {
+	if v := reflect.ValueOf(items); v.Kind() == reflect.Slice {
+		return v.Len()
+	}
+	return 0
+}
+
+// actionResult returns the message, or an ActionOutput as JSON
+func actionResult(request mcp.CallToolRequest, message string, action ActionOutput) *mcp.CallToolResult {
+	action.Message = message
+	return formatResult(request, message, action)
+}
+
+// jsonResult encodes data as the text content of a tool result
+func jsonResult(data interface{}) *mcp.CallToolResult {
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to encode result: %v", err))
+	}
+	return mcp.NewToolResultText(string(encoded))
+}
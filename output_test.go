@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newFormatTestServer registers every tool behind the format middleware, as main does
+func newFormatTestServer(t *testing.T) *server.MCPServer {
+	t.Helper()
+	s := server.NewMCPServer("BizflyCloud MCP Test", "1.0.0",
+		server.WithToolHandlerMiddleware(formatMiddleware()),
+	)
+	client, _ := gobizfly.NewClient()
+	registerTools(s, client, NewClientPool(&Config{}), nil)
+	return s
+}
+
+func TestEveryToolHasFormatArgument(t *testing.T) {
+	s := newFullTestServer(t)
+	response := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Failed to encode tools/list response: %v", err)
+	}
+	var list struct {
+		Result mcp.ListToolsResult `json:"result"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatalf("Failed to decode tools/list response: %v", err)
+	}
+	for _, tool := range list.Result.Tools {
+		if _, ok := tool.InputSchema.Properties[formatArg]; !ok {
+			t.Errorf("Tool %s has no %s argument", tool.Name, formatArg)
+		}
+	}
+}
+
+func TestFormatMiddleware(t *testing.T) {
+	ctx := context.Background()
+	failing := formatMiddleware()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("Failed to get server: 404 not found"), nil
+	})
+
+	t.Run("rejects unknown formats", func(t *testing.T) {
+		result, _ := failing(ctx, createTestMCPRequest("bizflycloud_get_server", map[string]interface{}{formatArg: "yaml"}))
+		verifyToolError(t, result, `unknown format "yaml"`)
+	})
+
+	t.Run("keeps text errors in text mode", func(t *testing.T) {
+		result, _ := failing(ctx, createTestMCPRequest("bizflycloud_get_server", map[string]interface{}{}))
+		if text := getTextFromResult(result); text != "Failed to get server: 404 not found" {
+			t.Errorf("Expected the plain error, got %q", text)
+		}
+	})
+
+	t.Run("wraps errors in json mode", func(t *testing.T) {
+		result, _ := failing(ctx, createTestMCPRequest("bizflycloud_get_server", map[string]interface{}{formatArg: formatJSON}))
+		if !result.IsError {
+			t.Fatal("Expected the result to stay an error")
+		}
+		var output ErrorOutput
+		if err := json.Unmarshal([]byte(getTextFromResult(result)), &output); err != nil {
+			t.Fatalf("Expected a JSON error, got %q", getTextFromResult(result))
+		}
+		if output.Error != "Failed to get server: 404 not found" {
+			t.Errorf("Unexpected error %q", output.Error)
+		}
+	})
+}
+
+func TestJSONResults(t *testing.T) {
+	request := createTestMCPRequest("bizflycloud_list_volumes", map[string]interface{}{formatArg: formatJSON})
+
+	t.Run("lists carry a count and are never null", func(t *testing.T) {
+		var output map[string]interface{}
+		if err := json.Unmarshal([]byte(getTextFromResult(listResult(request, "text", "volumes", []VolumeOutput{}))), &output); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if volumes, ok := output["volumes"].([]interface{}); !ok || len(volumes) != 0 {
+			t.Errorf("Expected an empty volumes array, got %v", output["volumes"])
+		}
+		if output["count"] != 0.0 {
+			t.Errorf("Expected a count of 0, got %v", output["count"])
+		}
+	})
+
+	t.Run("actions carry their message", func(t *testing.T) {
+		result := actionResult(request, "Volume vol-1 deleted successfully", ActionOutput{Action: "delete", ResourceType: "volume", ResourceID: "vol-1"})
+		var output ActionOutput
+		if err := json.Unmarshal([]byte(getTextFromResult(result)), &output); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if output.Action != "delete" || output.ResourceID != "vol-1" || output.Message != "Volume vol-1 deleted successfully" {
+			t.Errorf("Unexpected action %+v", output)
+		}
+	})
+
+	t.Run("text stays the default", func(t *testing.T) {
+		text := createTestMCPRequest("bizflycloud_list_volumes", map[string]interface{}{})
+		if got := getTextFromResult(formatResult(text, "Available volumes:", VolumeOutput{})); got != "Available volumes:" {
+			t.Errorf("Expected the text output, got %q", got)
+		}
+	})
+}
+
+func TestJSONDryRun(t *testing.T) {
+	s := newFormatTestServer(t)
+
+	valid := callTool(t, s, "bizflycloud_create_volume", map[string]interface{}{
+		"name": "data", "size": 50.0, "volume_type": "PREMIUM-SSD1", dryRunArg: true, formatArg: formatJSON,
+	})
+	var output DryRunOutput
+	if err := json.Unmarshal([]byte(getTextFromResult(valid)), &output); err != nil {
+		t.Fatalf("Expected a JSON dry run, got %q", getTextFromResult(valid))
+	}
+	if !output.Valid || valid.IsError || output.RequestType != "gobizfly.VolumeCreateRequest" {
+		t.Errorf("Unexpected dry run %+v", output)
+	}
+
+	invalid := callTool(t, s, "bizflycloud_create_volume", map[string]interface{}{
+		"name": "data", "size": 0.0, "volume_type": "PREMIUM-SSD1", dryRunArg: true, formatArg: formatJSON,
+	})
+	output = DryRunOutput{}
+	if err := json.Unmarshal([]byte(getTextFromResult(invalid)), &output); err != nil {
+		t.Fatalf("Expected the failed dry run to stay JSON, got %q", getTextFromResult(invalid))
+	}
+	if output.Valid || !invalid.IsError {
+		t.Errorf("Expected an invalid dry run, got %+v", output)
+	}
+}
+
+func TestJSONConfirmationPreview(t *testing.T) {
+	handler := NewConfirmationStore(time.Minute).Middleware()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("deleted"), nil
+	})
+	result, _ := handler(context.Background(), createTestMCPRequest("bizflycloud_delete_volume", map[string]interface{}{
+		"volume_id": "vol-1", formatArg: formatJSON,
+	}))
+	var output ConfirmationOutput
+	if err := json.Unmarshal([]byte(getTextFromResult(result)), &output); err != nil {
+		t.Fatalf("Expected a JSON preview, got %q", getTextFromResult(result))
+	}
+	if output.Tool != "bizflycloud_delete_volume" || output.ConfirmationToken == "" || output.ExpiresAt == "" {
+		t.Errorf("Unexpected preview %+v", output)
+	}
+}
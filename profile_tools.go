@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// RegisterProfileTools registers the tools that describe the configured account profiles
+func RegisterProfileTools(s *server.MCPServer, pool *ClientPool) {
+	// List profiles tool
+	listProfilesTool := mcp.NewTool("bizflycloud_list_profiles",
+		mcp.WithDescription("List the named account profiles that tools can run as with the profile argument"),
+		withFormatOption(),
+	)
+	s.AddTool(listProfilesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		config := pool.Config()
+		result := "Available profiles:\n\n"
+		items := []ProfileOutput{}
+		for _, name := range config.ProfileNames() {
+			profile := config.Profiles[name]
+			item := ProfileOutput{
+				Name:             name,
+				Default:          name == config.DefaultProfile,
+				Region:           profile.Region,
+				APIURL:           profile.APIURL,
+				AvailabilityZone: profile.AvailabilityZone,
+			}
+			if profile.Credentials != nil {
+				item.AuthMethod = profile.Credentials.Method
+				item.ProjectID = profile.Credentials.ProjectID
+			}
+			items = append(items, item)
+			result += fmt.Sprintf("Profile: %s\n", name)
+			if name == config.DefaultProfile {
+				result += "  Default: yes\n"
+			}
+			result += fmt.Sprintf("  Region: %s\n", profile.Region)
+			result += fmt.Sprintf("  API URL: %s\n", profile.APIURL)
+			if profile.AvailabilityZone != "" {
+				result += fmt.Sprintf("  Availability Zone: %s\n", profile.AvailabilityZone)
+			}
+			if profile.Credentials != nil {
+				result += fmt.Sprintf("  Auth Method: %s\n", profile.Credentials.Method)
+				if profile.Credentials.ProjectID != "" {
+					result += fmt.Sprintf("  Project ID: %s\n", profile.Credentials.ProjectID)
+				}
+			}
+			result += "\n"
+		}
+		return listResult(request, result, "profiles", items), nil
+	})
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bizflycloud/gobizfly/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// RegisterRegionTools registers the tools that describe the regions a tool call can target
+func RegisterRegionTools(s *server.MCPServer, pool *ClientPool) {
+	// List regions tool
+	listRegionsTool := mcp.NewTool("bizflycloud_list_regions",
+		mcp.WithDescription("List the Bizfly Cloud regions that tools can run against with the region argument"),
+		withProfileOption(),
+		withFormatOption(),
+	)
+	s.AddTool(listRegionsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		profile := profileFromContext(ctx)
+		if profile == nil {
+			var err error
+			if profile, err = pool.Config().Profile(""); err != nil {
+				return errorResult(ctx, "Failed to get profile", err), nil
+			}
+		}
+		profileRegion, _ := utils.ParseRegionName(profile.Region)
+
+		result := fmt.Sprintf("Available regions for profile %s:\n\n", profile.Name)
+		items := []RegionOutput{}
+		for _, region := range KnownRegions() {
+			items = append(items, RegionOutput{
+				Name:      region,
+				Default:   region == profileRegion,
+				Connected: pool.Connected(profile.Name, region),
+				Services:  []string{},
+			})
+			result += fmt.Sprintf("Region: %s\n", region)
+			if region == profileRegion {
+				result += "  Default: yes\n"
+			}
+			if pool.Connected(profile.Name, region) {
+				result += "  Connected: yes\n"
+			}
+			result += "\n"
+		}
+
+		// The service catalog tells which services are enabled in each region
+		client, err := pool.Client(ctx, profile.Name, "")
+		if err != nil {
+			return errorResult(ctx, "Failed to get client", err), nil
+		}
+		services, err := client.Service.List(ctx)
+		if err != nil {
+			result += fmt.Sprintf("Service catalog unavailable: %v\n", err)
+			return listResult(request, result, "regions", items), nil
+		}
+
+		byRegion := make(map[string][]string)
+		for _, service := range services {
+			if service.Region == "" || !service.Enabled {
+				continue
+			}
+			byRegion[service.Region] = append(byRegion[service.Region], service.CanonicalName)
+		}
+		for i := range items {
+			if names, ok := byRegion[items[i].Name]; ok {
+				items[i].Services = append(items[i].Services, names...)
+				sort.Strings(items[i].Services)
+			}
+		}
+		if len(byRegion) == 0 {
+			return listResult(request, result, "regions", items), nil
+		}
+
+		regions := make([]string, 0, len(byRegion))
+		for region := range byRegion {
+			regions = append(regions, region)
+		}
+		sort.Strings(regions)
+
+		result += "Services by region:\n\n"
+		for _, region := range regions {
+			names := byRegion[region]
+			sort.Strings(names)
+			result += fmt.Sprintf("%s: %s\n", region, strings.Join(names, ", "))
+		}
+		return listResult(request, result, "regions", items), nil
+	})
+}
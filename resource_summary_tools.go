@@ -16,8 +16,10 @@ func RegisterResourceSummaryTools(s *server.MCPServer, client *gobizfly.Client)
 	// List all resources tool
 	listAllResourcesTool := mcp.NewTool("bizflycloud_list_all_resources",
 		mcp.WithDescription("List all Bizfly Cloud resources in a formatted table"),
+		withCommonOptions(),
 	)
 	s.AddTool(listAllResourcesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		log.Printf("[DEBUG] List All Resources tool called")
 		
 		var result strings.Builder
@@ -31,13 +33,19 @@ func RegisterResourceSummaryTools(s *server.MCPServer, client *gobizfly.Client)
 		var repos []*gobizfly.Repository
 		var cdnDomains *gobizfly.DomainsResp
 		var snapshots []*gobizfly.Snapshot
+		summary := ResourceSummaryOutput{
+			KMSCertificates:   []KMSCertificateOutput{},
+			AutoScalingGroups: []AutoScalingGroupOutput{},
+			Errors:            map[string]string{},
+		}
 		
 		// Servers
 		result.WriteString("## 1. Servers\n\n")
 		var err error
-		servers, err = client.CloudServer.List(ctx, &gobizfly.ServerListOptions{})
+		servers, err = services.Servers.List(ctx, &gobizfly.ServerListOptions{})
 		if err != nil {
 			result.WriteString(fmt.Sprintf("❌ Error: %v\n\n", err))
+			summary.Errors["servers"] = err.Error()
 		} else {
 			result.WriteString(fmt.Sprintf("**Total: %d servers**\n\n", len(servers)))
 			if len(servers) > 0 {
@@ -64,9 +72,10 @@ func RegisterResourceSummaryTools(s *server.MCPServer, client *gobizfly.Client)
 		
 		// Volumes
 		result.WriteString("## 2. Volumes\n\n")
-		volumes, err = client.CloudServer.Volumes().List(ctx, &gobizfly.VolumeListOptions{})
+		volumes, err = services.Volumes.List(ctx, &gobizfly.VolumeListOptions{})
 		if err != nil {
 			result.WriteString(fmt.Sprintf("❌ Error: %v\n\n", err))
+			summary.Errors["volumes"] = err.Error()
 		} else {
 			inUseCount := 0
 			availableCount := 0
@@ -94,9 +103,10 @@ func RegisterResourceSummaryTools(s *server.MCPServer, client *gobizfly.Client)
 		
 		// Kubernetes Clusters
 		result.WriteString("## 3. Kubernetes Clusters\n\n")
-		clusters, err = client.KubernetesEngine.List(ctx, &gobizfly.ListOptions{})
+		clusters, err = services.Kubernetes.List(ctx, &gobizfly.ListOptions{})
 		if err != nil {
 			result.WriteString(fmt.Sprintf("❌ Error: %v\n\n", err))
+			summary.Errors["kubernetes_clusters"] = err.Error()
 		} else {
 			result.WriteString(fmt.Sprintf("**Total: %d clusters**\n\n", len(clusters)))
 			if len(clusters) > 0 {
@@ -115,9 +125,10 @@ func RegisterResourceSummaryTools(s *server.MCPServer, client *gobizfly.Client)
 		
 		// Databases
 		result.WriteString("## 4. Databases\n\n")
-		databases, err = client.CloudDatabase.Instances().List(ctx, &gobizfly.CloudDatabaseListOption{})
+		databases, err = services.Databases.ListInstances(ctx, &gobizfly.CloudDatabaseListOption{})
 		if err != nil {
 			result.WriteString(fmt.Sprintf("❌ Error: %v\n\n", err))
+			summary.Errors["databases"] = err.Error()
 		} else {
 			result.WriteString(fmt.Sprintf("**Total: %d databases**\n\n", len(databases)))
 			if len(databases) > 0 {
@@ -136,9 +147,10 @@ func RegisterResourceSummaryTools(s *server.MCPServer, client *gobizfly.Client)
 		
 		// Container Registries
 		result.WriteString("## 5. Container Registries\n\n")
-		repos, err = client.ContainerRegistry.List(ctx, &gobizfly.ListOptions{})
+		repos, err = services.Registry.List(ctx, &gobizfly.ListOptions{})
 		if err != nil {
 			result.WriteString(fmt.Sprintf("❌ Error: %v\n\n", err))
+			summary.Errors["repositories"] = err.Error()
 		} else {
 			publicCount := 0
 			privateCount := 0
@@ -173,9 +185,10 @@ func RegisterResourceSummaryTools(s *server.MCPServer, client *gobizfly.Client)
 		
 		// CDN Domains
 		result.WriteString("## 6. CDN Domains\n\n")
-		cdnDomains, err = client.CDN.List(ctx, &gobizfly.ListOptions{})
+		cdnDomains, err = services.CDN.List(ctx, &gobizfly.ListOptions{})
 		if err != nil {
 			result.WriteString(fmt.Sprintf("❌ Error: %v\n\n", err))
+			summary.Errors["cdn_domains"] = err.Error()
 		} else {
 			if cdnDomains != nil && len(cdnDomains.Domains) > 0 {
 				result.WriteString(fmt.Sprintf("**Total: %d domains**\n\n", len(cdnDomains.Domains)))
@@ -196,16 +209,18 @@ func RegisterResourceSummaryTools(s *server.MCPServer, client *gobizfly.Client)
 		
 		// KMS Certificates
 		result.WriteString("## 7. KMS Certificates\n\n")
-		if client.KMS != nil && client.KMS.Certificates() != nil {
-			certificates, err := client.KMS.Certificates().List(ctx)
+		if services.KMS != nil {
+			certificates, err := services.KMS.List(ctx)
 			if err != nil {
 				result.WriteString(fmt.Sprintf("❌ Error: %v\n\n", err))
+				summary.Errors["kms_certificates"] = err.Error()
 			} else {
 				result.WriteString(fmt.Sprintf("**Total: %d certificates**\n\n", len(certificates)))
 				if len(certificates) > 0 {
 					result.WriteString("| Certificate Name | Container ID |\n")
 					result.WriteString("|------------------|--------------|\n")
 					for _, cert := range certificates {
+						summary.KMSCertificates = append(summary.KMSCertificates, KMSCertificateOutput{ContainerID: cert.ContainerID, Name: cert.Name})
 						result.WriteString(fmt.Sprintf("| %s | %s |\n", cert.Name, cert.ContainerID))
 					}
 				} else {
@@ -219,16 +234,18 @@ func RegisterResourceSummaryTools(s *server.MCPServer, client *gobizfly.Client)
 		
 		// Auto Scaling Groups
 		result.WriteString("## 8. Auto Scaling Groups\n\n")
-		if client.AutoScaling != nil && client.AutoScaling.AutoScalingGroups() != nil {
-			groups, err := client.AutoScaling.AutoScalingGroups().List(ctx, false)
+		if services.AutoScaling != nil {
+			groups, err := services.AutoScaling.List(ctx, false)
 			if err != nil {
 				result.WriteString(fmt.Sprintf("❌ Error: %v\n\n", err))
+				summary.Errors["autoscaling_groups"] = err.Error()
 			} else {
 				result.WriteString(fmt.Sprintf("**Total: %d groups**\n\n", len(groups)))
 				if len(groups) > 0 {
 					result.WriteString("| Name | Status | Min/Max Size | Desired | Current Nodes |\n")
 					result.WriteString("|------|--------|--------------|---------|---------------|\n")
 					for _, group := range groups {
+						summary.AutoScalingGroups = append(summary.AutoScalingGroups, newAutoScalingGroupOutput(group))
 						result.WriteString(fmt.Sprintf("| %s | %s | %d/%d | %d | %d |\n",
 							group.Name, group.Status, group.MinSize, group.MaxSize, group.DesiredCapacity, len(group.NodeIDs)))
 					}
@@ -243,9 +260,10 @@ func RegisterResourceSummaryTools(s *server.MCPServer, client *gobizfly.Client)
 		
 		// Snapshots
 		result.WriteString("## 9. Snapshots\n\n")
-		snapshots, err = client.CloudServer.Snapshots().List(ctx, &gobizfly.ListSnasphotsOptions{})
+		snapshots, err = services.Snapshots.List(ctx, &gobizfly.ListSnasphotsOptions{})
 		if err != nil {
 			result.WriteString(fmt.Sprintf("❌ Error: %v\n\n", err))
+			summary.Errors["snapshots"] = err.Error()
 		} else {
 			result.WriteString(fmt.Sprintf("**Total: %d snapshots**\n\n", len(snapshots)))
 			if len(snapshots) > 0 {
@@ -274,17 +292,50 @@ func RegisterResourceSummaryTools(s *server.MCPServer, client *gobizfly.Client)
 			cdnDomainCount = len(cdnDomains.Domains)
 		}
 		result.WriteString(fmt.Sprintf("- **CDN Domains**: %d\n", cdnDomainCount))
-		if client.KMS != nil && client.KMS.Certificates() != nil {
-			certs, _ := client.KMS.Certificates().List(ctx)
+		if services.KMS != nil {
+			certs, _ := services.KMS.List(ctx)
 			result.WriteString(fmt.Sprintf("- **KMS Certificates**: %d\n", len(certs)))
 		}
-		if client.AutoScaling != nil && client.AutoScaling.AutoScalingGroups() != nil {
-			grps, _ := client.AutoScaling.AutoScalingGroups().List(ctx, false)
+		if services.AutoScaling != nil {
+			grps, _ := services.AutoScaling.List(ctx, false)
 			result.WriteString(fmt.Sprintf("- **Auto Scaling Groups**: %d\n", len(grps)))
 		}
 		result.WriteString(fmt.Sprintf("- **Snapshots**: %d\n", len(snapshots)))
 		
-		return mcp.NewToolResultText(result.String()), nil
+		if outputFormat(request) != formatJSON {
+			return mcp.NewToolResultText(result.String()), nil
+		}
+		summary.Servers = []ServerOutput{}
+		for _, srv := range servers {
+			summary.Servers = append(summary.Servers, newServerOutput(srv))
+		}
+		summary.Volumes = []VolumeOutput{}
+		for _, vol := range volumes {
+			summary.Volumes = append(summary.Volumes, newVolumeOutput(vol))
+		}
+		summary.KubernetesClusters = []ClusterOutput{}
+		for _, cluster := range clusters {
+			summary.KubernetesClusters = append(summary.KubernetesClusters, newClusterOutput(cluster))
+		}
+		summary.Databases = []DatabaseOutput{}
+		for _, db := range databases {
+			summary.Databases = append(summary.Databases, newDatabaseOutput(db, nil))
+		}
+		summary.Repositories = []RepositoryOutput{}
+		for _, repo := range repos {
+			summary.Repositories = append(summary.Repositories, newRepositoryOutput(repo))
+		}
+		summary.CDNDomains = []CDNDomainOutput{}
+		if cdnDomains != nil {
+			for i := range cdnDomains.Domains {
+				summary.CDNDomains = append(summary.CDNDomains, newCDNDomainOutput(&cdnDomains.Domains[i]))
+			}
+		}
+		summary.Snapshots = []SnapshotOutput{}
+		for _, snap := range snapshots {
+			summary.Snapshots = append(summary.Snapshots, newSnapshotOutput(snap))
+		}
+		return jsonResult(summary), nil
 	})
 }
 
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// resourceScheme prefixes the URI of every resource the server exposes
+const resourceScheme = "bizflycloud://"
+
+// resourceMIMEType is the type of every resource's contents, which follow the
+// JSON schemas of the matching list_* and get_* tools
+const resourceMIMEType = "application/json"
+
+// cloudResource exposes one kind of Bizfly Cloud resource as an MCP resource
+// listing all of them and a resource template reading one by ID
+type cloudResource struct {
+	// service is the service group whose tools the resource mirrors
+	service string
+	// path follows the scheme in the URIs, e.g. dns/zones
+	path string
+	// idVar names the ID in the URI template
+	idVar string
+	// kind is the singular name used in descriptions and errors
+	kind string
+	// key holds the items in the list, as in the list tool's JSON
+	key string
+	// listTool and getTool are the tools whose JSON output the resources return
+	listTool, getTool string
+	list              func(ctx context.Context, services *Services) (interface{}, error)
+	get               func(ctx context.Context, services *Services, id string) (interface{}, error)
+}
+
+// cloudResources are the resources clients can attach as context
+var cloudResources = []cloudResource{
+	{
+		service: serviceServer, path: "servers", idVar: "id", kind: "server", key: "servers",
+		listTool: "bizflycloud_list_servers", getTool: "bizflycloud_get_server",
+		list: func(ctx context.Context, services *Services) (interface{}, error) {
+			servers, err := services.Servers.List(ctx, &gobizfly.ServerListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			items := []ServerOutput{}
+			for _, server := range servers {
+				items = append(items, newServerOutput(server))
+			}
+			return items, nil
+		},
+		get: func(ctx context.Context, services *Services, id string) (interface{}, error) {
+			server, err := services.Servers.Get(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return newServerOutput(server), nil
+		},
+	},
+	{
+		service: serviceVolume, path: "volumes", idVar: "id", kind: "volume", key: "volumes",
+		listTool: "bizflycloud_list_volumes", getTool: "bizflycloud_get_volume",
+		list: func(ctx context.Context, services *Services) (interface{}, error) {
+			volumes, err := services.Volumes.List(ctx, &gobizfly.VolumeListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			items := []VolumeOutput{}
+			for _, volume := range volumes {
+				items = append(items, newVolumeOutput(volume))
+			}
+			return items, nil
+		},
+		get: func(ctx context.Context, services *Services, id string) (interface{}, error) {
+			volume, err := services.Volumes.Get(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return newVolumeOutput(volume), nil
+		},
+	},
+	{
+		service: serviceKubernetes, path: "k8s", idVar: "cluster_id", kind: "Kubernetes cluster", key: "clusters",
+		listTool: "bizflycloud_list_kubernetes_clusters", getTool: "bizflycloud_get_kubernetes_cluster",
+		list: func(ctx context.Context, services *Services) (interface{}, error) {
+			clusters, err := services.Kubernetes.List(ctx, &gobizfly.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			items := []ClusterOutput{}
+			for _, cluster := range clusters {
+				items = append(items, newClusterOutput(cluster))
+			}
+			return items, nil
+		},
+		get: func(ctx context.Context, services *Services, id string) (interface{}, error) {
+			cluster, err := services.Kubernetes.Get(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return newExtendedClusterOutput(&cluster.ExtendedCluster), nil
+		},
+	},
+	{
+		service: serviceDatabase, path: "databases", idVar: "id", kind: "database", key: "databases",
+		listTool: "bizflycloud_list_databases", getTool: "bizflycloud_get_database",
+		list: func(ctx context.Context, services *Services) (interface{}, error) {
+			items := []DatabaseOutput{}
+			databases, err := services.Databases.ListInstances(ctx, &gobizfly.CloudDatabaseListOption{})
+			if err != nil {
+				// As with list_databases, an account without the database service has no databases
+				if isServiceUnavailable(ctx, err) {
+					return items, nil
+				}
+				return nil, err
+			}
+			for _, db := range databases {
+				if db != nil {
+					items = append(items, newDatabaseOutput(db, nil))
+				}
+			}
+			return items, nil
+		},
+		get: func(ctx context.Context, services *Services, id string) (interface{}, error) {
+			db, err := services.Databases.GetInstance(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			// Like get_database, fall back to the nodes of the instance when they can't be listed
+			nodes, _ := services.Databases.ListNodes(ctx, id, &gobizfly.CloudDatabaseListOption{})
+			return newDatabaseOutput(db, nodes), nil
+		},
+	},
+	{
+		service: serviceDNS, path: "dns/zones", idVar: "id", kind: "DNS zone", key: "zones",
+		listTool: "bizflycloud_list_dns_zones", getTool: "bizflycloud_get_dns_zone",
+		list: func(ctx context.Context, services *Services) (interface{}, error) {
+			items := []DNSZoneOutput{}
+			zones, err := services.DNS.ListZones(ctx, &gobizfly.ListOptions{})
+			if err != nil {
+				// As with list_dns_zones, an account without the DNS service has no zones
+				if isServiceUnavailable(ctx, err) {
+					return items, nil
+				}
+				return nil, err
+			}
+			if zones != nil {
+				for i := range zones.Zones {
+					items = append(items, newDNSZoneOutput(&zones.Zones[i]))
+				}
+			}
+			return items, nil
+		},
+		get: func(ctx context.Context, services *Services, id string) (interface{}, error) {
+			zone, err := services.DNS.GetZone(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return newExtendedDNSZoneOutput(zone), nil
+		},
+	},
+}
+
+// listURI returns the URI of the resource listing every resource of the kind
+func (r cloudResource) listURI() string {
+	return resourceScheme + r.path
+}
+
+// uriTemplate returns the URI template of a single resource of the kind
+func (r cloudResource) uriTemplate() string {
+	return fmt.Sprintf("%s%s/{%s}", resourceScheme, r.path, r.idVar)
+}
+
+// registerResources registers the resources of every enabled service group.
+// Resource reads don't go through the tool middleware, so they always use
+// the default profile and region.
+func registerResources(s *server.MCPServer, client *gobizfly.Client, filter *ToolFilter) {
+	for _, resource := range cloudResources {
+		if !filter.ServiceEnabled(resource.service) {
+			continue
+		}
+		resource := resource
+		s.AddResource(mcp.NewResource(resource.listURI(), "All "+resource.key,
+			mcp.WithResourceDescription(fmt.Sprintf("Every %s in the default profile and region, as %s returns them in JSON", resource.kind, resource.listTool)),
+			mcp.WithMIMEType(resourceMIMEType),
+		), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			ctx = withAPIStatus(ctx)
+			items, err := resource.list(ctx, servicesFromContext(ctx, client))
+			if err != nil {
+				return nil, resourceError(ctx, fmt.Sprintf("Failed to list %s", resource.key), err)
+			}
+			count := sliceLen(items)
+			return resourceContents(request.Params.URI, map[string]interface{}{
+				resource.key:  items,
+				"count":       count,
+				"total":       count,
+				"next_cursor": "",
+			})
+		})
+
+		s.AddResourceTemplate(mcp.NewResourceTemplate(resource.uriTemplate(), resource.kind,
+			mcp.WithTemplateDescription(fmt.Sprintf("A %s by ID, as %s returns it in JSON", resource.kind, resource.getTool)),
+			mcp.WithTemplateMIMEType(resourceMIMEType),
+		), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			ctx = withAPIStatus(ctx)
+			id := templateArg(request, resource.idVar)
+			if id == "" {
+				return nil, resourceError(ctx, fmt.Sprintf("Failed to read %s", request.Params.URI), invalidArgument(fmt.Errorf("the URI names no %s", resource.idVar)))
+			}
+			data, err := resource.get(ctx, servicesFromContext(ctx, client), id)
+			if err != nil {
+				return nil, resourceError(ctx, fmt.Sprintf("Failed to get %s %s", resource.kind, id), err)
+			}
+			return resourceContents(request.Params.URI, data)
+		})
+	}
+}
+
+// templateArg returns a variable the URI template matched, or "" if it is missing
+func templateArg(request mcp.ReadResourceRequest, name string) string {
+	switch value := request.Params.Arguments[name].(type) {
+	case string:
+		return value
+	case []string:
+		if len(value) == 1 {
+			return value[0]
+		}
+	}
+	return ""
+}
+
+// resourceContents encodes data as the JSON contents of the resource at uri
+func resourceContents(uri string, data interface{}) ([]mcp.ResourceContents, error) {
+	text, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", uri, err)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      uri,
+		MIMEType: resourceMIMEType,
+		Text:     string(text),
+	}}, nil
+}
+
+// resourceError describes a failed resource read the way errorResult describes
+// a failed tool call; the protocol only carries the message of a read error
+func resourceError(ctx context.Context, action string, err error) error {
+	code := classifyError(ctx, err).Code
+	return fmt.Errorf("%s: %w (error code: %s)", action, err, code)
+}
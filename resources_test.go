@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// readResource reads a resource through the harness and returns its text
+func readResource(t *testing.T, h *mcpHarness, uri string) (string, error) {
+	t.Helper()
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = uri
+	result, err := h.ReadResource(h.context(t), request)
+	if err != nil {
+		return "", err
+	}
+	if len(result.Contents) != 1 {
+		t.Fatalf("Expected one content for %s, got %d", uri, len(result.Contents))
+	}
+	contents, ok := result.Contents[0].(mcp.TextResourceContents)
+	if !ok || contents.URI != uri || contents.MIMEType != resourceMIMEType {
+		t.Fatalf("Expected JSON text contents for %s, got %+v", uri, result.Contents[0])
+	}
+	return contents.Text, nil
+}
+
+func TestResourcesList(t *testing.T) {
+	_, s := newMockServer(t, testCloudState())
+	h := startHarness(t, s)
+
+	resources, err := h.ListResources(h.context(t), mcp.ListResourcesRequest{})
+	if err != nil {
+		t.Fatalf("resources/list failed: %v", err)
+	}
+	var uris []string
+	for _, resource := range resources.Resources {
+		uris = append(uris, resource.URI)
+	}
+	sort.Strings(uris)
+	want := "bizflycloud://databases bizflycloud://dns/zones bizflycloud://k8s bizflycloud://servers bizflycloud://volumes"
+	if got := strings.Join(uris, " "); got != want {
+		t.Errorf("Expected the resources %s, got %s", want, got)
+	}
+
+	templates, err := h.ListResourceTemplates(h.context(t), mcp.ListResourceTemplatesRequest{})
+	if err != nil {
+		t.Fatalf("resources/templates/list failed: %v", err)
+	}
+	var raw []string
+	for _, template := range templates.ResourceTemplates {
+		raw = append(raw, template.URITemplate.Raw())
+	}
+	sort.Strings(raw)
+	want = "bizflycloud://databases/{id} bizflycloud://dns/zones/{id} bizflycloud://k8s/{cluster_id} bizflycloud://servers/{id} bizflycloud://volumes/{id}"
+	if got := strings.Join(raw, " "); got != want {
+		t.Errorf("Expected the templates %s, got %s", want, got)
+	}
+
+	// Only the enabled service groups expose resources
+	filtered := server.NewMCPServer("BizflyCloud MCP Test", "1.0.0")
+	registerResources(filtered, nil, &ToolFilter{Services: []string{serviceVolume}})
+	resources, err = startHarness(t, filtered).ListResources(h.context(t), mcp.ListResourcesRequest{})
+	if err != nil {
+		t.Fatalf("resources/list failed: %v", err)
+	}
+	if len(resources.Resources) != 1 || resources.Resources[0].URI != "bizflycloud://volumes" {
+		t.Errorf("Expected only the volumes resource, got %+v", resources.Resources)
+	}
+}
+
+func TestReadResourceMatchesTools(t *testing.T) {
+	_, s := newMockServer(t, testCloudState())
+	h := startHarness(t, s)
+	zone := h.call(t, "bizflycloud_create_dns_zone", map[string]interface{}{"name": "example.com", "format": "json"})
+	var created DNSZoneOutput
+	if err := json.Unmarshal([]byte(getTextFromResult(zone)), &created); err != nil || created.ID == "" {
+		t.Fatalf("Expected the created zone, got %s", getTextFromResult(zone))
+	}
+
+	tests := []struct {
+		uri  string
+		tool string
+		args map[string]interface{}
+	}{
+		{"bizflycloud://servers", "bizflycloud_list_servers", nil},
+		{"bizflycloud://servers/srv-1", "bizflycloud_get_server", map[string]interface{}{"server_id": "srv-1"}},
+		{"bizflycloud://volumes", "bizflycloud_list_volumes", nil},
+		{"bizflycloud://volumes/vol-1", "bizflycloud_get_volume", map[string]interface{}{"volume_id": "vol-1"}},
+		{"bizflycloud://k8s", "bizflycloud_list_kubernetes_clusters", nil},
+		{"bizflycloud://k8s/cluster-1", "bizflycloud_get_kubernetes_cluster", map[string]interface{}{"cluster_id": "cluster-1"}},
+		{"bizflycloud://dns/zones", "bizflycloud_list_dns_zones", nil},
+		{"bizflycloud://dns/zones/" + created.ID, "bizflycloud_get_dns_zone", map[string]interface{}{"zone_id": created.ID}},
+	}
+	for _, tt := range tests {
+		text, err := readResource(t, h, tt.uri)
+		if err != nil {
+			t.Errorf("resources/read %s failed: %v", tt.uri, err)
+			continue
+		}
+		args := map[string]interface{}{"format": "json"}
+		for key, value := range tt.args {
+			args[key] = value
+		}
+		expected := getTextFromResult(h.call(t, tt.tool, args))
+		var got, want interface{}
+		if err := json.Unmarshal([]byte(text), &got); err != nil {
+			t.Errorf("%s: expected JSON, got %s", tt.uri, text)
+			continue
+		}
+		if err := json.Unmarshal([]byte(expected), &want); err != nil {
+			t.Fatalf("%s: expected JSON, got %s", tt.tool, expected)
+		}
+		if mustJSON(t, got) != mustJSON(t, want) {
+			t.Errorf("%s: expected the output of %s\n%s\ngot\n%s", tt.uri, tt.tool, expected, text)
+		}
+	}
+}
+
+func TestReadResourceErrors(t *testing.T) {
+	_, s := newMockServer(t, testCloudState())
+	h := startHarness(t, s)
+
+	tests := []struct {
+		uri      string
+		expected string
+	}{
+		{"bizflycloud://servers/missing", "Failed to get server missing"},
+		{"bizflycloud://servers/missing", "error code: " + string(ErrorNotFound)},
+		{"bizflycloud://buckets/b-1", "handler not found"},
+		{"bizflycloud://servers/srv-1/volumes", "handler not found"},
+	}
+	for _, tt := range tests {
+		if _, err := readResource(t, h, tt.uri); err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.uri, tt.expected, err)
+		}
+	}
+}
+
+// mustJSON re-encodes decoded JSON so documents can be compared regardless of layout
+func mustJSON(t *testing.T, value interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("Failed to encode %v: %v", value, err)
+	}
+	return string(data)
+}
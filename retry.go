@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRateLimit      = 10.0
+	defaultRateLimitBurst = 20
+
+	// maxRetryDelay caps the backoff between attempts; a Retry-After longer
+	// than this is not waited for and the response is returned as is
+	maxRetryDelay = 30 * time.Second
+)
+
+// RetryPolicy configures how the Bizfly API transport retries and rate limits requests
+type RetryPolicy struct {
+	// MaxRetries is how many times a failed request is retried; 0 disables retries
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry; it doubles with every attempt
+	BaseDelay time.Duration
+	// RateLimit is the sustained number of requests per second sent to each
+	// service, and RateLimitBurst how many may be sent at once; 0 disables rate limiting
+	RateLimit      float64
+	RateLimitBurst int
+}
+
+// RetryTransport retries transient Bizfly API failures with exponential backoff
+// and rate limits requests with a token bucket per service. GET and HEAD
+// requests are retried after network errors and 5xx responses; any request is
+// retried after a 429, which the API sends before doing anything. A
+// Retry-After header sets the delay before the next attempt. Requests of a
+// tool call carry its context, so its retries are counted in the audit log and
+// waiting ends when the call is cancelled.
+type RetryTransport struct {
+	policy RetryPolicy
+	next   http.RoundTripper
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryTransport creates a transport sending requests through next with the given policy
+func NewRetryTransport(policy RetryPolicy, next http.RoundTripper) *RetryTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &RetryTransport{
+		policy:  policy,
+		next:    next,
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+		sleep:   sleepContext,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(ctx, serviceKey(req)); err != nil {
+			return nil, err
+		}
+
+		try := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			try = req.Clone(ctx)
+			try.Body = body
+		}
+		resp, err := t.next.RoundTrip(try)
+
+		delay, retry := t.retryDelay(req, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+		if err != nil {
+			log.Printf("[INFO] Bizfly API request %s %s failed (%v), retrying in %s", req.Method, req.URL.Path, err, delay)
+		} else {
+			log.Printf("[INFO] Bizfly API returned %d for %s %s, retrying in %s", resp.StatusCode, req.Method, req.URL.Path, delay)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if counter := retryCounterFromContext(ctx); counter != nil {
+			counter.Add(1)
+		}
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDelay decides whether an attempt is retried and how long to wait first
+func (t *RetryTransport) retryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= t.policy.MaxRetries || req.Context().Err() != nil {
+		return 0, false
+	}
+	// A body that can't be replayed can't be sent again
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return 0, false
+	}
+
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead
+	switch {
+	case err != nil:
+		if !idempotent {
+			return 0, false
+		}
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode >= 500 && idempotent:
+	default:
+		return 0, false
+	}
+
+	delay := t.backoff(attempt)
+	if resp != nil {
+		if after, ok := parseRetryAfter(resp.Header.Get("Retry-After"), t.now()); ok {
+			if after > maxRetryDelay {
+				return 0, false
+			}
+			delay = after
+		}
+	}
+	return delay, true
+}
+
+// backoff returns the jittered exponential delay before retry attempt+1
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	delay := t.policy.BaseDelay << attempt
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	// Equal jitter keeps at least half the delay while spreading out concurrent retries
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// wait blocks until the rate limit of a service lets another request through
+func (t *RetryTransport) wait(ctx context.Context, service string) error {
+	if t.policy.RateLimit <= 0 {
+		return nil
+	}
+	t.mu.Lock()
+	bucket, ok := t.buckets[service]
+	if !ok {
+		bucket = newTokenBucket(t.policy.RateLimit, t.policy.RateLimitBurst, t.now())
+		t.buckets[service] = bucket
+	}
+	t.mu.Unlock()
+
+	for {
+		delay := bucket.take(t.now())
+		if delay <= 0 {
+			return nil
+		}
+		if err := t.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// tokenBucket is a token bucket rate limiter
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// take removes a token from the bucket, or returns how long until one is available
+func (b *tokenBucket) take(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// serviceKey identifies the service a request goes to: the host and the first
+// path segment, e.g. manage.bizflycloud.vn/iaas-cloud
+func serviceKey(req *http.Request) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/"), "/")
+	return req.URL.Host + "/" + segment
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if delay := at.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d, returning early with the context's error when it is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type retryCounterContextKey struct{}
+
+// withRetryCounter returns a context whose Bizfly API retries are counted
+func withRetryCounter(ctx context.Context) (context.Context, *atomic.Int64) {
+	counter := new(atomic.Int64)
+	return context.WithValue(ctx, retryCounterContextKey{}, counter), counter
+}
+
+// retryCounterFromContext returns the retry counter of a tool call, or nil
+func retryCounterFromContext(ctx context.Context) *atomic.Int64 {
+	counter, _ := ctx.Value(retryCounterContextKey{}).(*atomic.Int64)
+	return counter
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bizflycloud-mcp-server/internal/fakecloud"
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// flakyAPI answers each request with the next of its status codes, then 200
+type flakyAPI struct {
+	mu         sync.Mutex
+	statuses   []int
+	retryAfter string
+	requests   int
+}
+
+func (f *flakyAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	if len(f.statuses) == 0 {
+		w.Write([]byte(`{"ok": true}`))
+		return
+	}
+	status := f.statuses[0]
+	f.statuses = f.statuses[1:]
+	if f.retryAfter != "" {
+		w.Header().Set("Retry-After", f.retryAfter)
+	}
+	w.WriteHeader(status)
+}
+
+// newTestRetryTransport returns a transport that records its waits instead of sleeping
+func newTestRetryTransport(policy RetryPolicy) (*RetryTransport, *[]time.Duration) {
+	transport := NewRetryTransport(policy, http.DefaultTransport)
+	var waits []time.Duration
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return transport, &waits
+}
+
+func TestRetryTransport(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: 100 * time.Millisecond}
+
+	tests := []struct {
+		name           string
+		method         string
+		statuses       []int
+		retryAfter     string
+		expectStatus   int
+		expectRequests int
+	}{
+		{"retries GET after 5xx", http.MethodGet, []int{503, 502}, "", 200, 3},
+		{"gives up after the max retries", http.MethodGet, []int{500, 500, 500, 500, 500}, "", 500, 4},
+		{"does not retry POST after 5xx", http.MethodPost, []int{503}, "", 503, 1},
+		{"retries POST after 429", http.MethodPost, []int{429}, "0", 200, 2},
+		{"does not retry 4xx", http.MethodGet, []int{404}, "", 404, 1},
+		{"does not wait for a long Retry-After", http.MethodGet, []int{503}, "3600", 503, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &flakyAPI{statuses: tt.statuses, retryAfter: tt.retryAfter}
+			ts := httptest.NewServer(api)
+			defer ts.Close()
+
+			transport, _ := newTestRetryTransport(policy)
+			req, _ := http.NewRequest(tt.method, ts.URL+"/iaas-cloud/api/servers", strings.NewReader(`{"name": "web-1"}`))
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.expectStatus || api.requests != tt.expectRequests {
+				t.Errorf("Expected %d after %d requests, got %d after %d", tt.expectStatus, tt.expectRequests, resp.StatusCode, api.requests)
+			}
+		})
+	}
+}
+
+func TestRetryTransportDelays(t *testing.T) {
+	api := &flakyAPI{statuses: []int{503, 503}}
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	transport, waits := newTestRetryTransport(RetryPolicy{MaxRetries: 3, BaseDelay: 100 * time.Millisecond})
+	ctx, retries := withRetryCounter(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/iaas-cloud/api/servers", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if retries.Load() != 2 || len(*waits) != 2 {
+		t.Fatalf("Expected 2 counted retries, got %d with waits %v", retries.Load(), *waits)
+	}
+	// The backoff doubles, with equal jitter keeping at least half of it
+	for i, wait := range *waits {
+		base := 100 * time.Millisecond << i
+		if wait < base/2 || wait > base {
+			t.Errorf("Expected retry %d to wait between %s and %s, got %s", i+1, base/2, base, wait)
+		}
+	}
+
+	t.Run("honors Retry-After", func(t *testing.T) {
+		api := &flakyAPI{statuses: []int{503}, retryAfter: "2"}
+		ts := httptest.NewServer(api)
+		defer ts.Close()
+		transport, waits := newTestRetryTransport(RetryPolicy{MaxRetries: 3, BaseDelay: 100 * time.Millisecond})
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		if len(*waits) != 1 || (*waits)[0] != 2*time.Second {
+			t.Errorf("Expected a single 2s wait, got %v", *waits)
+		}
+	})
+}
+
+func TestRetryTransportRateLimit(t *testing.T) {
+	ts := httptest.NewServer(&flakyAPI{})
+	defer ts.Close()
+
+	transport, waits := newTestRetryTransport(RetryPolicy{RateLimit: 2, RateLimitBurst: 2})
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	transport.now = func() time.Time { return now }
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		now = now.Add(d)
+		return nil
+	}
+
+	for _, path := range []string{"/iaas-cloud/a", "/iaas-cloud/b", "/iaas-cloud/c", "/dns/a"} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+	// The third request to iaas-cloud exceeds the burst of 2 and waits for a token;
+	// dns has its own bucket
+	if len(*waits) != 1 || (*waits)[0] != 500*time.Millisecond {
+		t.Errorf("Expected one 500ms wait, got %v", *waits)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		delay, ok := parseRetryAfter(tt.value, now)
+		if delay != tt.expected || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %s, %v; expected %s, %v", tt.value, delay, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestAuditRecordsRetries(t *testing.T) {
+	var out bytes.Buffer
+	handler := NewAuditLogger(&out, nil).Middleware()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		retryCounterFromContext(ctx).Add(2)
+		return mcp.NewToolResultText("ok"), nil
+	})
+	handler(context.Background(), createTestMCPRequest("bizflycloud_list_servers", map[string]interface{}{}))
+
+	if record := auditRecords(t, out.Bytes())[0]; record.Retries != 2 {
+		t.Errorf("Expected 2 retries in the audit record, got %d", record.Retries)
+	}
+}
+
+func TestRetriesThroughGobizfly(t *testing.T) {
+	cloud := fakecloud.New(testCloudState())
+	transport := NewRetryTransport(RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond}, cloud)
+	pool := NewClientPool(NewMockConfig())
+	pool.SetTransport(transport)
+	client := newMockClient(t, pool)
+	var out bytes.Buffer
+	s := server.NewMCPServer("BizflyCloud MCP Test", "1.0.0",
+		server.WithToolHandlerMiddleware(NewAuditLogger(&out, nil).Middleware()),
+		server.WithToolHandlerMiddleware(pool.Middleware()),
+	)
+	registerTools(s, client, pool, nil)
+
+	cloud.InjectFailure(fakecloud.Failure{Method: http.MethodGet, Path: "/iaas-cloud/api/servers", Status: http.StatusServiceUnavailable, Times: 2})
+	if result := callTool(t, s, "bizflycloud_list_servers", nil); result.IsError {
+		t.Fatalf("Expected list_servers to succeed after the retries, got %s", getTextFromResult(result))
+	}
+	if record := auditRecords(t, out.Bytes())[0]; record.Retries != 2 {
+		t.Errorf("Expected 2 retries in the audit record, got %d", record.Retries)
+	}
+
+	// The backoff of a cancelled tool call stops waiting
+	transport.policy.BaseDelay = time.Minute
+	cloud.InjectFailure(fakecloud.Failure{Method: http.MethodGet, Path: "/iaas-cloud/api/servers", Status: http.StatusServiceUnavailable})
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), poolContextKey{}, pool), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := servicesFromContext(ctx, client).Servers.List(ctx, &gobizfly.ServerListOptions{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the cancelled call to fail with its deadline, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the backoff to stop with the call, it took %s", elapsed)
+	}
+}
@@ -0,0 +1,740 @@
+package main
+
+import (
+	"github.com/bizflycloud/gobizfly"
+)
+
+// The JSON schemas returned by tools called with format=json. Field names are
+// snake_case and stable: fields are only ever added, never renamed or removed.
+// Lists are always arrays, never null.
+
+// ServerOutput is the JSON schema of a server
+type ServerOutput struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Status           string   `json:"status"`
+	Flavor           string   `json:"flavor"`
+	Category         string   `json:"category"`
+	AvailabilityZone string   `json:"availability_zone"`
+	WANIPv4          []string `json:"wan_ipv4"`
+	LANIPs           []string `json:"lan_ips"`
+	VolumeIDs        []string `json:"volume_ids"`
+	CreatedAt        string   `json:"created_at"`
+	UpdatedAt        string   `json:"updated_at"`
+}
+
+// ServerCreationOutput is the JSON schema of a server creation request that was accepted
+type ServerCreationOutput struct {
+	Name             string   `json:"name"`
+	Flavor           string   `json:"flavor"`
+	OS               string   `json:"os"`
+	RootDiskGB       int      `json:"root_disk_gb"`
+	VolumeType       string   `json:"volume_type"`
+	AvailabilityZone string   `json:"availability_zone"`
+	TaskIDs          []string `json:"task_ids"`
+	// Set when the call waited for the server
+	ID      string      `json:"id,omitempty"`
+	Status  string      `json:"status,omitempty"`
+	WANIPv4 []string    `json:"wan_ipv4,omitempty"`
+	LANIPs  []string    `json:"lan_ips,omitempty"`
+	Wait    *WaitOutput `json:"wait,omitempty"`
+}
+
+// FlavorOutput is the JSON schema of a server flavor
+type FlavorOutput struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	VCPUs    int    `json:"vcpus"`
+	RAMMB    int    `json:"ram_mb"`
+	DiskGB   int    `json:"disk_gb"`
+	Category string `json:"category"`
+}
+
+// VolumeOutput is the JSON schema of a volume
+type VolumeOutput struct {
+	ID               string                   `json:"id"`
+	Name             string                   `json:"name"`
+	Status           string                   `json:"status"`
+	SizeGB           int                      `json:"size_gb"`
+	VolumeType       string                   `json:"volume_type"`
+	Category         string                   `json:"category"`
+	AvailabilityZone string                   `json:"availability_zone"`
+	Bootable         bool                     `json:"bootable"`
+	Attachments      []VolumeAttachmentOutput `json:"attachments"`
+	CreatedAt        string                   `json:"created_at"`
+	UpdatedAt        string                   `json:"updated_at"`
+}
+
+// VolumeAttachmentOutput is the JSON schema of a volume attached to a server
+type VolumeAttachmentOutput struct {
+	ServerID string `json:"server_id"`
+	Device   string `json:"device"`
+}
+
+// VolumeTypeOutput is the JSON schema of a volume type
+type VolumeTypeOutput struct {
+	Name    string `json:"name"`
+	SSD     bool   `json:"ssd"`
+	Default bool   `json:"default"`
+}
+
+// SnapshotOutput is the JSON schema of a volume snapshot
+type SnapshotOutput struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	VolumeID  string `json:"volume_id"`
+	SizeGB    int    `json:"size_gb"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ClusterOutput is the JSON schema of a Kubernetes cluster. Worker pools are
+// omitted when the cluster details couldn't be fetched.
+type ClusterOutput struct {
+	ID               string             `json:"id"`
+	Name             string             `json:"name"`
+	Status           string             `json:"status"`
+	ProvisionStatus  string             `json:"provision_status"`
+	Version          string             `json:"version"`
+	WorkerPoolsCount int                `json:"worker_pools_count"`
+	AutoUpgrade      bool               `json:"auto_upgrade"`
+	VPCNetworkID     string             `json:"vpc_network_id"`
+	WorkerPools      []WorkerPoolOutput `json:"worker_pools,omitempty"`
+	CreatedAt        string             `json:"created_at"`
+}
+
+// ClusterCreationOutput is the JSON schema of a created Kubernetes cluster. The
+// API endpoint, worker pool nodes and wait are only set when the call waited.
+type ClusterCreationOutput struct {
+	ClusterOutput
+	APIEndpoint string      `json:"api_endpoint,omitempty"`
+	Wait        *WaitOutput `json:"wait,omitempty"`
+}
+
+// WorkerPoolOutput is the JSON schema of a Kubernetes worker pool
+type WorkerPoolOutput struct {
+	ID                string            `json:"id"`
+	Name              string            `json:"name"`
+	Flavor            string            `json:"flavor"`
+	ProfileType       string            `json:"profile_type"`
+	VolumeType        string            `json:"volume_type"`
+	VolumeSizeGB      int               `json:"volume_size_gb"`
+	AvailabilityZone  string            `json:"availability_zone"`
+	DesiredSize       int               `json:"desired_size"`
+	EnableAutoScaling bool              `json:"enable_autoscaling"`
+	MinSize           int               `json:"min_size"`
+	MaxSize           int               `json:"max_size"`
+	Tags              []string          `json:"tags"`
+	Labels            map[string]string `json:"labels"`
+	NetworkPlan       string            `json:"network_plan"`
+	BillingPlan       string            `json:"billing_plan"`
+	Nodes             []PoolNodeOutput  `json:"nodes,omitempty"`
+}
+
+// PoolNodeOutput is the JSON schema of a node in a Kubernetes worker pool
+type PoolNodeOutput struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Status      string   `json:"status"`
+	IPAddresses []string `json:"ip_addresses"`
+}
+
+// DatabaseOutput is the JSON schema of a database instance
+type DatabaseOutput struct {
+	ID             string               `json:"id"`
+	Name           string               `json:"name"`
+	Status         string               `json:"status"`
+	DatastoreType  string               `json:"datastore_type"`
+	DatastoreID    string               `json:"datastore_id"`
+	InstanceType   string               `json:"instance_type"`
+	VolumeSizeGB   int                  `json:"volume_size_gb"`
+	VolumeUsedGB   float32              `json:"volume_used_gb"`
+	PublicAccess   bool                 `json:"public_access"`
+	EnableFailover bool                 `json:"enable_failover"`
+	Nodes          []DatabaseNodeOutput `json:"nodes"`
+	CreatedAt      string               `json:"created_at"`
+}
+
+// DatabaseCreationOutput is the JSON schema of a created database instance.
+// The endpoints and wait are only set when the call waited.
+type DatabaseCreationOutput struct {
+	DatabaseOutput
+	PrivateEndpoint string      `json:"private_endpoint,omitempty"`
+	PublicEndpoint  string      `json:"public_endpoint,omitempty"`
+	Wait            *WaitOutput `json:"wait,omitempty"`
+}
+
+// DatabaseNodeOutput is the JSON schema of a database node
+type DatabaseNodeOutput struct {
+	ID               string                  `json:"id"`
+	Name             string                  `json:"name"`
+	Status           string                  `json:"status"`
+	OperatingStatus  string                  `json:"operating_status"`
+	NodeType         string                  `json:"node_type"`
+	Role             string                  `json:"role"`
+	Flavor           string                  `json:"flavor"`
+	AvailabilityZone string                  `json:"availability_zone"`
+	EnableFailover   bool                    `json:"enable_failover"`
+	ReplicaOf        string                  `json:"replica_of"`
+	ReplicaIDs       []string                `json:"replica_ids"`
+	PrivateAddresses []DatabaseAddressOutput `json:"private_addresses"`
+	PublicAddresses  []DatabaseAddressOutput `json:"public_addresses"`
+	PrivateDNS       string                  `json:"private_dns"`
+	PublicDNS        string                  `json:"public_dns"`
+	SRVDNS           string                  `json:"srv_dns"`
+	Message          string                  `json:"message"`
+	CreatedAt        string                  `json:"created_at"`
+}
+
+// DatabaseAddressOutput is the JSON schema of a database node address
+type DatabaseAddressOutput struct {
+	IPAddress string `json:"ip_address"`
+	Port      int    `json:"port"`
+	Network   string `json:"network"`
+}
+
+// DatastoreOutput is the JSON schema of a database engine and its versions
+type DatastoreOutput struct {
+	ID       string                   `json:"id"`
+	Name     string                   `json:"name"`
+	Versions []DatastoreVersionOutput `json:"versions"`
+}
+
+// DatastoreVersionOutput is the JSON schema of a database engine version
+type DatastoreVersionOutput struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// DatabaseBackupOutput is the JSON schema of a database backup
+type DatabaseBackupOutput struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Type      string  `json:"type"`
+	SizeGB    float32 `json:"size_gb"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// LoadBalancerOutput is the JSON schema of a load balancer
+type LoadBalancerOutput struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	Description        string `json:"description"`
+	ProvisioningStatus string `json:"provisioning_status"`
+	OperatingStatus    string `json:"operating_status"`
+	Type               string `json:"type"`
+	NetworkType        string `json:"network_type"`
+	VIPAddress         string `json:"vip_address"`
+	AdminStateUp       bool   `json:"admin_state_up"`
+	CreatedAt          string `json:"created_at"`
+	UpdatedAt          string `json:"updated_at"`
+}
+
+// DNSZoneOutput is the JSON schema of a DNS zone. Records are only included by get_dns_zone.
+type DNSZoneOutput struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Active      bool              `json:"active"`
+	TTL         int               `json:"ttl"`
+	NameServers []string          `json:"name_servers"`
+	Records     []DNSRecordOutput `json:"records,omitempty"`
+	CreatedAt   string            `json:"created_at"`
+	UpdatedAt   string            `json:"updated_at"`
+}
+
+// DNSRecordOutput is the JSON schema of a DNS record
+type DNSRecordOutput struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	TTL    int    `json:"ttl"`
+	ZoneID string `json:"zone_id"`
+}
+
+// CDNDomainOutput is the JSON schema of a CDN domain
+type CDNDomainOutput struct {
+	ID        string `json:"id"`
+	Domain    string `json:"domain"`
+	Slug      string `json:"slug"`
+	CDNDomain string `json:"cdn_domain"`
+	Message   string `json:"message,omitempty"`
+}
+
+// KMSCertificateOutput is the JSON schema of a KMS certificate container
+type KMSCertificateOutput struct {
+	ContainerID     string `json:"container_id"`
+	Name            string `json:"name"`
+	Certificate     string `json:"certificate,omitempty"`
+	CertificateHref string `json:"certificate_href,omitempty"`
+}
+
+// RepositoryOutput is the JSON schema of a container registry repository
+type RepositoryOutput struct {
+	Name      string                `json:"name"`
+	Public    bool                  `json:"public"`
+	Pulls     int                   `json:"pulls"`
+	LastPush  string                `json:"last_push"`
+	CreatedAt string                `json:"created_at"`
+	Tags      []RepositoryTagOutput `json:"tags,omitempty"`
+}
+
+// RepositoryTagOutput is the JSON schema of a container registry tag
+type RepositoryTagOutput struct {
+	Repository           string                `json:"repository"`
+	Name                 string                `json:"name"`
+	Author               string                `json:"author"`
+	ScanStatus           string                `json:"scan_status"`
+	VulnerabilitiesCount int                   `json:"vulnerabilities_count"`
+	FixesCount           int                   `json:"fixes_count"`
+	Vulnerabilities      []VulnerabilityOutput `json:"vulnerabilities,omitempty"`
+	CreatedAt            string                `json:"created_at"`
+	LastUpdated          string                `json:"last_updated"`
+}
+
+// VulnerabilityOutput is the JSON schema of a vulnerability found in an image
+type VulnerabilityOutput struct {
+	Name        string `json:"name"`
+	Package     string `json:"package"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+	FixedBy     string `json:"fixed_by"`
+}
+
+// AutoScalingGroupOutput is the JSON schema of an auto scaling group
+type AutoScalingGroupOutput struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Status          string   `json:"status"`
+	MinSize         int      `json:"min_size"`
+	MaxSize         int      `json:"max_size"`
+	DesiredCapacity int      `json:"desired_capacity"`
+	NodeIDs         []string `json:"node_ids"`
+	ProfileID       string   `json:"profile_id"`
+	ProfileName     string   `json:"profile_name"`
+	CreatedAt       string   `json:"created_at"`
+	UpdatedAt       string   `json:"updated_at"`
+}
+
+// AlarmOutput is the JSON schema of an alarm
+type AlarmOutput struct {
+	ID            string                `json:"id"`
+	Name          string                `json:"name"`
+	ResourceType  string                `json:"resource_type"`
+	Enabled       bool                  `json:"enabled"`
+	AlertInterval int                   `json:"alert_interval"`
+	Receivers     []AlarmReceiverOutput `json:"receivers"`
+	CreatedAt     string                `json:"created_at"`
+}
+
+// AlarmReceiverOutput is the JSON schema of a receiver an alarm notifies
+type AlarmReceiverOutput struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ReceiverOutput is the JSON schema of an alert receiver. Type is email,
+// webhook, sms, telegram or an empty string.
+type ReceiverOutput struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Target    string `json:"target"`
+	Verified  bool   `json:"verified"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ResourceSummaryOutput is the JSON schema of list_all_resources. Errors maps
+// a resource section such as "servers" to the error that kept it empty.
+type ResourceSummaryOutput struct {
+	Servers            []ServerOutput           `json:"servers"`
+	Volumes            []VolumeOutput           `json:"volumes"`
+	KubernetesClusters []ClusterOutput          `json:"kubernetes_clusters"`
+	Databases          []DatabaseOutput         `json:"databases"`
+	Repositories       []RepositoryOutput       `json:"repositories"`
+	CDNDomains         []CDNDomainOutput        `json:"cdn_domains"`
+	KMSCertificates    []KMSCertificateOutput   `json:"kms_certificates"`
+	AutoScalingGroups  []AutoScalingGroupOutput `json:"autoscaling_groups"`
+	Snapshots          []SnapshotOutput         `json:"snapshots"`
+	Errors             map[string]string        `json:"errors,omitempty"`
+}
+
+// RegionOutput is the JSON schema of a region a profile can target
+type RegionOutput struct {
+	Name      string   `json:"name"`
+	Default   bool     `json:"default"`
+	Connected bool     `json:"connected"`
+	Services  []string `json:"services"`
+}
+
+// ProfileOutput is the JSON schema of a configured account profile
+type ProfileOutput struct {
+	Name             string `json:"name"`
+	Default          bool   `json:"default"`
+	Region           string `json:"region"`
+	APIURL           string `json:"api_url"`
+	AvailabilityZone string `json:"availability_zone"`
+	AuthMethod       string `json:"auth_method"`
+	ProjectID        string `json:"project_id"`
+}
+
+func newServerOutput(server *gobizfly.Server) ServerOutput {
+	out := ServerOutput{
+		ID:               server.ID,
+		Name:             server.Name,
+		Status:           server.Status,
+		Flavor:           server.FlavorName,
+		Category:         server.Category,
+		AvailabilityZone: server.AvailabilityZone,
+		WANIPv4:          []string{},
+		LANIPs:           []string{},
+		VolumeIDs:        []string{},
+		CreatedAt:        server.CreatedAt,
+		UpdatedAt:        server.UpdatedAt,
+	}
+	for _, ip := range server.IPAddresses.WanV4Addresses {
+		out.WANIPv4 = append(out.WANIPv4, ip.Address)
+	}
+	for _, ip := range server.IPAddresses.LanAddresses {
+		out.LANIPs = append(out.LANIPs, ip.Address)
+	}
+	for _, volume := range server.AttachedVolumes {
+		out.VolumeIDs = append(out.VolumeIDs, volume.ID)
+	}
+	return out
+}
+
+func newVolumeOutput(volume *gobizfly.Volume) VolumeOutput {
+	out := VolumeOutput{
+		ID:               volume.ID,
+		Name:             volume.Name,
+		Status:           volume.Status,
+		SizeGB:           volume.Size,
+		VolumeType:       volume.VolumeType,
+		Category:         volume.Category,
+		AvailabilityZone: volume.AvailabilityZone,
+		Bootable:         volume.Bootable,
+		Attachments:      []VolumeAttachmentOutput{},
+		CreatedAt:        volume.CreatedAt,
+		UpdatedAt:        volume.UpdatedAt,
+	}
+	for _, attachment := range volume.Attachments {
+		out.Attachments = append(out.Attachments, VolumeAttachmentOutput{ServerID: attachment.ServerID, Device: attachment.Device})
+	}
+	return out
+}
+
+func newSnapshotOutput(snapshot *gobizfly.Snapshot) SnapshotOutput {
+	return SnapshotOutput{
+		ID:        snapshot.ID,
+		Name:      snapshot.Name,
+		Status:    snapshot.Status,
+		VolumeID:  snapshot.VolumeID,
+		SizeGB:    snapshot.Size,
+		CreatedAt: snapshot.CreateAt,
+	}
+}
+
+func newClusterOutput(cluster *gobizfly.Cluster) ClusterOutput {
+	return ClusterOutput{
+		ID:               cluster.UID,
+		Name:             cluster.Name,
+		Status:           cluster.ClusterStatus,
+		ProvisionStatus:  cluster.ProvisionStatus,
+		Version:          clusterVersion(cluster),
+		WorkerPoolsCount: cluster.WorkerPoolsCount,
+		AutoUpgrade:      cluster.AutoUpgrade,
+		VPCNetworkID:     cluster.VPCNetworkID,
+		CreatedAt:        cluster.CreatedAt,
+	}
+}
+
+// newExtendedClusterOutput includes the worker pools of a cluster
+func newExtendedClusterOutput(cluster *gobizfly.ExtendedCluster) ClusterOutput {
+	out := newClusterOutput(&cluster.Cluster)
+	out.WorkerPools = []WorkerPoolOutput{}
+	for i := range cluster.WorkerPools {
+		out.WorkerPools = append(out.WorkerPools, newWorkerPoolOutput(&cluster.WorkerPools[i]))
+	}
+	return out
+}
+
+// clusterVersion prefers the Kubernetes version over the controller version name
+func clusterVersion(cluster *gobizfly.Cluster) string {
+	if cluster.Version.K8SVersion != "" {
+		return cluster.Version.K8SVersion
+	}
+	return cluster.Version.Name
+}
+
+func newWorkerPoolOutput(pool *gobizfly.ExtendedWorkerPool) WorkerPoolOutput {
+	out := WorkerPoolOutput{
+		ID:                pool.UID,
+		Name:              pool.Name,
+		Flavor:            pool.Flavor,
+		ProfileType:       pool.ProfileType,
+		VolumeType:        pool.VolumeType,
+		VolumeSizeGB:      pool.VolumeSize,
+		AvailabilityZone:  pool.AvailabilityZone,
+		DesiredSize:       pool.DesiredSize,
+		EnableAutoScaling: pool.EnableAutoScaling,
+		MinSize:           pool.MinSize,
+		MaxSize:           pool.MaxSize,
+		Tags:              append([]string{}, pool.Tags...),
+		Labels:            map[string]string{},
+		NetworkPlan:       pool.NetworkPlan,
+		BillingPlan:       pool.BillingPlan,
+	}
+	for key, value := range pool.Labels {
+		out.Labels[key] = value
+	}
+	return out
+}
+
+func newPoolNodeOutput(node gobizfly.PoolNode) PoolNodeOutput {
+	return PoolNodeOutput{
+		ID:          node.ID,
+		Name:        node.Name,
+		Status:      node.Status,
+		IPAddresses: append([]string{}, node.IPAddresses...),
+	}
+}
+
+// newDatabaseOutput converts an instance; nodes lists the detailed nodes when
+// they were fetched separately, otherwise the nodes embedded in the instance are used
+func newDatabaseOutput(db *gobizfly.CloudDatabaseInstance, nodes []*gobizfly.CloudDatabaseNode) DatabaseOutput {
+	out := DatabaseOutput{
+		ID:             db.ID,
+		Name:           db.Name,
+		Status:         db.Status,
+		DatastoreType:  db.Datastore.Type,
+		DatastoreID:    db.Datastore.ID,
+		InstanceType:   db.InstanceType,
+		VolumeSizeGB:   db.Volume.Size,
+		VolumeUsedGB:   db.Volume.Used,
+		PublicAccess:   db.PublicAccess,
+		EnableFailover: db.EnableFailover,
+		Nodes:          []DatabaseNodeOutput{},
+		CreatedAt:      db.CreatedAt,
+	}
+	if len(nodes) > 0 {
+		for _, node := range nodes {
+			out.Nodes = append(out.Nodes, newDatabaseNodeOutput(node))
+		}
+	} else {
+		for i := range db.Nodes {
+			out.Nodes = append(out.Nodes, newDatabaseNodeOutput(&db.Nodes[i]))
+		}
+	}
+	return out
+}
+
+func newDatabaseNodeOutput(node *gobizfly.CloudDatabaseNode) DatabaseNodeOutput {
+	out := DatabaseNodeOutput{
+		ID:               node.ID,
+		Name:             node.Name,
+		Status:           node.Status,
+		OperatingStatus:  node.OperatingStatus,
+		NodeType:         node.NodeType,
+		Role:             node.Role,
+		Flavor:           node.Flavor,
+		AvailabilityZone: node.AvailabilityZone,
+		EnableFailover:   node.EnableFailover,
+		ReplicaOf:        node.ReplicaOf,
+		ReplicaIDs:       []string{},
+		PrivateAddresses: newDatabaseAddressOutputs(node.Addresses.Private),
+		PublicAddresses:  newDatabaseAddressOutputs(node.Addresses.Public),
+		PrivateDNS:       node.DNS.Private,
+		PublicDNS:        node.DNS.Public,
+		SRVDNS:           node.DNS.SRV,
+		Message:          node.Message,
+		CreatedAt:        node.CreatedAt,
+	}
+	for _, replica := range node.Replicas {
+		out.ReplicaIDs = append(out.ReplicaIDs, replica.ID)
+	}
+	return out
+}
+
+func newDatabaseAddressOutputs(addresses []gobizfly.CloudDatabaseAddressesDetail) []DatabaseAddressOutput {
+	out := []DatabaseAddressOutput{}
+	for _, addr := range addresses {
+		out = append(out, DatabaseAddressOutput{IPAddress: addr.IPAddress, Port: addr.Port, Network: addr.Network})
+	}
+	return out
+}
+
+func newDatastoreOutput(engine *gobizfly.CloudDatabaseEngine) DatastoreOutput {
+	out := DatastoreOutput{ID: engine.ID, Name: engine.Name, Versions: []DatastoreVersionOutput{}}
+	for _, version := range engine.Versions {
+		out.Versions = append(out.Versions, DatastoreVersionOutput{ID: version.ID, Name: version.Name, Version: version.Version})
+	}
+	return out
+}
+
+func newDatabaseBackupOutput(backup *gobizfly.CloudDatabaseBackup) DatabaseBackupOutput {
+	return DatabaseBackupOutput{
+		ID:        backup.ID,
+		Name:      backup.Name,
+		Status:    backup.Status,
+		Type:      backup.Type,
+		SizeGB:    backup.Size,
+		CreatedAt: backup.Created,
+	}
+}
+
+func newLoadBalancerOutput(lb *gobizfly.LoadBalancer) LoadBalancerOutput {
+	return LoadBalancerOutput{
+		ID:                 lb.ID,
+		Name:               lb.Name,
+		Description:        lb.Description,
+		ProvisioningStatus: lb.ProvisioningStatus,
+		OperatingStatus:    lb.OperatingStatus,
+		Type:               lb.Type,
+		NetworkType:        lb.NetworkType,
+		VIPAddress:         lb.VipAddress,
+		AdminStateUp:       lb.AdminStateUp,
+		CreatedAt:          lb.CreatedAt,
+		UpdatedAt:          lb.UpdatedAt,
+	}
+}
+
+func newDNSZoneOutput(zone *gobizfly.Zone) DNSZoneOutput {
+	return DNSZoneOutput{
+		ID:          zone.ID,
+		Name:        zone.Name,
+		Active:      zone.Active,
+		TTL:         zone.TTL,
+		NameServers: append([]string{}, zone.NameServer...),
+		CreatedAt:   zone.CreatedAt,
+		UpdatedAt:   zone.UpdatedAt,
+	}
+}
+
+// newExtendedDNSZoneOutput includes the records of a zone
+func newExtendedDNSZoneOutput(zone *gobizfly.ExtendedZone) DNSZoneOutput {
+	out := newDNSZoneOutput(&zone.Zone)
+	out.Records = []DNSRecordOutput{}
+	for i := range zone.RecordsSet {
+		out.Records = append(out.Records, newDNSRecordOutput(&zone.RecordsSet[i]))
+	}
+	return out
+}
+
+func newDNSRecordOutput(record *gobizfly.Record) DNSRecordOutput {
+	return DNSRecordOutput{
+		ID:     record.ID,
+		Name:   record.Name,
+		Type:   record.Type,
+		TTL:    record.TTL,
+		ZoneID: record.ZoneID,
+	}
+}
+
+func newCDNDomainOutput(domain *gobizfly.Domain) CDNDomainOutput {
+	return CDNDomainOutput{
+		ID:        domain.DomainID,
+		Domain:    domain.Domain,
+		Slug:      domain.Slug,
+		CDNDomain: domain.DomainCDN,
+	}
+}
+
+func newRepositoryOutput(repo *gobizfly.Repository) RepositoryOutput {
+	return RepositoryOutput{
+		Name:      repo.Name,
+		Public:    repo.Public,
+		Pulls:     repo.Pulls,
+		LastPush:  repo.LastPush,
+		CreatedAt: repo.CreatedAt,
+	}
+}
+
+func newRepositoryTagOutput(repository string, tag *gobizfly.RepositoryTag) RepositoryTagOutput {
+	return RepositoryTagOutput{
+		Repository:           repository,
+		Name:                 tag.Name,
+		Author:               tag.Author,
+		ScanStatus:           tag.ScanStatus,
+		VulnerabilitiesCount: tag.Vulnerabilities,
+		FixesCount:           tag.Fixes,
+		CreatedAt:            tag.CreatedAt,
+		LastUpdated:          tag.LastUpdated,
+	}
+}
+
+func newAutoScalingGroupOutput(group *gobizfly.AutoScalingGroup) AutoScalingGroupOutput {
+	return AutoScalingGroupOutput{
+		ID:              group.ID,
+		Name:            group.Name,
+		Status:          group.Status,
+		MinSize:         group.MinSize,
+		MaxSize:         group.MaxSize,
+		DesiredCapacity: group.DesiredCapacity,
+		NodeIDs:         append([]string{}, group.NodeIDs...),
+		ProfileID:       group.ProfileID,
+		ProfileName:     group.ProfileName,
+		CreatedAt:       group.Created,
+		UpdatedAt:       group.Updated,
+	}
+}
+
+func newAlarmOutput(alarm *gobizfly.Alarms) AlarmOutput {
+	out := AlarmOutput{
+		ID:            alarm.ID,
+		Name:          alarm.Name,
+		ResourceType:  alarm.ResourceType,
+		Enabled:       alarm.Enable,
+		AlertInterval: alarm.AlertInterval,
+		Receivers:     []AlarmReceiverOutput{},
+		CreatedAt:     alarm.Created,
+	}
+	for _, receiver := range alarm.Receivers {
+		out.Receivers = append(out.Receivers, AlarmReceiverOutput{ID: receiver.ReceiverID, Name: receiver.Name})
+	}
+	return out
+}
+
+func newReceiverOutput(receiver *gobizfly.Receivers) ReceiverOutput {
+	out := ReceiverOutput{ID: receiver.ReceiverID, Name: receiver.Name, CreatedAt: receiver.Created}
+	switch {
+	case receiver.EmailAddress != "":
+		out.Type, out.Target, out.Verified = "email", receiver.EmailAddress, receiver.VerifiedEmailDddress
+	case receiver.WebhookURL != "":
+		out.Type, out.Target, out.Verified = "webhook", receiver.WebhookURL, receiver.VerifiedWebhookURL
+	case receiver.SMSNumber != "":
+		out.Type, out.Target, out.Verified = "sms", receiver.SMSNumber, receiver.VerifiedSMSNumber
+	case receiver.TelegramChatID != "":
+		out.Type, out.Target, out.Verified = "telegram", receiver.TelegramChatID, receiver.VerifiedTelegramChatID
+	}
+	return out
+}
+
+// StatusWaitOutput is the JSON schema of bizflycloud_wait_for_status
+type StatusWaitOutput struct {
+	ResourceType   string   `json:"resource_type"`
+	ResourceID     string   `json:"resource_id"`
+	TargetStatuses []string `json:"target_statuses"`
+	WaitOutput
+}
+
+// TaskOutput is the JSON schema of an asynchronous operation the server tracks
+type TaskOutput struct {
+	ID           string `json:"id"`
+	Tool         string `json:"tool"`
+	Action       string `json:"action"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	ResourceName string `json:"resource_name,omitempty"`
+	APITaskID    string `json:"api_task_id,omitempty"`
+	Profile      string `json:"profile,omitempty"`
+	Region       string `json:"region,omitempty"`
+	StartedAt    string `json:"started_at"`
+	CheckedAt    string `json:"checked_at,omitempty"`
+	FinishedAt   string `json:"finished_at,omitempty"`
+	State        string `json:"state"`
+	Progress     int    `json:"progress,omitempty"`
+	Outcome      string `json:"outcome"`
+	Error        string `json:"error,omitempty"`
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -59,7 +60,8 @@ func main() {
 
 	// Set the token
 	client.SetKeystoneToken(token)
-	fmt.Println("✅ Authentication successful\n")
+	fmt.Println("✅ Authentication successful")
+	fmt.Println()
 
 	fmt.Println("=== Listing Kubernetes Clusters ===")
 	fmt.Println()
@@ -67,9 +69,8 @@ func main() {
 	// List clusters
 	clusters, err := client.KubernetesEngine.List(ctx, &gobizfly.ListOptions{})
 	if err != nil {
-		// Check if it's a 404 error
-		errStr := err.Error()
-		if containsAny(errStr, "404", "<svg", "Resource not found") {
+		// A 404 means the Kubernetes Engine endpoint doesn't exist for the account
+		if errors.Is(err, gobizfly.ErrNotFound) {
 			fmt.Println("❌ Error: Kubernetes Engine service may not be enabled or API endpoint not available")
 			fmt.Printf("   Details: %v\n", err)
 			fmt.Println()
@@ -162,17 +163,3 @@ func main() {
 	}
 	fmt.Println()
 }
-
-func containsAny(s string, substrings ...string) bool {
-	for _, substr := range substrings {
-		if len(s) >= len(substr) {
-			for i := 0; i <= len(s)-len(substr); i++ {
-				if s[i:i+len(substr)] == substr {
-					return true
-				}
-			}
-		}
-	}
-	return false
-}
-
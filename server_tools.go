@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bizflycloud/gobizfly"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -16,15 +17,25 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 	// List servers tool
 	listServersTool := mcp.NewTool("bizflycloud_list_servers",
 		mcp.WithDescription("List all Bizfly Cloud servers"),
+		withCommonOptions(),
+		withListOptions(),
 	)
 	s.AddTool(listServersTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		servers, err := client.CloudServer.List(ctx, &gobizfly.ServerListOptions{})
+		services := servicesFromContext(ctx, client)
+		query, err := parseListQuery(request.Params.Arguments)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list servers: %v", err)), nil
+			return errorResult(ctx, "Failed to list servers", err), nil
 		}
+		servers, err := services.Servers.List(ctx, &gobizfly.ServerListOptions{})
+		if err != nil {
+			return errorResult(ctx, "Failed to list servers", err), nil
+		}
+		servers, page := pageItems(query, servers, serverListFields)
 
 		result := "Available servers:\n\n"
+		items := []ServerOutput{}
 		for _, server := range servers {
+			items = append(items, newServerOutput(server))
 			result += fmt.Sprintf("Server: %s\n", server.Name)
 			result += fmt.Sprintf("  ID: %s\n", server.ID)
 			result += fmt.Sprintf("  Status: %s\n", server.Status)
@@ -40,72 +51,83 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 			result += fmt.Sprintf("  Updated At: %s\n", server.UpdatedAt)
 			result += "\n"
 		}
-		return mcp.NewToolResultText(result), nil
+		return pageResult(request, result, "servers", items, page), nil
 	})
 
 	// Reboot server tool
 	rebootServerTool := mcp.NewTool("bizflycloud_reboot_server",
 		mcp.WithDescription("Reboot a Bizfly Cloud server"),
+		withCommonOptions(),
 		mcp.WithString("server_id",
 			mcp.Required(),
 			mcp.Description("ID of the server to reboot"),
 		),
 	)
 	s.AddTool(rebootServerTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		serverID, ok := request.Params.Arguments["server_id"].(string)
 		if !ok {
 			return nil, errors.New("server_id must be a string")
 		}
-		_, err := client.CloudServer.SoftReboot(ctx, serverID)
+		_, err := services.Servers.SoftReboot(ctx, serverID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to reboot server: %v", err)), nil
+			return errorResult(ctx, "Failed to reboot server", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Server %s rebooted successfully", serverID)), nil
+		recordTask(ctx, services, TaskSpec{Action: "reboot", ResourceType: "server", ResourceID: serverID, Done: []string{"ACTIVE"}, From: startStatus(ctx, services, "server", serverID)})
+		return actionResult(request, fmt.Sprintf("Server %s rebooted successfully", serverID), ActionOutput{Action: "reboot", ResourceType: "server", ResourceID: serverID}), nil
 	})
 
 	// Delete server tool
 	deleteServerTool := mcp.NewTool("bizflycloud_delete_server",
 		mcp.WithDescription("Delete a Bizfly Cloud server"),
+		withCommonOptions(),
+		withConfirmation(),
 		mcp.WithString("server_id",
 			mcp.Required(),
 			mcp.Description("ID of the server to delete"),
 		),
 	)
 	s.AddTool(deleteServerTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		serverID, ok := request.Params.Arguments["server_id"].(string)
 		if !ok {
 			return nil, errors.New("server_id must be a string")
 		}
-		_, err := client.CloudServer.Delete(ctx, serverID, []string{})
+		task, err := services.Servers.Delete(ctx, serverID, []string{})
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete server: %v", err)), nil
+			return errorResult(ctx, "Failed to delete server", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Server %s deleted successfully", serverID)), nil
+		recordTask(ctx, services, TaskSpec{Action: "delete", ResourceType: "server", ResourceID: serverID, APITaskID: task.TaskID, Done: []string{statusDeleted}})
+		return actionResult(request, fmt.Sprintf("Server %s deleted successfully", serverID), ActionOutput{Action: "delete", ResourceType: "server", ResourceID: serverID}), nil
 	})
 
 	// Start server tool
 	startServerTool := mcp.NewTool("bizflycloud_start_server",
 		mcp.WithDescription("Start a Bizfly Cloud server"),
+		withCommonOptions(),
 		mcp.WithString("server_id",
 			mcp.Required(),
 			mcp.Description("ID of the server to start"),
 		),
 	)
 	s.AddTool(startServerTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		serverID, ok := request.Params.Arguments["server_id"].(string)
 		if !ok {
 			return nil, errors.New("server_id must be a string")
 		}
-		_, err := client.CloudServer.Start(ctx, serverID)
+		_, err := services.Servers.Start(ctx, serverID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to start server: %v", err)), nil
+			return errorResult(ctx, "Failed to start server", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Server %s started successfully", serverID)), nil
+		recordTask(ctx, services, TaskSpec{Action: "start", ResourceType: "server", ResourceID: serverID, Done: []string{"ACTIVE"}, From: startStatus(ctx, services, "server", serverID)})
+		return actionResult(request, fmt.Sprintf("Server %s started successfully", serverID), ActionOutput{Action: "start", ResourceType: "server", ResourceID: serverID}), nil
 	})
 
 	// Resize server tool
 	resizeServerTool := mcp.NewTool("bizflycloud_resize_server",
 		mcp.WithDescription("Resize a Bizfly Cloud server"),
+		withCommonOptions(),
 		mcp.WithString("server_id",
 			mcp.Required(),
 			mcp.Description("ID of the server to resize"),
@@ -116,6 +138,7 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(resizeServerTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		serverID, ok := request.Params.Arguments["server_id"].(string)
 		if !ok {
 			return nil, errors.New("server_id must be a string")
@@ -126,9 +149,9 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 		}
 
 		// Get the flavor ID from the name
-		flavors, err := client.CloudServer.Flavors().List(ctx)
+		flavors, err := catalogLookup(ctx, catalogFromContext(ctx), clientFromContext(ctx, client), catalogFlavors, services.Servers.ListFlavors)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get flavors: %v", err)), nil
+			return errorResult(ctx, "Failed to get flavors", err), nil
 		}
 
 		var flavorID string
@@ -142,25 +165,36 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 			return mcp.NewToolResultError(fmt.Sprintf("Flavor '%s' not found", flavorName)), nil
 		}
 
-		_, err = client.CloudServer.Resize(ctx, serverID, flavorID)
+		task, err := services.Servers.Resize(ctx, serverID, flavorID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to resize server: %v", err)), nil
+			return errorResult(ctx, "Failed to resize server", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Server %s resizing to flavor %s successfully", serverID, flavorName)), nil
+		recordTask(ctx, services, TaskSpec{Action: "resize", ResourceType: "server", ResourceID: serverID, APITaskID: task.TaskID, Done: []string{"ACTIVE"}})
+		return actionResult(request, fmt.Sprintf("Server %s resizing to flavor %s successfully", serverID, flavorName), ActionOutput{
+			Action: "resize", ResourceType: "server", ResourceID: serverID,
+			Details: map[string]string{"flavor": flavorName},
+		}), nil
 	})
 
 	// List flavors tool
 	listFlavorsTool := mcp.NewTool("bizflycloud_list_flavors",
 		mcp.WithDescription("List all available Bizfly Cloud server flavors"),
+		withCommonOptions(),
 	)
 	s.AddTool(listFlavorsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		flavors, err := client.CloudServer.Flavors().List(ctx)
+		services := servicesFromContext(ctx, client)
+		flavors, err := catalogLookup(ctx, catalogFromContext(ctx), clientFromContext(ctx, client), catalogFlavors, services.Servers.ListFlavors)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list flavors: %v", err)), nil
+			return errorResult(ctx, "Failed to list flavors", err), nil
 		}
 
 		result := "Available flavors:\n\n"
+		items := []FlavorOutput{}
 		for _, flavor := range flavors {
+			items = append(items, FlavorOutput{
+				ID: flavor.ID, Name: flavor.Name, VCPUs: flavor.VCPUs,
+				RAMMB: flavor.RAM, DiskGB: flavor.Disk, Category: flavor.Category,
+			})
 			result += fmt.Sprintf("Flavor: %s\n", flavor.Name)
 			result += fmt.Sprintf("  ID: %s\n", flavor.ID)
 			result += fmt.Sprintf("  vCPUs: %d\n", flavor.VCPUs)
@@ -169,25 +203,27 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 			result += fmt.Sprintf("  Category: %s\n", flavor.Category)
 			result += "\n"
 		}
-		return mcp.NewToolResultText(result), nil
+		return listResult(request, result, "flavors", items), nil
 	})
 
 	// Get server tool
 	getServerTool := mcp.NewTool("bizflycloud_get_server",
 		mcp.WithDescription("Get details of a Bizfly Cloud server"),
+		withCommonOptions(),
 		mcp.WithString("server_id",
 			mcp.Required(),
 			mcp.Description("ID of the server to get details for"),
 		),
 	)
 	s.AddTool(getServerTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		serverID, ok := request.Params.Arguments["server_id"].(string)
 		if !ok {
 			return nil, errors.New("server_id must be a string")
 		}
-		server, err := client.CloudServer.Get(ctx, serverID)
+		server, err := services.Servers.Get(ctx, serverID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get server: %v", err)), nil
+			return errorResult(ctx, "Failed to get server", err), nil
 		}
 
 		result := fmt.Sprintf("Server Details:\n\n")
@@ -210,52 +246,61 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 		}
 		result += fmt.Sprintf("Created At: %s\n", server.CreatedAt)
 		result += fmt.Sprintf("Updated At: %s\n", server.UpdatedAt)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newServerOutput(server)), nil
 	})
 
 	// Stop server tool
 	stopServerTool := mcp.NewTool("bizflycloud_stop_server",
 		mcp.WithDescription("Stop a Bizfly Cloud server"),
+		withCommonOptions(),
 		mcp.WithString("server_id",
 			mcp.Required(),
 			mcp.Description("ID of the server to stop"),
 		),
 	)
 	s.AddTool(stopServerTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		serverID, ok := request.Params.Arguments["server_id"].(string)
 		if !ok {
 			return nil, errors.New("server_id must be a string")
 		}
-		_, err := client.CloudServer.Stop(ctx, serverID)
+		_, err := services.Servers.Stop(ctx, serverID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to stop server: %v", err)), nil
+			return errorResult(ctx, "Failed to stop server", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Server %s stopped successfully", serverID)), nil
+		recordTask(ctx, services, TaskSpec{Action: "stop", ResourceType: "server", ResourceID: serverID, Done: []string{"SHUTOFF"}})
+		return actionResult(request, fmt.Sprintf("Server %s stopped successfully", serverID), ActionOutput{Action: "stop", ResourceType: "server", ResourceID: serverID}), nil
 	})
 
 	// Hard reboot server tool
 	hardRebootServerTool := mcp.NewTool("bizflycloud_hard_reboot_server",
 		mcp.WithDescription("Hard reboot a Bizfly Cloud server (force reboot)"),
+		withCommonOptions(),
 		mcp.WithString("server_id",
 			mcp.Required(),
 			mcp.Description("ID of the server to hard reboot"),
 		),
 	)
 	s.AddTool(hardRebootServerTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		serverID, ok := request.Params.Arguments["server_id"].(string)
 		if !ok {
 			return nil, errors.New("server_id must be a string")
 		}
-		_, err := client.CloudServer.HardReboot(ctx, serverID)
+		_, err := services.Servers.HardReboot(ctx, serverID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to hard reboot server: %v", err)), nil
+			return errorResult(ctx, "Failed to hard reboot server", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Server %s hard rebooted successfully", serverID)), nil
+		recordTask(ctx, services, TaskSpec{Action: "hard_reboot", ResourceType: "server", ResourceID: serverID, Done: []string{"ACTIVE"}, From: startStatus(ctx, services, "server", serverID)})
+		return actionResult(request, fmt.Sprintf("Server %s hard rebooted successfully", serverID), ActionOutput{Action: "hard_reboot", ResourceType: "server", ResourceID: serverID}), nil
 	})
 
 	// Create server tool - Create a server with customizable OS, flavor, disk size and volume type
 	createServerTool := mcp.NewTool("bizflycloud_create_server",
 		mcp.WithDescription("Create a new Bizfly Cloud server"),
+		withCommonOptions(),
+		withDryRun(),
+		withWait(),
 		mcp.WithString("name",
 			mcp.Required(),
 			mcp.Description("Name of the server"),
@@ -276,13 +321,14 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 			mcp.Description("Volume type for root disk (optional, defaults to SSD - PREMIUM-SSD1)"),
 		),
 		mcp.WithString("availability_zone",
-			mcp.Description("Availability zone (optional, defaults to HN1)"),
+			mcp.Description("Availability zone (optional, defaults to the profile's availability zone or HN1)"),
 		),
 		mcp.WithString("use_password",
 			mcp.Description("Set to 'true' to use password authentication (optional, defaults to SSH key)"),
 		),
 	)
 	s.AddTool(createServerTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		name, ok := request.Params.Arguments["name"].(string)
 		if !ok {
 			return nil, errors.New("name must be a string")
@@ -305,15 +351,15 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 			rootDiskSize = int(rds)
 		}
 
-		availabilityZone := "HN1"
+		availabilityZone := availabilityZoneFromContext(ctx, "HN1")
 		if zone, ok := request.Params.Arguments["availability_zone"].(string); ok && zone != "" {
 			availabilityZone = zone
 		}
 
 		// Verify flavor exists
-		flavors, err := client.CloudServer.Flavors().List(ctx)
+		flavors, err := catalogLookup(ctx, catalogFromContext(ctx), clientFromContext(ctx, client), catalogFlavors, services.Servers.ListFlavors)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get flavors: %v", err)), nil
+			return errorResult(ctx, "Failed to get flavors", err), nil
 		}
 
 		flavorFound := false
@@ -323,7 +369,8 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 				break
 			}
 		}
-		if !flavorFound {
+		// A dry run reports an unknown flavor among its failed checks
+		if !flavorFound && !isDryRun(request) {
 			return mcp.NewToolResultError(fmt.Sprintf("Flavor '%s' not found. Use bizflycloud_list_flavors to see available flavors", flavorName)), nil
 		}
 
@@ -333,7 +380,7 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 			imageID = imgID
 		} else {
 			// Try to find image from custom images first
-			customImages, err := client.CloudServer.CustomImages().List(ctx)
+			customImages, err := catalogLookup(ctx, catalogFromContext(ctx), clientFromContext(ctx, client), catalogCustomImages, services.Servers.ListCustomImages)
 			if err == nil && len(customImages) > 0 {
 				// Look for OS type in custom images
 				for _, img := range customImages {
@@ -346,9 +393,10 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 			
 			// If not found in custom images, try OS images
 			if imageID == "" {
-				images, err := client.CloudServer.OSImages().List(ctx)
-				if err != nil {
-					return mcp.NewToolResultError(fmt.Sprintf("Failed to get images: %v. Please provide image_id manually", err)), nil
+				images, err := catalogLookup(ctx, catalogFromContext(ctx), clientFromContext(ctx, client), catalogOSImages, services.Servers.ListOSImages)
+				// A dry run reports an unresolved image among its failed checks
+				if err != nil && !isDryRun(request) {
+					return errorResult(ctx, "Failed to get images", fmt.Errorf("%w. Please provide image_id manually", err)), nil
 				}
 
 				// Find image matching OS type
@@ -363,8 +411,8 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 				}
 			}
 			
-			if imageID == "" {
-				return mcp.NewToolResultError(fmt.Sprintf("%s image not found automatically. Please provide image_id parameter", strings.Title(osType))), nil
+			if imageID == "" && !isDryRun(request) {
+				return errorResult(ctx, "Failed to create server", invalidArgument(fmt.Errorf("%s image not found automatically. Please provide image_id parameter", strings.Title(osType)))), nil
 			}
 		}
 
@@ -409,20 +457,20 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 			volumeType = vt
 		} else {
 			// Try to find SSD volume type from existing volumes
-			volumes, err := client.CloudServer.Volumes().List(ctx, &gobizfly.VolumeListOptions{})
+			volumeTypes, err := catalogLookup(ctx, catalogFromContext(ctx), clientFromContext(ctx, client), catalogVolumeTypes, volumeTypesInUse(services.Volumes))
 			if err == nil {
 				// Look for SSD volume types in existing volumes (highest priority)
-				for _, vol := range volumes {
-					if strings.Contains(strings.ToUpper(vol.VolumeType), "SSD") {
-						volumeType = vol.VolumeType
+				for _, vType := range volumeTypes {
+					if strings.Contains(strings.ToUpper(vType), "SSD") {
+						volumeType = vType
 						break
 					}
 				}
 				// If no SSD found, try NVME (also fast storage, second priority)
 				if volumeType == "" {
-					for _, vol := range volumes {
-						if strings.Contains(strings.ToUpper(vol.VolumeType), "NVME") {
-							volumeType = vol.VolumeType
+					for _, vType := range volumeTypes {
+						if strings.Contains(strings.ToUpper(vType), "NVME") {
+							volumeType = vType
 							break
 						}
 					}
@@ -454,11 +502,21 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 			},
 			Password: usePassword,
 		}
+		timeout, err := waitTimeout(request)
+		if err != nil {
+			return errorResult(ctx, "Failed to create server", err), nil
+		}
+		if isDryRun(request) {
+			return dryRunResult(request, "bizflycloud_create_server", "", createReq, validateServerCreate(createReq, flavors)), nil
+		}
 
 		// Create the server
-		createResp, err := client.CloudServer.Create(ctx, createReq)
+		createResp, err := services.Servers.Create(ctx, createReq)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create server: %v", err)), nil
+			return errorResult(ctx, "Failed to create server", err), nil
+		}
+		for _, taskID := range createResp.Task {
+			recordTask(ctx, services, TaskSpec{Action: "create", ResourceType: "server", ResourceName: name, APITaskID: taskID, Done: []string{"ACTIVE"}})
 		}
 
 		result := fmt.Sprintf("Server creation initiated successfully:\n")
@@ -468,7 +526,136 @@ func RegisterServerTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += fmt.Sprintf("  Root Disk: %d GB (%s)\n", rootDiskSize, volumeType)
 		result += fmt.Sprintf("  Zone: %s\n", availabilityZone)
 		result += fmt.Sprintf("  Task IDs: %v\n", createResp.Task)
-		result += fmt.Sprintf("\nNote: Server is being created. Use bizflycloud_list_servers to check status.\n")
-		return mcp.NewToolResultText(result), nil
+		output := ServerCreationOutput{
+			Name:             name,
+			Flavor:           flavorName,
+			OS:               osType,
+			RootDiskGB:       rootDiskSize,
+			VolumeType:       volumeType,
+			AvailabilityZone: availabilityZone,
+			TaskIDs:          append([]string{}, createResp.Task...),
+		}
+		if timeout == 0 {
+			result += fmt.Sprintf("\nNote: Server is being created. Use bizflycloud_list_servers to check status.\n")
+			return formatResult(request, result, output), nil
+		}
+
+		server, wait, err := waitForServer(ctx, services, request, name, createResp.Task, timeout)
+		if err != nil {
+			return errorResult(ctx, "Server creation was initiated but waiting for it failed", err), nil
+		}
+		output.Wait = &wait
+		if server != nil {
+			details := newServerOutput(server)
+			output.ID, output.Status = details.ID, details.Status
+			output.WANIPv4, output.LANIPs = details.WANIPv4, details.LANIPs
+			result += fmt.Sprintf("  ID: %s\n", server.ID)
+			result += fmt.Sprintf("  Status: %s\n", server.Status)
+			for _, ip := range details.WANIPv4 {
+				result += fmt.Sprintf("  WAN IP: %s\n", ip)
+			}
+			for _, ip := range details.LANIPs {
+				result += fmt.Sprintf("  LAN IP: %s\n", ip)
+			}
+		}
+		result += describeWait("server", wait)
+		final := formatResult(request, result, output)
+		final.IsError = wait.Failed
+		return final, nil
 	})
-} 
\ No newline at end of file
+}
+
+// waitForServer follows the creation task to the new server and polls the
+// server until it is ACTIVE or ERROR. The server is nil if the task never
+// named it.
+func waitForServer(ctx context.Context, services *Services, request mcp.CallToolRequest, name string, taskIDs []string, timeout time.Duration) (*gobizfly.Server, WaitOutput, error) {
+	if len(taskIDs) == 0 {
+		return nil, WaitOutput{}, errors.New("the API returned no task to follow")
+	}
+	var server *gobizfly.Server
+	poll := statusPoll{
+		Kind:     "server",
+		Name:     name,
+		Ready:    []string{"ACTIVE"},
+		Failed:   []string{"ERROR"},
+		Timeout:  timeout,
+		Interval: waitPollInterval,
+		Get: func(ctx context.Context) (string, error) {
+			if server == nil {
+				task, err := services.Servers.GetTask(ctx, taskIDs[0])
+				if err != nil {
+					return "", err
+				}
+				if task.Ready && !task.Result.Success {
+					return "ERROR", nil
+				}
+				if task.Result.ID == "" {
+					return "BUILD", nil
+				}
+				server = &task.Result.Server
+			}
+			current, err := services.Servers.Get(ctx, server.ID)
+			if err != nil {
+				return "", err
+			}
+			server = current
+			return server.Status, nil
+		},
+	}
+	wait, err := poll.Run(ctx, newProgressReporter(ctx, request))
+	return server, wait, err
+}
+
+// previewServerDeletion describes the server and the volumes attached to it
+func previewServerDeletion(ctx context.Context, services *Services, args map[string]interface{}) (string, error) {
+	serverID, err := stringArg(args, "server_id")
+	if err != nil {
+		return "", err
+	}
+	server, err := services.Servers.Get(ctx, serverID)
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("Server: %s\n", server.Name)
+	result += fmt.Sprintf("  ID: %s\n", server.ID)
+	result += fmt.Sprintf("  Status: %s\n", server.Status)
+	result += fmt.Sprintf("  Flavor: %s\n", server.FlavorName)
+	result += fmt.Sprintf("  Zone: %s\n", server.AvailabilityZone)
+	if len(server.IPAddresses.WanV4Addresses) > 0 {
+		result += fmt.Sprintf("  WAN IP: %s\n", string(server.IPAddresses.WanV4Addresses[0].Address))
+	}
+	if len(server.AttachedVolumes) > 0 {
+		result += "  Attached Volumes:\n"
+		for _, volume := range server.AttachedVolumes {
+			result += fmt.Sprintf("    - %s (ID: %s, %d GB, %s)\n", volume.Name, volume.ID, volume.Size, volume.AttachedType)
+		}
+	}
+	result += fmt.Sprintf("  Created At: %s\n", server.CreatedAt)
+	return result, nil
+}
+
+// validateServerCreate checks a server create request before it is sent,
+// resolving its flavor against the flavors of the region
+func validateServerCreate(req *gobizfly.ServerCreateRequest, flavors []*ServerFlavor) *requestValidation {
+	flavorFound := false
+	for _, flavor := range flavors {
+		if flavor.Name == req.FlavorName {
+			flavorFound = true
+			break
+		}
+	}
+	v := &requestValidation{}
+	v.check(req.Name != "", "name is set")
+	v.check(flavorFound, fmt.Sprintf("flavor %q exists", req.FlavorName))
+	v.check(req.OS != nil && req.OS.ID != "", "image is resolved")
+	v.check(req.RootDisk != nil && req.RootDisk.Size > 0, "root disk size is positive")
+	v.check(req.RootDisk != nil && req.RootDisk.VolumeType != nil && *req.RootDisk.VolumeType != "", "root disk volume type is set")
+	v.check(req.AvailabilityZone != "", "availability zone is set")
+	return v
+}
+
+// serverListFields are the fields list_servers filters and sorts on
+func serverListFields(server *gobizfly.Server) listFields {
+	return listFields{Name: server.Name, Status: server.Status, CreatedAt: server.CreatedAt}
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"context"
+
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ServerService is the part of the cloud server API the server tools use
+type ServerService interface {
+	List(ctx context.Context, opts *gobizfly.ServerListOptions) ([]*gobizfly.Server, error)
+	Get(ctx context.Context, id string) (*gobizfly.Server, error)
+	Create(ctx context.Context, req *gobizfly.ServerCreateRequest) (*gobizfly.ServerCreateResponse, error)
+	GetTask(ctx context.Context, id string) (*gobizfly.ServerTaskResponse, error)
+	Delete(ctx context.Context, id string, deletedRootDisk []string) (*gobizfly.ServerTask, error)
+	Resize(ctx context.Context, id string, flavorID string) (*gobizfly.ServerTask, error)
+	Start(ctx context.Context, id string) (*gobizfly.Server, error)
+	Stop(ctx context.Context, id string) (*gobizfly.Server, error)
+	SoftReboot(ctx context.Context, id string) (*gobizfly.ServerMessageResponse, error)
+	HardReboot(ctx context.Context, id string) (*gobizfly.ServerMessageResponse, error)
+	ListFlavors(ctx context.Context) ([]*ServerFlavor, error)
+	ListOSImages(ctx context.Context) ([]OSImage, error)
+	ListCustomImages(ctx context.Context) ([]*gobizfly.CustomImage, error)
+}
+
+// VolumeService is the part of the cloud server API the volume tools use
+type VolumeService interface {
+	List(ctx context.Context, opts *gobizfly.VolumeListOptions) ([]*gobizfly.Volume, error)
+	Get(ctx context.Context, id string) (*gobizfly.Volume, error)
+	Create(ctx context.Context, req *gobizfly.VolumeCreateRequest) (*gobizfly.Volume, error)
+	Delete(ctx context.Context, id string) error
+	ExtendVolume(ctx context.Context, id string, newSize int) (*gobizfly.Task, error)
+	Attach(ctx context.Context, id string, serverID string) (*gobizfly.VolumeAttachDetachResponse, error)
+	Detach(ctx context.Context, id string, serverID string) (*gobizfly.VolumeAttachDetachResponse, error)
+}
+
+// SnapshotService is the part of the cloud server API the snapshot tools use
+type SnapshotService interface {
+	List(ctx context.Context, opts *gobizfly.ListSnasphotsOptions) ([]*gobizfly.Snapshot, error)
+	Get(ctx context.Context, id string) (*gobizfly.Snapshot, error)
+	Create(ctx context.Context, req *gobizfly.SnapshotCreateRequest) (*gobizfly.Snapshot, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// KubernetesService is the part of the Kubernetes Engine API the Kubernetes tools use
+type KubernetesService interface {
+	List(ctx context.Context, opts *gobizfly.ListOptions) ([]*gobizfly.Cluster, error)
+	Get(ctx context.Context, id string) (*gobizfly.FullCluster, error)
+	Create(ctx context.Context, req *gobizfly.ClusterCreateRequest) (*gobizfly.ExtendedCluster, error)
+	Delete(ctx context.Context, id string) error
+	GetKubeConfig(ctx context.Context, clusterUID string, opts *gobizfly.GetKubeConfigOptions) (string, error)
+	GetClusterWorkerPool(ctx context.Context, clusterUID string, poolID string) (*gobizfly.WorkerPoolWithNodes, error)
+	UpdateClusterWorkerPool(ctx context.Context, clusterUID string, poolID string, req *gobizfly.UpdateWorkerPoolRequest) error
+	DeleteClusterWorkerPool(ctx context.Context, clusterUID string, poolID string) error
+}
+
+// DatabaseService is the part of the cloud database API the database tools use
+type DatabaseService interface {
+	ListInstances(ctx context.Context, opts *gobizfly.CloudDatabaseListOption) ([]*gobizfly.CloudDatabaseInstance, error)
+	GetInstance(ctx context.Context, id string) (*gobizfly.CloudDatabaseInstance, error)
+	CreateInstance(ctx context.Context, req *gobizfly.CloudDatabaseInstanceCreate) (*gobizfly.CloudDatabaseInstance, error)
+	DeleteInstance(ctx context.Context, id string, req *gobizfly.CloudDatabaseDelete) (*gobizfly.CloudDatabaseMessageResponse, error)
+	ListNodes(ctx context.Context, instanceID string, opts *gobizfly.CloudDatabaseListOption) ([]*gobizfly.CloudDatabaseNode, error)
+	ListBackups(ctx context.Context, resource *gobizfly.CloudDatabaseBackupResource, opts *gobizfly.CloudDatabaseListOption) ([]*gobizfly.CloudDatabaseBackup, error)
+	CreateBackup(ctx context.Context, resourceType string, resourceID string, req *gobizfly.CloudDatabaseBackupCreate) (*gobizfly.CloudDatabaseBackup, error)
+	ListEngines(ctx context.Context) ([]*gobizfly.CloudDatabaseEngine, error)
+}
+
+// LoadBalancerService is the part of the load balancer API the load balancer tools use
+type LoadBalancerService interface {
+	List(ctx context.Context, opts *gobizfly.ListOptions) ([]*gobizfly.LoadBalancer, error)
+	Get(ctx context.Context, id string) (*gobizfly.LoadBalancer, error)
+	Create(ctx context.Context, req *gobizfly.LoadBalancerCreateRequest) (*gobizfly.LoadBalancer, error)
+	Update(ctx context.Context, id string, req *gobizfly.LoadBalancerUpdateRequest) (*gobizfly.LoadBalancer, error)
+	Delete(ctx context.Context, req *gobizfly.LoadBalancerDeleteRequest) error
+}
+
+// DNSService is the part of the DNS API the DNS tools use
+type DNSService interface {
+	ListZones(ctx context.Context, opts *gobizfly.ListOptions) (*gobizfly.ListZoneResp, error)
+	GetZone(ctx context.Context, zoneID string) (*gobizfly.ExtendedZone, error)
+	CreateZone(ctx context.Context, req *gobizfly.CreateZonePayload) (*gobizfly.ExtendedZone, error)
+	DeleteZone(ctx context.Context, zoneID string) error
+	GetRecord(ctx context.Context, recordID string) (*gobizfly.Record, error)
+	CreateRecord(ctx context.Context, zoneID string, req interface{}) (*gobizfly.Record, error)
+	DeleteRecord(ctx context.Context, recordID string) error
+}
+
+// CDNService is the part of the CDN API the CDN tools use
+type CDNService interface {
+	List(ctx context.Context, opts *gobizfly.ListOptions) (*gobizfly.DomainsResp, error)
+	Get(ctx context.Context, domainID string) (*gobizfly.Domain, error)
+	Create(ctx context.Context, req *gobizfly.CreateDomainPayload) (*gobizfly.CreateDomainResponse, error)
+	Update(ctx context.Context, domainID string, req *gobizfly.UpdateDomainPayload) (*gobizfly.UpdateDomainResp, error)
+	Delete(ctx context.Context, domainID string) error
+	DeleteCache(ctx context.Context, domainID string, files *gobizfly.Files) error
+}
+
+// KMSService is the part of the KMS API the KMS tools use
+type KMSService interface {
+	List(ctx context.Context) ([]*gobizfly.KMSCertificate, error)
+	Get(ctx context.Context, id string) (*gobizfly.KMSCertificateGetResponse, error)
+	Create(ctx context.Context, req *gobizfly.KMSCertificateContainerCreateRequest) (*gobizfly.KMSCertificateCreateResponse, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// ContainerRegistryService is the part of the container registry API the registry tools use
+type ContainerRegistryService interface {
+	List(ctx context.Context, opts *gobizfly.ListOptions) ([]*gobizfly.Repository, error)
+	Create(ctx context.Context, req *gobizfly.CreateRepositoryPayload) error
+	Delete(ctx context.Context, repositoryName string) error
+	EditRepo(ctx context.Context, repositoryName string, req *gobizfly.EditRepositoryPayload) error
+	GetTags(ctx context.Context, repositoryName string) (*gobizfly.TagRepository, error)
+	GetTag(ctx context.Context, repositoryName string, tagName string, vulnerabilities string) (*gobizfly.Image, error)
+	DeleteTag(ctx context.Context, repositoryName string, tagName string) error
+}
+
+// AutoScalingService is the part of the AutoScaling API the AutoScaling tools use
+type AutoScalingService interface {
+	List(ctx context.Context, all bool) ([]*gobizfly.AutoScalingGroup, error)
+	Get(ctx context.Context, id string) (*gobizfly.AutoScalingGroup, error)
+	Create(ctx context.Context, req *gobizfly.AutoScalingGroupCreateRequest) (*gobizfly.AutoScalingGroup, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// AlertService is the part of the CloudWatcher API the alert tools use
+type AlertService interface {
+	ListAlarms(ctx context.Context, filters *string) ([]*gobizfly.Alarms, error)
+	GetAlarm(ctx context.Context, id string) (*gobizfly.Alarms, error)
+	ListReceivers(ctx context.Context, filters *string) ([]*gobizfly.Receivers, error)
+	GetReceiver(ctx context.Context, id string) (*gobizfly.Receivers, error)
+}
+
+// ServerFlavor is a server flavor as the flavors API returns it
+type ServerFlavor struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	VCPUs    int    `json:"vcpus"`
+	RAM      int    `json:"ram"`
+	Disk     int    `json:"disk"`
+	Category string `json:"category"`
+}
+
+// OSImage is an OS distribution and its image versions
+type OSImage struct {
+	OSDistribution string           `json:"os"`
+	Version        []OSImageVersion `json:"versions"`
+}
+
+// OSImageVersion is one image of an OS distribution
+type OSImageVersion struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Services are the Bizfly Cloud APIs the tool handlers call. Handlers only
+// depend on these interfaces, so tests can replace any of them with fakes.
+type Services struct {
+	Servers       ServerService
+	Volumes       VolumeService
+	Snapshots     SnapshotService
+	Kubernetes    KubernetesService
+	Databases     DatabaseService
+	LoadBalancers LoadBalancerService
+	DNS           DNSService
+	CDN           CDNService
+	KMS           KMSService
+	Registry      ContainerRegistryService
+	AutoScaling   AutoScalingService
+	Alerts        AlertService
+}
+
+// NewServices returns the services backed by a gobizfly client
+func NewServices(client *gobizfly.Client) *Services {
+	return &Services{
+		Servers:       &gobizflyServers{client.CloudServer},
+		Volumes:       gobizflyVolumes{client.CloudServer},
+		Snapshots:     gobizflySnapshots{client.CloudServer},
+		Kubernetes:    client.KubernetesEngine,
+		Databases:     gobizflyDatabases{client.CloudDatabase},
+		LoadBalancers: client.CloudLoadBalancer,
+		DNS:           client.DNS,
+		CDN:           client.CDN,
+		KMS:           gobizflyKMS{client.KMS},
+		Registry:      client.ContainerRegistry,
+		AutoScaling:   gobizflyAutoScaling{client.AutoScaling},
+		Alerts:        gobizflyAlerts{client.CloudWatcher},
+	}
+}
+
+type servicesContextKey struct{}
+
+// servicesMiddleware makes every tool call use the given services instead of
+// the ones backed by the selected client
+func servicesMiddleware(services *Services) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return next(context.WithValue(ctx, servicesContextKey{}, services), request)
+		}
+	}
+}
+
+// servicesFromContext returns the services of the current tool call: the ones
+// set by servicesMiddleware, or those of the selected client or the fallback.
+// The pool's clients are bound to the call, so their requests carry ctx.
+// It returns nil when there is neither.
+func servicesFromContext(ctx context.Context, fallback *gobizfly.Client) *Services {
+	if services, ok := ctx.Value(servicesContextKey{}).(*Services); ok && services != nil {
+		return services
+	}
+	client := clientFromContext(ctx, fallback)
+	if client == nil {
+		return nil
+	}
+	if pool, ok := ctx.Value(poolContextKey{}).(*ClientPool); ok {
+		pool.bind(ctx)
+	}
+	return NewServices(client)
+}
+
+// gobizflyServers adapts the cloud server API, whose flavor and image types are unexported
+type gobizflyServers struct {
+	gobizfly.CloudServerService
+}
+
+func (s *gobizflyServers) ListFlavors(ctx context.Context) ([]*ServerFlavor, error) {
+	flavors, err := s.Flavors().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*ServerFlavor, 0, len(flavors))
+	for _, flavor := range flavors {
+		result = append(result, &ServerFlavor{
+			ID: flavor.ID, Name: flavor.Name, VCPUs: flavor.VCPUs,
+			RAM: flavor.RAM, Disk: flavor.Disk, Category: flavor.Category,
+		})
+	}
+	return result, nil
+}
+
+func (s *gobizflyServers) ListOSImages(ctx context.Context) ([]OSImage, error) {
+	images, err := s.OSImages().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]OSImage, 0, len(images))
+	for _, image := range images {
+		versions := make([]OSImageVersion, 0, len(image.Version))
+		for _, version := range image.Version {
+			versions = append(versions, OSImageVersion{ID: version.ID, Name: version.Name})
+		}
+		result = append(result, OSImage{OSDistribution: image.OSDistribution, Version: versions})
+	}
+	return result, nil
+}
+
+func (s *gobizflyServers) ListCustomImages(ctx context.Context) ([]*gobizfly.CustomImage, error) {
+	return s.CustomImages().List(ctx)
+}
+
+// gobizflyVolumes adapts the volumes of the cloud server API
+type gobizflyVolumes struct {
+	cloudServer gobizfly.CloudServerService
+}
+
+func (v gobizflyVolumes) List(ctx context.Context, opts *gobizfly.VolumeListOptions) ([]*gobizfly.Volume, error) {
+	return v.cloudServer.Volumes().List(ctx, opts)
+}
+
+func (v gobizflyVolumes) Get(ctx context.Context, id string) (*gobizfly.Volume, error) {
+	return v.cloudServer.Volumes().Get(ctx, id)
+}
+
+func (v gobizflyVolumes) Create(ctx context.Context, req *gobizfly.VolumeCreateRequest) (*gobizfly.Volume, error) {
+	return v.cloudServer.Volumes().Create(ctx, req)
+}
+
+func (v gobizflyVolumes) Delete(ctx context.Context, id string) error {
+	return v.cloudServer.Volumes().Delete(ctx, id)
+}
+
+func (v gobizflyVolumes) ExtendVolume(ctx context.Context, id string, newSize int) (*gobizfly.Task, error) {
+	return v.cloudServer.Volumes().ExtendVolume(ctx, id, newSize)
+}
+
+func (v gobizflyVolumes) Attach(ctx context.Context, id string, serverID string) (*gobizfly.VolumeAttachDetachResponse, error) {
+	return v.cloudServer.Volumes().Attach(ctx, id, serverID)
+}
+
+func (v gobizflyVolumes) Detach(ctx context.Context, id string, serverID string) (*gobizfly.VolumeAttachDetachResponse, error) {
+	return v.cloudServer.Volumes().Detach(ctx, id, serverID)
+}
+
+// gobizflySnapshots adapts the snapshots of the cloud server API
+type gobizflySnapshots struct {
+	cloudServer gobizfly.CloudServerService
+}
+
+func (s gobizflySnapshots) List(ctx context.Context, opts *gobizfly.ListSnasphotsOptions) ([]*gobizfly.Snapshot, error) {
+	return s.cloudServer.Snapshots().List(ctx, opts)
+}
+
+func (s gobizflySnapshots) Get(ctx context.Context, id string) (*gobizfly.Snapshot, error) {
+	return s.cloudServer.Snapshots().Get(ctx, id)
+}
+
+func (s gobizflySnapshots) Create(ctx context.Context, req *gobizfly.SnapshotCreateRequest) (*gobizfly.Snapshot, error) {
+	return s.cloudServer.Snapshots().Create(ctx, req)
+}
+
+func (s gobizflySnapshots) Delete(ctx context.Context, id string) error {
+	return s.cloudServer.Snapshots().Delete(ctx, id)
+}
+
+// gobizflyDatabases adapts the instances, backups and engines of the cloud database API
+type gobizflyDatabases struct {
+	cloudDatabase gobizfly.CloudDatabaseService
+}
+
+func (d gobizflyDatabases) ListInstances(ctx context.Context, opts *gobizfly.CloudDatabaseListOption) ([]*gobizfly.CloudDatabaseInstance, error) {
+	return d.cloudDatabase.Instances().List(ctx, opts)
+}
+
+func (d gobizflyDatabases) GetInstance(ctx context.Context, id string) (*gobizfly.CloudDatabaseInstance, error) {
+	return d.cloudDatabase.Instances().Get(ctx, id)
+}
+
+func (d gobizflyDatabases) CreateInstance(ctx context.Context, req *gobizfly.CloudDatabaseInstanceCreate) (*gobizfly.CloudDatabaseInstance, error) {
+	return d.cloudDatabase.Instances().Create(ctx, req)
+}
+
+func (d gobizflyDatabases) DeleteInstance(ctx context.Context, id string, req *gobizfly.CloudDatabaseDelete) (*gobizfly.CloudDatabaseMessageResponse, error) {
+	return d.cloudDatabase.Instances().Delete(ctx, id, req)
+}
+
+func (d gobizflyDatabases) ListNodes(ctx context.Context, instanceID string, opts *gobizfly.CloudDatabaseListOption) ([]*gobizfly.CloudDatabaseNode, error) {
+	return d.cloudDatabase.Instances().ListNodes(ctx, instanceID, opts)
+}
+
+func (d gobizflyDatabases) ListBackups(ctx context.Context, resource *gobizfly.CloudDatabaseBackupResource, opts *gobizfly.CloudDatabaseListOption) ([]*gobizfly.CloudDatabaseBackup, error) {
+	return d.cloudDatabase.Backups().List(ctx, resource, opts)
+}
+
+func (d gobizflyDatabases) CreateBackup(ctx context.Context, resourceType string, resourceID string, req *gobizfly.CloudDatabaseBackupCreate) (*gobizfly.CloudDatabaseBackup, error) {
+	return d.cloudDatabase.Backups().Create(ctx, resourceType, resourceID, req)
+}
+
+func (d gobizflyDatabases) ListEngines(ctx context.Context) ([]*gobizfly.CloudDatabaseEngine, error) {
+	return d.cloudDatabase.Engines().List(ctx)
+}
+
+// gobizflyKMS adapts the certificates of the KMS API
+type gobizflyKMS struct {
+	kms gobizfly.KMSService
+}
+
+func (k gobizflyKMS) List(ctx context.Context) ([]*gobizfly.KMSCertificate, error) {
+	return k.kms.Certificates().List(ctx)
+}
+
+func (k gobizflyKMS) Get(ctx context.Context, id string) (*gobizfly.KMSCertificateGetResponse, error) {
+	return k.kms.Certificates().Get(ctx, id)
+}
+
+func (k gobizflyKMS) Create(ctx context.Context, req *gobizfly.KMSCertificateContainerCreateRequest) (*gobizfly.KMSCertificateCreateResponse, error) {
+	return k.kms.Certificates().Create(ctx, req)
+}
+
+func (k gobizflyKMS) Delete(ctx context.Context, id string) error {
+	return k.kms.Certificates().Delete(ctx, id)
+}
+
+// gobizflyAutoScaling adapts the groups of the AutoScaling API
+type gobizflyAutoScaling struct {
+	autoScaling gobizfly.AutoScalingService
+}
+
+func (a gobizflyAutoScaling) List(ctx context.Context, all bool) ([]*gobizfly.AutoScalingGroup, error) {
+	return a.autoScaling.AutoScalingGroups().List(ctx, all)
+}
+
+func (a gobizflyAutoScaling) Get(ctx context.Context, id string) (*gobizfly.AutoScalingGroup, error) {
+	return a.autoScaling.AutoScalingGroups().Get(ctx, id)
+}
+
+func (a gobizflyAutoScaling) Create(ctx context.Context, req *gobizfly.AutoScalingGroupCreateRequest) (*gobizfly.AutoScalingGroup, error) {
+	return a.autoScaling.AutoScalingGroups().Create(ctx, req)
+}
+
+func (a gobizflyAutoScaling) Delete(ctx context.Context, id string) error {
+	return a.autoScaling.AutoScalingGroups().Delete(ctx, id)
+}
+
+// gobizflyAlerts adapts the alarms and receivers of the CloudWatcher API
+type gobizflyAlerts struct {
+	cloudWatcher gobizfly.CloudWatcherService
+}
+
+func (a gobizflyAlerts) ListAlarms(ctx context.Context, filters *string) ([]*gobizfly.Alarms, error) {
+	return a.cloudWatcher.Alarms().List(ctx, filters)
+}
+
+func (a gobizflyAlerts) GetAlarm(ctx context.Context, id string) (*gobizfly.Alarms, error) {
+	return a.cloudWatcher.Alarms().Get(ctx, id)
+}
+
+func (a gobizflyAlerts) ListReceivers(ctx context.Context, filters *string) ([]*gobizfly.Receivers, error) {
+	return a.cloudWatcher.Receivers().List(ctx, filters)
+}
+
+func (a gobizflyAlerts) GetReceiver(ctx context.Context, id string) (*gobizfly.Receivers, error) {
+	return a.cloudWatcher.Receivers().Get(ctx, id)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fakeServers is an in-memory ServerService. Methods it does not override panic.
+type fakeServers struct {
+	ServerService
+	servers map[string]*gobizfly.Server
+	flavors []*ServerFlavor
+	resized map[string]string
+}
+
+func (f *fakeServers) List(ctx context.Context, opts *gobizfly.ServerListOptions) ([]*gobizfly.Server, error) {
+	servers := []*gobizfly.Server{}
+	for _, server := range f.servers {
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+func (f *fakeServers) Get(ctx context.Context, id string) (*gobizfly.Server, error) {
+	server, ok := f.servers[id]
+	if !ok {
+		return nil, fmt.Errorf("server %s: %w", id, gobizfly.ErrNotFound)
+	}
+	return server, nil
+}
+
+func (f *fakeServers) Resize(ctx context.Context, id string, flavorID string) (*gobizfly.ServerTask, error) {
+	f.resized[id] = flavorID
+	return &gobizfly.ServerTask{TaskID: "task-1"}, nil
+}
+
+func (f *fakeServers) ListFlavors(ctx context.Context) ([]*ServerFlavor, error) {
+	return f.flavors, nil
+}
+
+// fakeVolumes is an in-memory VolumeService. Methods it does not override panic.
+type fakeVolumes struct {
+	VolumeService
+	volumes map[string]*gobizfly.Volume
+	deleted []string
+}
+
+func (f *fakeVolumes) Get(ctx context.Context, id string) (*gobizfly.Volume, error) {
+	volume, ok := f.volumes[id]
+	if !ok {
+		return nil, fmt.Errorf("volume %s: %w", id, gobizfly.ErrNotFound)
+	}
+	return volume, nil
+}
+
+func (f *fakeVolumes) Delete(ctx context.Context, id string) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func newFakeServices() *Services {
+	return &Services{
+		Servers: &fakeServers{
+			servers: map[string]*gobizfly.Server{
+				"server-123": {ID: "server-123", Name: "web-1", Status: "ACTIVE", FlavorName: "2c_4g"},
+			},
+			flavors: []*ServerFlavor{
+				{ID: "flavor-2c4g", Name: "2c_4g", VCPUs: 2, RAM: 4096},
+				{ID: "flavor-4c8g", Name: "4c_8g", VCPUs: 4, RAM: 8192},
+			},
+			resized: map[string]string{},
+		},
+		Volumes: &fakeVolumes{
+			volumes: map[string]*gobizfly.Volume{
+				"volume-123": {ID: "volume-123", Name: "data-1", Size: 50, VolumeType: "SSD",
+					Attachments: []gobizfly.VolumeAttachment{{ServerID: "server-123", Device: "/dev/vdb"}}},
+			},
+		},
+	}
+}
+
+// newFakeServicesServer returns a server whose tools call the given services
+func newFakeServicesServer(services *Services, middleware ...server.ToolHandlerMiddleware) *server.MCPServer {
+	options := []server.ServerOption{server.WithToolHandlerMiddleware(servicesMiddleware(services))}
+	for _, m := range middleware {
+		options = append(options, server.WithToolHandlerMiddleware(m))
+	}
+	s := server.NewMCPServer("test-server", "1.0.0", options...)
+	RegisterServerTools(s, nil)
+	RegisterVolumeTools(s, nil)
+	return s
+}
+
+func TestHandlersUseInjectedServices(t *testing.T) {
+	services := newFakeServices()
+	s := newFakeServicesServer(services)
+
+	t.Run("list servers", func(t *testing.T) {
+		result := callTool(t, s, "bizflycloud_list_servers", map[string]interface{}{})
+		verifyToolResult(t, result, "Server: web-1")
+	})
+
+	t.Run("missing server is not found", func(t *testing.T) {
+		result := callTool(t, s, "bizflycloud_get_server", map[string]interface{}{"server_id": "server-404"})
+		verifyToolError(t, result, "Failed to get server")
+		if result.Meta[errorCodeMeta] != string(ErrorNotFound) {
+			t.Errorf("Expected error code %s, got %v", ErrorNotFound, result.Meta)
+		}
+	})
+
+	t.Run("resize resolves the flavor name", func(t *testing.T) {
+		result := callTool(t, s, "bizflycloud_resize_server", map[string]interface{}{
+			"server_id":   "server-123",
+			"flavor_name": "4c_8g",
+		})
+		verifyToolResult(t, result, "resizing to flavor 4c_8g")
+		if got := services.Servers.(*fakeServers).resized["server-123"]; got != "flavor-4c8g" {
+			t.Errorf("Expected resize to flavor-4c8g, got %q", got)
+		}
+	})
+
+	t.Run("unknown flavor is rejected before resizing", func(t *testing.T) {
+		result := callTool(t, s, "bizflycloud_resize_server", map[string]interface{}{
+			"server_id":   "server-123",
+			"flavor_name": "64c_256g",
+		})
+		verifyToolError(t, result, "Flavor '64c_256g' not found")
+	})
+}
+
+func TestDeletionPreviewUsesInjectedServices(t *testing.T) {
+	services := newFakeServices()
+	s := newFakeServicesServer(services, NewConfirmationStore(time.Minute).Middleware())
+
+	result := callTool(t, s, "bizflycloud_delete_volume", map[string]interface{}{"volume_id": "volume-123"})
+	verifyToolResult(t, result, "Volume: data-1")
+	verifyToolResult(t, result, "Attached To Server: server-123 (/dev/vdb)")
+
+	match := confirmationTokenPattern.FindStringSubmatch(getTextFromResult(result))
+	if match == nil {
+		t.Fatalf("Expected a confirmation token in the preview, got: %s", getTextFromResult(result))
+	}
+	result = callTool(t, s, "bizflycloud_delete_volume", map[string]interface{}{
+		"volume_id":          "volume-123",
+		confirmationTokenArg: match[1],
+	})
+	verifyToolResult(t, result, "deleted successfully")
+	if deleted := services.Volumes.(*fakeVolumes).deleted; len(deleted) != 1 || deleted[0] != "volume-123" {
+		t.Errorf("Expected volume-123 to be deleted, got %v", deleted)
+	}
+}
+
+func TestServicesFromContext(t *testing.T) {
+	if services := servicesFromContext(context.Background(), nil); services != nil {
+		t.Errorf("Expected no services without a client, got %v", services)
+	}
+
+	client, _ := gobizfly.NewClient()
+	services := servicesFromContext(context.Background(), client)
+	if services == nil || services.Servers == nil || services.Alerts == nil {
+		t.Fatalf("Expected services backed by the client, got %v", services)
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const waitForStatusTool = "bizflycloud_wait_for_status"
+
+// statusDeleted is the status a waited-for resource has once its get call
+// reports that it no longer exists
+const statusDeleted = "DELETED"
+
+// minStatusPollInterval is the shortest poll interval a wait_for_status call may ask for
+var minStatusPollInterval = time.Second
+
+// statusKind reads the status of one kind of resource
+type statusKind struct {
+	// field names the status the kind reports, for descriptions
+	field string
+	// failed are the statuses the resource won't leave on its own
+	failed []string
+	get    func(ctx context.Context, services *Services, id string) (string, error)
+}
+
+// statusKinds are the resource kinds whose status can be waited for
+var statusKinds = map[string]statusKind{
+	"server": {"status", []string{"ERROR"}, func(ctx context.Context, services *Services, id string) (string, error) {
+		server, err := services.Servers.Get(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return server.Status, nil
+	}},
+	"volume": {"status", []string{"error", "error_extending", "error_restoring"}, func(ctx context.Context, services *Services, id string) (string, error) {
+		volume, err := services.Volumes.Get(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return volume.Status, nil
+	}},
+	"snapshot": {"status", []string{"error"}, func(ctx context.Context, services *Services, id string) (string, error) {
+		snapshot, err := services.Snapshots.Get(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return snapshot.Status, nil
+	}},
+	"cluster": {"provision status", []string{"FAILED", "ERROR"}, func(ctx context.Context, services *Services, id string) (string, error) {
+		cluster, err := services.Kubernetes.Get(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return cluster.ProvisionStatus, nil
+	}},
+	"database": {"status", []string{"ERROR", "FAILED"}, func(ctx context.Context, services *Services, id string) (string, error) {
+		instance, err := services.Databases.GetInstance(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return instance.Status, nil
+	}},
+	"loadbalancer": {"provisioning status", []string{"ERROR"}, func(ctx context.Context, services *Services, id string) (string, error) {
+		lb, err := services.LoadBalancers.Get(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return lb.ProvisioningStatus, nil
+	}},
+	"autoscaling_group": {"status", []string{"ERROR"}, func(ctx context.Context, services *Services, id string) (string, error) {
+		group, err := services.AutoScaling.Get(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return group.Status, nil
+	}},
+}
+
+// statusKindNames returns the sorted names of the resource kinds
+func statusKindNames() []string {
+	names := make([]string, 0, len(statusKinds))
+	for name := range statusKinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resourceStatus reads the status of a resource, reporting a resource that no
+// longer exists as DELETED
+func resourceStatus(ctx context.Context, services *Services, kind, id string) (string, error) {
+	status, err := statusKinds[kind].get(ctx, services, id)
+	if err != nil {
+		if classifyError(ctx, err).Code == ErrorNotFound {
+			return statusDeleted, nil
+		}
+		return "", err
+	}
+	return status, nil
+}
+
+// RegisterStatusTools registers the tools that follow the status of resources
+func RegisterStatusTools(s *server.MCPServer, client *gobizfly.Client) {
+	var fields []string
+	for _, name := range statusKindNames() {
+		if field := statusKinds[name].field; field != "status" {
+			fields = append(fields, fmt.Sprintf("the %s of a %s", field, name))
+		}
+	}
+
+	// Wait for status tool
+	waitTool := mcp.NewTool(waitForStatusTool,
+		mcp.WithDescription(fmt.Sprintf("Wait until a Bizfly Cloud resource reaches one of the target statuses, e.g. until a volume is available before attaching it or until a cluster is provisioned. "+
+			"Polls the resource and reports the status transitions it saw. Statuses are the ones its get tool shows (%s) and are compared case-insensitively; "+
+			"use %s to wait until the resource is gone. The wait also stops when the resource reaches an error status.", strings.Join(fields, ", "), statusDeleted)),
+		withCommonOptions(),
+		mcp.WithString("resource_type",
+			mcp.Required(),
+			mcp.Description("Kind of resource to wait for"),
+			mcp.Enum(statusKindNames()...),
+		),
+		mcp.WithString("resource_id",
+			mcp.Required(),
+			mcp.Description("ID of the resource (the UID for a cluster)"),
+		),
+		mcp.WithString("target_statuses",
+			mcp.Required(),
+			mcp.Description("Comma-separated statuses to wait for, e.g. ACTIVE or available,in-use"),
+		),
+		mcp.WithNumber("timeout",
+			mcp.Description(fmt.Sprintf("Seconds to wait before giving up (default: %d, at most %d)", int(defaultWaitTimeout.Seconds()), int(maxWaitTimeout.Seconds()))),
+		),
+		mcp.WithNumber("poll_interval",
+			mcp.Description(fmt.Sprintf("Seconds between status checks (default: %d)", int(waitPollInterval.Seconds()))),
+		),
+	)
+	s.AddTool(waitTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
+		args := request.Params.Arguments
+		kind, _ := args["resource_type"].(string)
+		if _, ok := statusKinds[kind]; !ok {
+			return errorResult(ctx, "Failed to wait for status", invalidArgument(fmt.Errorf("resource_type must be one of %s", strings.Join(statusKindNames(), ", ")))), nil
+		}
+		id, _ := args["resource_id"].(string)
+		if id == "" {
+			return errorResult(ctx, "Failed to wait for status", invalidArgument(errors.New("resource_id is required"))), nil
+		}
+		targetsArg, _ := args["target_statuses"].(string)
+		targets := splitList(targetsArg)
+		if len(targets) == 0 {
+			return errorResult(ctx, "Failed to wait for status", invalidArgument(errors.New("target_statuses must name at least one status"))), nil
+		}
+		timeout, err := secondsArg(args, "timeout", defaultWaitTimeout, time.Second, maxWaitTimeout)
+		if err != nil {
+			return errorResult(ctx, "Failed to wait for status", err), nil
+		}
+		interval, err := secondsArg(args, "poll_interval", min(waitPollInterval, timeout), minStatusPollInterval, timeout)
+		if err != nil {
+			return errorResult(ctx, "Failed to wait for status", err), nil
+		}
+
+		// An error status the caller waits for is a target, not a failure
+		var failed []string
+		for _, status := range append([]string{statusDeleted}, statusKinds[kind].failed...) {
+			if !statusIn(status, targets) {
+				failed = append(failed, status)
+			}
+		}
+		poll := statusPoll{
+			Kind:     strings.ReplaceAll(kind, "_", " "),
+			Name:     id,
+			Ready:    targets,
+			Failed:   failed,
+			Timeout:  timeout,
+			Interval: interval,
+			Get: func(ctx context.Context) (string, error) {
+				return resourceStatus(ctx, services, kind, id)
+			},
+		}
+		wait, err := poll.Run(ctx, newProgressReporter(ctx, request))
+		if err != nil {
+			return errorResult(ctx, fmt.Sprintf("Failed to get the status of %s %s", poll.Kind, id), err), nil
+		}
+
+		result := fmt.Sprintf("Waited for %s %s to be %s.\n", poll.Kind, id, strings.Join(targets, " or "))
+		result += describeTransitions(wait)
+		switch {
+		case wait.Ready:
+			result += fmt.Sprintf("The %s reached %s.\n", poll.Kind, wait.Status)
+		case wait.Failed:
+			result += fmt.Sprintf("The %s is %s and won't reach %s.\n", poll.Kind, wait.Status, strings.Join(targets, " or "))
+		case wait.TimedOut:
+			result += fmt.Sprintf("Stopped waiting after %s while the %s is still %s.\n", timeout, poll.Kind, wait.Status)
+		}
+		output := StatusWaitOutput{
+			ResourceType:   kind,
+			ResourceID:     id,
+			TargetStatuses: targets,
+			WaitOutput:     wait,
+		}
+		final := formatResult(request, result, output)
+		final.IsError = wait.Failed
+		return final, nil
+	})
+}
+
+// secondsArg reads an optional number of seconds between min and max
+func secondsArg(args map[string]interface{}, key string, fallback, lowest, highest time.Duration) (time.Duration, error) {
+	value, ok := args[key]
+	if !ok {
+		return fallback, nil
+	}
+	seconds, ok := value.(float64)
+	duration := time.Duration(seconds * float64(time.Second))
+	if !ok || duration < lowest || duration > highest {
+		return 0, invalidArgument(fmt.Errorf("%s must be a number of seconds between %g and %g", key, lowest.Seconds(), highest.Seconds()))
+	}
+	return duration, nil
+}
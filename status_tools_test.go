@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fastStatusPolls lets wait_for_status calls poll every millisecond for the rest of the test
+func fastStatusPolls(t *testing.T) {
+	t.Helper()
+	previous := minStatusPollInterval
+	minStatusPollInterval = time.Millisecond
+	t.Cleanup(func() { minStatusPollInterval = previous })
+}
+
+func TestWaitForStatusTransitions(t *testing.T) {
+	fastStatusPolls(t)
+	cloud, s := newMockServer(t, testCloudState())
+	cloud.SetProvisioningReads(3)
+
+	result := callTool(t, s, "bizflycloud_create_server", map[string]interface{}{
+		"name": "web-2", "flavor_name": "nix.2c_4g", "image_id": "image-1",
+	})
+	if result.IsError {
+		t.Fatalf("create_server failed: %s", getTextFromResult(result))
+	}
+	serverID := cloud.State().Servers[1].ID
+
+	result = callTool(t, s, "bizflycloud_wait_for_status", map[string]interface{}{
+		"resource_type": "server", "resource_id": serverID, "target_statuses": "active",
+		"poll_interval": 0.001, "format": "json",
+	})
+	if result.IsError {
+		t.Fatalf("wait_for_status failed: %s", getTextFromResult(result))
+	}
+	var output StatusWaitOutput
+	if err := json.Unmarshal([]byte(getTextFromResult(result)), &output); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if output.ResourceID != serverID || !output.Ready || output.Status != "ACTIVE" {
+		t.Errorf("Expected the server to become ACTIVE, got %+v", output)
+	}
+	if len(output.Transitions) != 2 || output.Transitions[0].Status != "BUILD" || output.Transitions[1].Status != "ACTIVE" {
+		t.Errorf("Expected the transitions BUILD -> ACTIVE, got %+v", output.Transitions)
+	}
+}
+
+func TestWaitForStatusOutcomes(t *testing.T) {
+	fastStatusPolls(t)
+	cloud, s := newMockServer(t, testCloudState())
+	cloud.SetProvisioningReads(2)
+	cloud.FailProvisioning("broken")
+	result := callTool(t, s, "bizflycloud_create_database", map[string]interface{}{
+		"name": "broken", "type": "MySQL", "version": "mysql-8.0", "flavor": "1c_2g", "volume_size": float64(20),
+		"availability_zone": "HN1",
+	})
+	if result.IsError {
+		t.Fatalf("create_database failed: %s", getTextFromResult(result))
+	}
+	databaseID := cloud.State().Databases[0].ID
+
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		isError  bool
+		expected string
+	}{
+		{
+			name:     "already there",
+			args:     map[string]interface{}{"resource_type": "volume", "resource_id": "vol-1", "target_statuses": "available,in-use"},
+			expected: "The volume reached available",
+		},
+		{
+			name:     "gone",
+			args:     map[string]interface{}{"resource_type": "server", "resource_id": "missing", "target_statuses": "DELETED"},
+			expected: "The server reached DELETED",
+		},
+		{
+			name:     "error status",
+			args:     map[string]interface{}{"resource_type": "database", "resource_id": databaseID, "target_statuses": "ACTIVE", "poll_interval": 0.001},
+			isError:  true,
+			expected: "BUILD -> ERROR",
+		},
+		{
+			name:     "deleted while waiting",
+			args:     map[string]interface{}{"resource_type": "cluster", "resource_id": "missing", "target_statuses": "PROVISIONED"},
+			isError:  true,
+			expected: "is DELETED and won't reach PROVISIONED",
+		},
+		{
+			name:     "timeout",
+			args:     map[string]interface{}{"resource_type": "cluster", "resource_id": "cluster-1", "target_statuses": "FAILED", "timeout": float64(1), "poll_interval": 0.4},
+			expected: "Stopped waiting after 1s",
+		},
+		{
+			name:     "unknown kind",
+			args:     map[string]interface{}{"resource_type": "bucket", "resource_id": "b-1", "target_statuses": "ACTIVE"},
+			isError:  true,
+			expected: "resource_type must be one of autoscaling_group, cluster, database",
+		},
+		{
+			name:     "no target",
+			args:     map[string]interface{}{"resource_type": "server", "resource_id": "srv-1", "target_statuses": " , "},
+			isError:  true,
+			expected: "target_statuses must name at least one status",
+		},
+		{
+			name:     "interval longer than timeout",
+			args:     map[string]interface{}{"resource_type": "server", "resource_id": "srv-1", "target_statuses": "ACTIVE", "timeout": float64(10), "poll_interval": float64(30)},
+			isError:  true,
+			expected: "poll_interval must be a number of seconds between",
+		},
+	}
+	for _, tt := range tests {
+		result := callTool(t, s, "bizflycloud_wait_for_status", tt.args)
+		text := getTextFromResult(result)
+		if result.IsError != tt.isError {
+			t.Errorf("%s: expected IsError %v, got:\n%s", tt.name, tt.isError, text)
+		}
+		if !strings.Contains(text, tt.expected) {
+			t.Errorf("%s: expected %q in:\n%s", tt.name, tt.expected, text)
+		}
+	}
+}
+
+func TestWaitForStatusCancelledOverStdio(t *testing.T) {
+	fastStatusPolls(t)
+	cloud, s := newMockServer(t, testCloudState())
+	conn := startStdio(t, s)
+	conn.send(t, initializeMessage)
+	conn.next(t)
+
+	conn.send(t, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"bizflycloud_wait_for_status","arguments":{"resource_type":"server","resource_id":"srv-1","target_statuses":"SHUTOFF","poll_interval":0.001}}}`)
+	// The wait holds up no other request
+	conn.send(t, `{"jsonrpc":"2.0","id":3,"method":"ping"}`)
+	if response := conn.next(t); response["id"] != float64(3) {
+		t.Fatalf("Expected the ping to be answered while the wait runs, got %v", response)
+	}
+
+	conn.send(t, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":2}}`)
+	conn.send(t, `{"jsonrpc":"2.0","id":4,"method":"ping"}`)
+	if response := conn.next(t); response["id"] != float64(4) {
+		t.Fatalf("Expected no response to the cancelled wait, got %v", response)
+	}
+	// The cancelled wait stops polling once a poll in flight settles
+	time.Sleep(20 * time.Millisecond)
+	polls := len(cloud.Requests())
+	time.Sleep(50 * time.Millisecond)
+	if len(cloud.Requests()) != polls {
+		t.Errorf("Expected the wait to stop polling once cancelled, got %d more requests", len(cloud.Requests())-polls)
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultResourcePollInterval is how often subscribed resources are checked for changes
+const defaultResourcePollInterval = 15 * time.Second
+
+// Resource subscription methods, which the MCP SDK doesn't handle itself
+const (
+	methodResourcesSubscribe   = "resources/subscribe"
+	methodResourcesUnsubscribe = "resources/unsubscribe"
+	methodResourcesUpdated     = "notifications/resources/updated"
+)
+
+// resourceState is the part of a resource a subscription diffs, by field name
+type resourceState map[string]string
+
+// watchedStates derive the state of the resources that can be subscribed to
+// from their get output, by resource path
+var watchedStates = map[string]func(data interface{}) resourceState{
+	"servers": func(data interface{}) resourceState {
+		server := data.(ServerOutput)
+		return resourceState{
+			"status":  server.Status,
+			"flavor":  server.Flavor,
+			"volumes": sortedJoin(server.VolumeIDs),
+		}
+	},
+	"k8s": func(data interface{}) resourceState {
+		cluster := data.(ClusterOutput)
+		var pools []string
+		for _, pool := range cluster.WorkerPools {
+			pools = append(pools, fmt.Sprintf("%s %s x%d", pool.Name, pool.Flavor, pool.DesiredSize))
+		}
+		return resourceState{
+			"status":           cluster.Status,
+			"provision status": cluster.ProvisionStatus,
+			"version":          cluster.Version,
+			"worker pools":     sortedJoin(pools),
+		}
+	},
+	"databases": func(data interface{}) resourceState {
+		db := data.(DatabaseOutput)
+		var nodes []string
+		for _, node := range db.Nodes {
+			nodes = append(nodes, fmt.Sprintf("%s %s %s %s", node.ID, node.Role, node.Flavor, node.Status))
+		}
+		return resourceState{
+			"status":      db.Status,
+			"volume size": fmt.Sprintf("%d GB", db.VolumeSizeGB),
+			"nodes":       sortedJoin(nodes),
+		}
+	},
+}
+
+// changes describes the fields whose value differs from the previous state
+func (s resourceState) changes(previous resourceState) []string {
+	var fields []string
+	for field := range s {
+		if s[field] != previous[field] {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	var changes []string
+	for _, field := range fields {
+		changes = append(changes, fmt.Sprintf("%s %s -> %s", field, orDash(previous[field]), orDash(s[field])))
+	}
+	return changes
+}
+
+// resourceSubscription is a resource a client subscribed to
+type resourceSubscription struct {
+	resource cloudResource
+	id       string
+	state    resourceState
+}
+
+// ResourceSubscriptions tracks the resources a client subscribed to and polls
+// them in the background, notifying the client when their state changes.
+// Like resource reads, it uses the default profile and region.
+type ResourceSubscriptions struct {
+	mu            sync.Mutex
+	client        *gobizfly.Client
+	resources     []cloudResource
+	interval      time.Duration
+	subscriptions map[string]*resourceSubscription
+}
+
+// NewResourceSubscriptions creates the subscriptions of the resources the
+// filter enables, polled every interval
+func NewResourceSubscriptions(client *gobizfly.Client, filter *ToolFilter, interval time.Duration) *ResourceSubscriptions {
+	r := &ResourceSubscriptions{
+		client:        client,
+		interval:      interval,
+		subscriptions: make(map[string]*resourceSubscription),
+	}
+	for _, resource := range cloudResources {
+		if _, ok := watchedStates[resource.path]; ok && filter.ServiceEnabled(resource.service) {
+			r.resources = append(r.resources, resource)
+		}
+	}
+	return r
+}
+
+// HandleMessage answers resources/subscribe and resources/unsubscribe
+// requests; it reports false for every other message
+func (r *ResourceSubscriptions) HandleMessage(ctx context.Context, message json.RawMessage) (mcp.JSONRPCMessage, bool) {
+	var request struct {
+		ID     mcp.RequestId `json:"id"`
+		Method string        `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil {
+		return nil, false
+	}
+	switch request.Method {
+	case methodResourcesSubscribe:
+		if err := r.Subscribe(ctx, request.Params.URI); err != nil {
+			code := mcp.INTERNAL_ERROR
+			switch classifyError(ctx, err).Code {
+			case ErrorValidation, ErrorNotFound:
+				code = mcp.INVALID_PARAMS
+			}
+			return mcp.NewJSONRPCError(request.ID, code, err.Error(), nil), true
+		}
+	case methodResourcesUnsubscribe:
+		r.Unsubscribe(request.Params.URI)
+	default:
+		return nil, false
+	}
+	return mcp.NewJSONRPCResponse(request.ID, mcp.Result{}), true
+}
+
+// Subscribe starts watching the resource at uri from its current state
+func (r *ResourceSubscriptions) Subscribe(ctx context.Context, uri string) error {
+	resource, id, ok := r.match(uri)
+	if !ok {
+		var templates []string
+		for _, resource := range r.resources {
+			templates = append(templates, resource.uriTemplate())
+		}
+		return invalidArgument(fmt.Errorf("can't subscribe to %s; subscriptions are available for %s", uri, strings.Join(templates, ", ")))
+	}
+	ctx = withAPIStatus(ctx)
+	state, err := r.read(ctx, resource, id)
+	if err != nil {
+		return resourceError(ctx, fmt.Sprintf("Failed to get %s %s", resource.kind, id), err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscriptions[uri] = &resourceSubscription{resource: resource, id: id, state: state}
+	return nil
+}
+
+// Unsubscribe stops watching the resource at uri
+func (r *ResourceSubscriptions) Unsubscribe(uri string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.subscriptions, uri)
+}
+
+// match returns the resource kind and ID a subscribable URI names
+func (r *ResourceSubscriptions) match(uri string) (cloudResource, string, bool) {
+	for _, resource := range r.resources {
+		id, found := strings.CutPrefix(uri, resource.listURI()+"/")
+		if found && id != "" && !strings.Contains(id, "/") {
+			return resource, id, true
+		}
+	}
+	return cloudResource{}, "", false
+}
+
+// read returns the state of a resource
+func (r *ResourceSubscriptions) read(ctx context.Context, resource cloudResource, id string) (resourceState, error) {
+	data, err := resource.get(ctx, servicesFromContext(ctx, r.client), id)
+	if err != nil {
+		return nil, err
+	}
+	return watchedStates[resource.path](data), nil
+}
+
+// Poll reads every subscribed resource and returns the URIs whose state
+// changed since the last poll, sorted
+func (r *ResourceSubscriptions) Poll(ctx context.Context) []string {
+	r.mu.Lock()
+	watched := make(map[string]resourceSubscription, len(r.subscriptions))
+	for uri, subscription := range r.subscriptions {
+		watched[uri] = *subscription
+	}
+	r.mu.Unlock()
+
+	var changed []string
+	for uri, subscription := range watched {
+		readCtx := withAPIStatus(ctx)
+		state, err := r.read(readCtx, subscription.resource, subscription.id)
+		if err != nil && classifyError(readCtx, err).Code == ErrorNotFound {
+			state, err = resourceState{"status": statusDeleted}, nil
+		}
+		if err != nil {
+			// A failed poll leaves the state as it was; the next one tries again
+			if !errors.Is(err, context.Canceled) {
+				log.Printf("[WARN] Failed to poll subscribed resource %s: %v", uri, err)
+			}
+			continue
+		}
+		changes := state.changes(subscription.state)
+		if len(changes) == 0 {
+			continue
+		}
+
+		r.mu.Lock()
+		// The client may have unsubscribed while the resource was read
+		if current, ok := r.subscriptions[uri]; ok {
+			current.state = state
+			changed = append(changed, uri)
+			log.Printf("[INFO] Subscribed resource %s changed: %s", uri, strings.Join(changes, ", "))
+		}
+		r.mu.Unlock()
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Run polls the subscribed resources every interval until ctx is done and
+// sends a notifications/resources/updated for every resource that changed
+func (r *ResourceSubscriptions) Run(ctx context.Context, notifications chan<- mcp.JSONRPCNotification) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, uri := range r.Poll(ctx) {
+			notification := mcp.JSONRPCNotification{
+				JSONRPC: mcp.JSONRPC_VERSION,
+				Notification: mcp.Notification{
+					Method: methodResourcesUpdated,
+					Params: mcp.NotificationParams{AdditionalFields: map[string]interface{}{"uri": uri}},
+				},
+			}
+			select {
+			case notifications <- notification:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// sortedJoin joins a sorted copy of items, so their order doesn't count as a change
+func sortedJoin(items []string) string {
+	sorted := append([]string(nil), items...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
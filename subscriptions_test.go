@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bizflycloud-mcp-server/internal/fakecloud"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newMockSubscriptions returns the subscriptions of a server created by newMockServer
+func newMockSubscriptions(t *testing.T, cloud *fakecloud.Cloud, filter *ToolFilter, interval time.Duration) *ResourceSubscriptions {
+	t.Helper()
+	pool := NewClientPool(NewMockConfig())
+	pool.SetTransport(cloud)
+	return NewResourceSubscriptions(newMockClient(t, pool), filter, interval)
+}
+
+func TestResourceSubscriptionsPoll(t *testing.T) {
+	cloud, s := newMockServer(t, testCloudState())
+	cloud.SetProvisioningReads(2)
+	subscriptions := newMockSubscriptions(t, cloud, nil, time.Hour)
+	ctx := context.Background()
+	call := func(name string, args map[string]interface{}) string {
+		result := callTool(t, s, name, args)
+		if result.IsError {
+			t.Fatalf("%s failed: %s", name, getTextFromResult(result))
+		}
+		return getTextFromResult(result)
+	}
+
+	text := call("bizflycloud_create_database", map[string]interface{}{
+		"name": "orders", "type": "MySQL", "version": "mysql-8.0", "flavor": "1c_2g", "volume_size": float64(20),
+		"availability_zone": "HN1", "format": "json",
+	})
+	var database DatabaseCreationOutput
+	if err := json.Unmarshal([]byte(text), &database); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	uris := []string{"bizflycloud://servers/srv-1", "bizflycloud://k8s/cluster-1", "bizflycloud://databases/" + database.ID}
+	for _, uri := range uris {
+		if err := subscriptions.Subscribe(ctx, uri); err != nil {
+			t.Fatalf("Subscribe(%s) failed: %v", uri, err)
+		}
+	}
+
+	// The database finishes provisioning; nothing else changed
+	if changed := subscriptions.Poll(ctx); !reflect.DeepEqual(changed, uris[2:]) {
+		t.Errorf("Expected only the provisioned database to change, got %v", changed)
+	}
+	if changed := subscriptions.Poll(ctx); len(changed) != 0 {
+		t.Errorf("Expected no change without activity, got %v", changed)
+	}
+
+	// A resize and an attached volume change the server
+	call("bizflycloud_resize_server", map[string]interface{}{"server_id": "srv-1", "flavor_name": "nix.4c_8g"})
+	if changed := subscriptions.Poll(ctx); !reflect.DeepEqual(changed, uris[:1]) {
+		t.Errorf("Expected the resized server to change, got %v", changed)
+	}
+	call("bizflycloud_attach_volume", map[string]interface{}{"volume_id": "vol-1", "server_id": "srv-1"})
+	if changed := subscriptions.Poll(ctx); !reflect.DeepEqual(changed, uris[:1]) {
+		t.Errorf("Expected the server with a new volume to change, got %v", changed)
+	}
+
+	// A deleted server changes once, an unsubscribed one not at all
+	call("bizflycloud_delete_server", map[string]interface{}{"server_id": "srv-1"})
+	if changed := subscriptions.Poll(ctx); !reflect.DeepEqual(changed, uris[:1]) {
+		t.Errorf("Expected the deleted server to change, got %v", changed)
+	}
+	if changed := subscriptions.Poll(ctx); len(changed) != 0 {
+		t.Errorf("Expected a deleted server to change only once, got %v", changed)
+	}
+	subscriptions.Unsubscribe(uris[1])
+	call("bizflycloud_delete_kubernetes_cluster", map[string]interface{}{"cluster_id": "cluster-1"})
+	if changed := subscriptions.Poll(ctx); len(changed) != 0 {
+		t.Errorf("Expected no change after unsubscribing, got %v", changed)
+	}
+}
+
+func TestResourceSubscriptionsErrors(t *testing.T) {
+	cloud, _ := newMockServer(t, testCloudState())
+	subscriptions := newMockSubscriptions(t, cloud, &ToolFilter{DisableServices: []string{serviceDatabase}}, time.Hour)
+
+	tests := []struct {
+		uri      string
+		code     int
+		expected string
+	}{
+		{"bizflycloud://volumes/vol-1", mcp.INVALID_PARAMS, "subscriptions are available for bizflycloud://servers/{id}, bizflycloud://k8s/{cluster_id}"},
+		{"bizflycloud://databases/db-1", mcp.INVALID_PARAMS, "can't subscribe to bizflycloud://databases/db-1"},
+		{"bizflycloud://servers/srv-1/volumes", mcp.INVALID_PARAMS, "can't subscribe"},
+		{"bizflycloud://servers/missing", mcp.INVALID_PARAMS, "Failed to get server missing"},
+	}
+	for _, tt := range tests {
+		message := `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"` + tt.uri + `"}}`
+		response, handled := subscriptions.HandleMessage(context.Background(), json.RawMessage(message))
+		failed, ok := response.(mcp.JSONRPCError)
+		if !handled || !ok {
+			t.Errorf("%s: expected an error response, got %+v", tt.uri, response)
+			continue
+		}
+		if failed.Error.Code != tt.code || !strings.Contains(failed.Error.Message, tt.expected) {
+			t.Errorf("%s: expected code %d and %q, got %d %q", tt.uri, tt.code, tt.expected, failed.Error.Code, failed.Error.Message)
+		}
+	}
+
+	if _, handled := subscriptions.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":2,"method":"resources/list"}`)); handled {
+		t.Error("Expected other methods to be left to the MCP server")
+	}
+}
+
+func TestResourceSubscriptionNotifications(t *testing.T) {
+	cloud, s := newMockServer(t, testCloudState())
+	h := startSubscriptionHarness(t, s, newMockSubscriptions(t, cloud, nil, 5*time.Millisecond))
+
+	subscribe := mcp.SubscribeRequest{}
+	subscribe.Params.URI = "bizflycloud://servers/srv-1"
+	if err := h.Subscribe(h.context(t), subscribe); err != nil {
+		t.Fatalf("resources/subscribe failed: %v", err)
+	}
+	h.call(t, "bizflycloud_stop_server", map[string]interface{}{"server_id": "srv-1"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		var updated []interface{}
+		for _, notification := range h.receivedNotifications() {
+			if notification.Method == methodResourcesUpdated {
+				updated = append(updated, notification.Params.AdditionalFields["uri"])
+			}
+		}
+		if len(updated) > 0 {
+			if len(updated) != 1 || updated[0] != subscribe.Params.URI {
+				t.Errorf("Expected one update of %s, got %v", subscribe.Params.URI, updated)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected a notifications/resources/updated after the server stopped")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	unsubscribe := mcp.UnsubscribeRequest{}
+	unsubscribe.Params.URI = subscribe.Params.URI
+	if err := h.Unsubscribe(h.context(t), unsubscribe); err != nil {
+		t.Errorf("resources/unsubscribe failed: %v", err)
+	}
+
+	// Without subscriptions the server doesn't offer them
+	plain := startHarness(t, server.NewMCPServer("BizflyCloud MCP Test", "1.0.0"))
+	if err := plain.Subscribe(plain.context(t), subscribe); err == nil {
+		t.Error("Expected resources/subscribe to fail without subscriptions")
+	}
+}
@@ -0,0 +1,511 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// defaultMaxTasks is how many tasks the registry keeps before it forgets the oldest
+	defaultMaxTasks = 500
+
+	// taskIDsMeta is the _meta key listing the tasks a tool call started
+	taskIDsMeta = "task_ids"
+)
+
+// Task outcomes
+const (
+	taskRunning   = "running"
+	taskSucceeded = "succeeded"
+	taskFailed    = "failed"
+)
+
+// TaskSpec describes an asynchronous operation a tool started
+type TaskSpec struct {
+	Action       string // e.g. "create" or "resize"
+	ResourceType string // one of the statusKinds
+	ResourceID   string // empty until the API task reports it for a server being created
+	ResourceName string
+	// APITaskID is the Bizfly task carrying the operation, if the API returned one.
+	// The operation isn't finished before the task is.
+	APITaskID string
+	// Done are the statuses of the resource once the operation finished
+	Done []string
+	// From is the status of the resource as the operation started. A resource
+	// still in it hasn't begun the operation, so when From is also a Done status,
+	// as for a reboot, the task only finishes after a poll saw it leave From.
+	From string
+}
+
+// Task is an asynchronous operation started through the server and what was
+// last observed of it
+type Task struct {
+	TaskSpec
+	ID         string
+	Tool       string
+	Session    string
+	Profile    string
+	Region     string
+	StartedAt  time.Time
+	CheckedAt  time.Time
+	FinishedAt time.Time
+	State      string
+	Progress   int
+	Outcome    string
+	Error      string
+
+	// left is set once a poll saw the resource in a status other than From
+	left bool
+
+	// services are the ones the operation was started with, so the task is
+	// polled with the profile and region it runs in. A client of the pool is
+	// kept instead and bound to the call polling the task, whose context the
+	// services of the starting call no longer carry.
+	services *Services
+	client   *gobizfly.Client
+}
+
+type taskContextKey struct{}
+
+// taskScope is who sees a task: the client session and profile that started it
+type taskScope struct {
+	session string
+	profile string
+}
+
+// taskScopeFromContext returns the scope of the current tool call
+func taskScopeFromContext(ctx context.Context) taskScope {
+	scope := taskScope{session: sessionIDFromContext(ctx)}
+	if profile := profileFromContext(ctx); profile != nil {
+		scope.profile = profile.Name
+	}
+	return scope
+}
+
+// sessionIDFromContext returns the ID of the client session making the call,
+// or "" outside of one
+func sessionIDFromContext(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// visibleTo reports whether the task was started in the scope
+func (t *Task) visibleTo(scope taskScope) bool {
+	return t.Session == scope.session && t.Profile == scope.profile
+}
+
+// TaskRegistry records the asynchronous operations tools start, such as
+// creating a server or resizing a volume, so they can be followed after the
+// tool call returned. Tasks are kept in memory and forgotten on restart.
+type TaskRegistry struct {
+	mu    sync.Mutex
+	tasks map[string]*Task
+	next  int
+	max   int
+	now   func() time.Time
+}
+
+// NewTaskRegistry creates an empty task registry
+func NewTaskRegistry() *TaskRegistry {
+	return &TaskRegistry{
+		tasks: make(map[string]*Task),
+		max:   defaultMaxTasks,
+		now:   time.Now,
+	}
+}
+
+// taskCall collects the tasks one tool call starts
+type taskCall struct {
+	registry *TaskRegistry
+	request  mcp.CallToolRequest
+	started  []string
+}
+
+// tasksFromContext returns the task registry of the current tool call, or nil
+func tasksFromContext(ctx context.Context) *TaskRegistry {
+	if call, ok := ctx.Value(taskContextKey{}).(*taskCall); ok {
+		return call.registry
+	}
+	return nil
+}
+
+// Middleware makes the registry available to the tool handlers and lists the
+// tasks a call started in the _meta of its result and, in text mode, its text
+func (r *TaskRegistry) Middleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			call := &taskCall{registry: r, request: request}
+			result, err := next(context.WithValue(ctx, taskContextKey{}, call), request)
+			if err != nil || result == nil || len(call.started) == 0 {
+				return result, err
+			}
+			if result.Meta == nil {
+				result.Meta = make(map[string]interface{})
+			}
+			result.Meta[taskIDsMeta] = call.started
+			if outputFormat(request) != formatJSON && len(result.Content) > 0 {
+				if text, ok := result.Content[0].(mcp.TextContent); ok {
+					text.Text = strings.TrimRight(text.Text, "\n") + fmt.Sprintf("\n\nTracking as %s; check progress with bizflycloud_get_task.", strings.Join(call.started, ", "))
+					result.Content[0] = text
+				}
+			}
+			return result, nil
+		}
+	}
+}
+
+// recordTask registers an operation the current tool call started with the
+// given services. It does nothing when the server doesn't track tasks.
+func recordTask(ctx context.Context, services *Services, spec TaskSpec) {
+	call, ok := ctx.Value(taskContextKey{}).(*taskCall)
+	if !ok {
+		return
+	}
+	scope := taskScopeFromContext(ctx)
+	region, _ := call.request.Params.Arguments["region"].(string)
+	if selected := profileFromContext(ctx); selected != nil && region == "" {
+		region = selected.Region
+	}
+	task := call.registry.add(&Task{
+		TaskSpec: spec,
+		Tool:     call.request.Params.Name,
+		Session:  scope.session,
+		Profile:  scope.profile,
+		Region:   region,
+		Outcome:  taskRunning,
+		services: services,
+		client:   clientFromContext(ctx, nil),
+	})
+	call.started = append(call.started, task.ID)
+}
+
+// startStatus returns the status of a resource as an operation on it starts,
+// for the From of its task. It returns "" without reading it when the server
+// doesn't track tasks or the status can't be read.
+func startStatus(ctx context.Context, services *Services, kind, id string) string {
+	if tasksFromContext(ctx) == nil {
+		return ""
+	}
+	status, err := resourceStatus(ctx, services, kind, id)
+	if err != nil {
+		return ""
+	}
+	return status
+}
+
+// add stores a new task, forgetting the oldest finished task, or else the
+// oldest task, once the registry is full
+func (r *TaskRegistry) add(task *Task) *Task {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next++
+	task.ID = fmt.Sprintf("task-%d", r.next)
+	task.StartedAt = r.now()
+	r.tasks[task.ID] = task
+
+	if len(r.tasks) > r.max {
+		var evict *Task
+		for _, t := range r.tasks {
+			if evict == nil || evictBefore(t, evict) {
+				evict = t
+			}
+		}
+		delete(r.tasks, evict.ID)
+	}
+	return task
+}
+
+// evictBefore reports whether a full registry forgets a before b: finished
+// tasks go before running ones, and older tasks before newer ones
+func evictBefore(a, b *Task) bool {
+	aFinished, bFinished := a.Outcome != taskRunning, b.Outcome != taskRunning
+	if aFinished != bFinished {
+		return aFinished
+	}
+	return taskNumber(a.ID) < taskNumber(b.ID)
+}
+
+// Get returns a copy of the task
+func (r *TaskRegistry) Get(id string) (Task, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task, ok := r.tasks[id]
+	if !ok {
+		return Task{}, false
+	}
+	return *task, true
+}
+
+// List returns copies of every task, the most recently started first
+func (r *TaskRegistry) List() []Task {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tasks := make([]Task, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		tasks = append(tasks, *task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return taskNumber(tasks[i].ID) > taskNumber(tasks[j].ID) })
+	return tasks
+}
+
+// taskNumber returns the sequence number of a task ID
+func taskNumber(id string) int {
+	var n int
+	fmt.Sscanf(id, "task-%d", &n)
+	return n
+}
+
+// Refresh polls a running task's API task or resource and returns what it
+// observed. Finished tasks are returned as they are. A failed poll is kept in
+// the task's Error and doesn't change its outcome. Tasks started by another
+// session or profile are unknown to the call.
+func (r *TaskRegistry) Refresh(ctx context.Context, id string) (Task, error) {
+	task, ok := r.Get(id)
+	if !ok || !task.visibleTo(taskScopeFromContext(ctx)) {
+		return Task{}, invalidArgument(fmt.Errorf("unknown task %s; bizflycloud_list_tasks lists the tasks of this server", id))
+	}
+	if task.Outcome != taskRunning {
+		return task, nil
+	}
+
+	observed := task
+	if err := pollTask(ctx, &observed); err != nil {
+		observed.Error = err.Error()
+	} else {
+		observed.Error = ""
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.tasks[id]
+	if !ok {
+		return observed, nil
+	}
+	now := r.now()
+	stored.ResourceID = observed.ResourceID
+	stored.State = observed.State
+	stored.Progress = observed.Progress
+	stored.Outcome = observed.Outcome
+	stored.Error = observed.Error
+	stored.left = observed.left
+	stored.CheckedAt = now
+	if stored.Outcome != taskRunning {
+		stored.FinishedAt = now
+	}
+	return *stored, nil
+}
+
+// pollTask updates the task with the state of its API task and resource
+func pollTask(ctx context.Context, task *Task) error {
+	services := task.services
+	if task.client != nil {
+		services = servicesFromContext(context.WithValue(ctx, clientContextKey{}, task.client), nil)
+	}
+	if services == nil {
+		return errors.New("the task has no client to poll it with")
+	}
+	if task.APITaskID != "" {
+		apiTask, err := services.Servers.GetTask(ctx, task.APITaskID)
+		if err != nil {
+			return err
+		}
+		task.Progress = apiTask.Result.Progress
+		if task.ResourceID == "" {
+			task.ResourceID = apiTask.Result.ID
+		}
+		if apiTask.Ready && !apiTask.Result.Success {
+			task.State = "ERROR"
+			task.Outcome = taskFailed
+			return nil
+		}
+		if !apiTask.Ready {
+			if task.ResourceID != "" {
+				if status, err := resourceStatus(ctx, services, task.ResourceType, task.ResourceID); err == nil {
+					task.State = status
+				}
+			}
+			return nil
+		}
+	}
+	if task.ResourceID == "" {
+		return nil
+	}
+
+	status, err := resourceStatus(ctx, services, task.ResourceType, task.ResourceID)
+	if err != nil {
+		return err
+	}
+	task.State = status
+	if task.From != "" && !task.left {
+		if status == task.From {
+			return nil
+		}
+		task.left = true
+	}
+	switch {
+	case statusIn(status, task.Done):
+		task.Outcome = taskSucceeded
+	case statusIn(status, append([]string{statusDeleted}, statusKinds[task.ResourceType].failed...)):
+		task.Outcome = taskFailed
+	}
+	return nil
+}
+
+// newTaskOutput converts a task to its JSON schema
+func newTaskOutput(task Task) TaskOutput {
+	out := TaskOutput{
+		ID:           task.ID,
+		Tool:         task.Tool,
+		Action:       task.Action,
+		ResourceType: task.ResourceType,
+		ResourceID:   task.ResourceID,
+		ResourceName: task.ResourceName,
+		APITaskID:    task.APITaskID,
+		Profile:      task.Profile,
+		Region:       task.Region,
+		StartedAt:    task.StartedAt.UTC().Format(time.RFC3339),
+		State:        task.State,
+		Progress:     task.Progress,
+		Outcome:      task.Outcome,
+		Error:        task.Error,
+	}
+	if !task.CheckedAt.IsZero() {
+		out.CheckedAt = task.CheckedAt.UTC().Format(time.RFC3339)
+	}
+	if !task.FinishedAt.IsZero() {
+		out.FinishedAt = task.FinishedAt.UTC().Format(time.RFC3339)
+	}
+	return out
+}
+
+// describeTask formats a task for the text result
+func describeTask(task Task) string {
+	resource := task.ResourceID
+	if task.ResourceName != "" {
+		resource = fmt.Sprintf("%s (%s)", task.ResourceName, orDash(task.ResourceID))
+	}
+	result := fmt.Sprintf("Task: %s\n", task.ID)
+	result += fmt.Sprintf("  Operation: %s %s %s\n", task.Action, strings.ReplaceAll(task.ResourceType, "_", " "), orDash(resource))
+	result += fmt.Sprintf("  Tool: %s\n", task.Tool)
+	result += fmt.Sprintf("  Outcome: %s\n", task.Outcome)
+	result += fmt.Sprintf("  State: %s\n", orDash(task.State))
+	if task.APITaskID != "" {
+		result += fmt.Sprintf("  API Task: %s (%d%%)\n", task.APITaskID, task.Progress)
+	}
+	if task.Profile != "" {
+		result += fmt.Sprintf("  Profile: %s, region %s\n", task.Profile, task.Region)
+	}
+	result += fmt.Sprintf("  Started: %s\n", task.StartedAt.UTC().Format(time.RFC3339))
+	if !task.FinishedAt.IsZero() {
+		result += fmt.Sprintf("  Finished: %s\n", task.FinishedAt.UTC().Format(time.RFC3339))
+	}
+	if task.Error != "" {
+		result += fmt.Sprintf("  Last poll failed: %s\n", task.Error)
+	}
+	return result
+}
+
+// orDash returns s, or "-" when it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// RegisterTaskTools registers the tools that follow the tasks of the registry
+func RegisterTaskTools(s *server.MCPServer) {
+	// List tasks tool
+	listTasksTool := mcp.NewTool("bizflycloud_list_tasks",
+		mcp.WithDescription("List the asynchronous operations this session started through the server with the selected profile, such as creating a server or resizing a volume, the newest first. The running tasks of the returned page are polled first."),
+		withCommonOptions(),
+		withPagingOptions(),
+		mcp.WithString("outcome",
+			mcp.Description("Only list tasks with this outcome when last polled"),
+			mcp.Enum(taskRunning, taskSucceeded, taskFailed),
+		),
+		mcp.WithString("resource_id",
+			mcp.Description("Only list tasks on this resource"),
+		),
+	)
+	s.AddTool(listTasksTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		registry := tasksFromContext(ctx)
+		if registry == nil {
+			return mcp.NewToolResultError("Failed to list tasks: task tracking is disabled on this server"), nil
+		}
+		query, err := parseListQuery(request.Params.Arguments)
+		if err != nil {
+			return errorResult(ctx, "Failed to list tasks", err), nil
+		}
+		outcome, _ := request.Params.Arguments["outcome"].(string)
+		resourceID, _ := request.Params.Arguments["resource_id"].(string)
+
+		scope := taskScopeFromContext(ctx)
+		tasks := []Task{}
+		for _, task := range registry.List() {
+			if task.visibleTo(scope) && (outcome == "" || task.Outcome == outcome) && (resourceID == "" || task.ResourceID == resourceID) {
+				tasks = append(tasks, task)
+			}
+		}
+		tasks, page := pageItems(query, tasks, taskListFields)
+
+		items := []TaskOutput{}
+		result := ""
+		for _, task := range tasks {
+			if task.Outcome == taskRunning {
+				if refreshed, err := registry.Refresh(ctx, task.ID); err == nil {
+					task = refreshed
+				}
+			}
+			items = append(items, newTaskOutput(task))
+			result += describeTask(task) + "\n"
+		}
+		if page.Total == 0 {
+			result = "No tasks found. Tasks are recorded for operations this session started through this server since it started.\n"
+		} else {
+			result = fmt.Sprintf("Tasks (%d):\n\n", page.Total) + result
+		}
+		return pageResult(request, result, "tasks", items, page), nil
+	})
+
+	// Get task tool
+	getTaskTool := mcp.NewTool("bizflycloud_get_task",
+		mcp.WithDescription("Get the progress and outcome of an asynchronous operation started through this server, polling its resource or Bizfly task"),
+		withCommonOptions(),
+		mcp.WithString("task_id",
+			mcp.Required(),
+			mcp.Description("ID of the task, e.g. task-3"),
+		),
+	)
+	s.AddTool(getTaskTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		registry := tasksFromContext(ctx)
+		if registry == nil {
+			return mcp.NewToolResultError("Failed to get task: task tracking is disabled on this server"), nil
+		}
+		taskID, ok := request.Params.Arguments["task_id"].(string)
+		if !ok {
+			return nil, errors.New("task_id must be a string")
+		}
+		task, err := registry.Refresh(ctx, taskID)
+		if err != nil {
+			return errorResult(ctx, "Failed to get task", err), nil
+		}
+		return formatResult(request, describeTask(task), newTaskOutput(task)), nil
+	})
+}
+
+// taskListFields returns the fields the list arguments filter and sort tasks on
+func taskListFields(task Task) listFields {
+	return listFields{Name: task.ResourceName, Status: task.Outcome, CreatedAt: task.StartedAt.UTC().Format(time.RFC3339)}
+}
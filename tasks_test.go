@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// getTask calls bizflycloud_get_task in JSON mode
+func getTask(t *testing.T, call func(name string, args map[string]interface{}) string, id string) TaskOutput {
+	t.Helper()
+	var task TaskOutput
+	text := call("bizflycloud_get_task", map[string]interface{}{"task_id": id, "format": "json"})
+	if err := json.Unmarshal([]byte(text), &task); err != nil {
+		t.Fatalf("Expected JSON output, got %s: %v", text, err)
+	}
+	return task
+}
+
+func TestTaskTracksServerCreation(t *testing.T) {
+	cloud, s := newMockServer(t, testCloudState())
+	cloud.SetProvisioningReads(2)
+	call := func(name string, args map[string]interface{}) string {
+		result := callTool(t, s, name, args)
+		if result.IsError {
+			t.Fatalf("%s failed: %s", name, getTextFromResult(result))
+		}
+		return getTextFromResult(result)
+	}
+
+	result := callTool(t, s, "bizflycloud_create_server", map[string]interface{}{
+		"name": "web-2", "flavor_name": "nix.2c_4g", "image_id": "image-1",
+	})
+	if result.IsError {
+		t.Fatalf("create_server failed: %s", getTextFromResult(result))
+	}
+	if ids := result.Meta[taskIDsMeta]; !reflect.DeepEqual(ids, []string{"task-1"}) {
+		t.Errorf("Expected the result to name task-1 in _meta, got %v", ids)
+	}
+	if text := getTextFromResult(result); !strings.Contains(text, "Tracking as task-1; check progress with bizflycloud_get_task") {
+		t.Errorf("Expected the task in the text result, got:\n%s", text)
+	}
+
+	task := getTask(t, call, "task-1")
+	if task.Tool != "bizflycloud_create_server" || task.Action != "create" || task.ResourceName != "web-2" || task.APITaskID == "" {
+		t.Errorf("Unexpected task: %+v", task)
+	}
+	if task.Outcome != taskRunning || task.State != "BUILD" || task.ResourceID != cloud.State().Servers[1].ID || task.Progress != 100 {
+		t.Errorf("Expected the task to run while the server builds, got %+v", task)
+	}
+	if task.Profile != mockProfileName || task.Region == "" {
+		t.Errorf("Expected the task to record the profile and region, got %q %q", task.Profile, task.Region)
+	}
+
+	task = getTask(t, call, "task-1")
+	if task.Outcome != taskSucceeded || task.State != "ACTIVE" || task.FinishedAt == "" {
+		t.Errorf("Expected the task to succeed once the server is ACTIVE, got %+v", task)
+	}
+	// A finished task isn't polled again
+	requests := len(cloud.Requests())
+	getTask(t, call, "task-1")
+	if got := len(cloud.Requests()); got != requests {
+		t.Errorf("Expected no API request for a finished task, got %d", got-requests)
+	}
+}
+
+func TestListTasks(t *testing.T) {
+	cloud, s := newMockServer(t, testCloudState())
+	cloud.SetProvisioningReads(3)
+	cloud.FailProvisioning("broken")
+	steps := []struct {
+		tool string
+		args map[string]interface{}
+	}{
+		{"bizflycloud_stop_server", map[string]interface{}{"server_id": "srv-1"}},
+		{"bizflycloud_attach_volume", map[string]interface{}{"volume_id": "vol-1", "server_id": "srv-1"}},
+		{"bizflycloud_create_database", map[string]interface{}{
+			"name": "broken", "type": "MySQL", "version": "mysql-8.0", "flavor": "1c_2g", "volume_size": float64(20), "availability_zone": "HN1",
+		}},
+		{"bizflycloud_create_volume", map[string]interface{}{"name": "logs", "size": float64(30), "volume_type": "PREMIUM-SSD1", "dry_run": true}},
+	}
+	for _, step := range steps {
+		if result := callTool(t, s, step.tool, step.args); result.IsError {
+			t.Fatalf("%s failed: %s", step.tool, getTextFromResult(result))
+		}
+	}
+
+	result := callTool(t, s, "bizflycloud_list_tasks", map[string]interface{}{"format": "json"})
+	var listed struct {
+		Tasks []TaskOutput `json:"tasks"`
+		Count int          `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(getTextFromResult(result)), &listed); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	// The dry run started nothing; the newest task comes first
+	var summary []string
+	for _, task := range listed.Tasks {
+		summary = append(summary, fmt.Sprintf("%s %s %s %s", task.ID, task.Action, task.ResourceType, task.Outcome))
+	}
+	want := []string{"task-3 create database running", "task-2 attach volume succeeded", "task-1 stop server succeeded"}
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("Expected tasks %v, got %v", want, summary)
+	}
+
+	// Polling the database through its provisioning ends in the failure
+	for i := 0; i < 3; i++ {
+		callTool(t, s, "bizflycloud_list_tasks", map[string]interface{}{})
+	}
+	result = callTool(t, s, "bizflycloud_list_tasks", map[string]interface{}{"outcome": "failed"})
+	text := getTextFromResult(result)
+	if !strings.Contains(text, "Task: task-3") || !strings.Contains(text, "State: ERROR") || strings.Contains(text, "task-1") {
+		t.Errorf("Expected only the failed database task, got:\n%s", text)
+	}
+
+	result = callTool(t, s, "bizflycloud_list_tasks", map[string]interface{}{"resource_id": "vol-1"})
+	if text := getTextFromResult(result); !strings.Contains(text, "Tasks (1)") || !strings.Contains(text, "attach volume vol-1") {
+		t.Errorf("Expected the volume task, got:\n%s", text)
+	}
+}
+
+func TestTaskWaitsForTheResourceToLeaveItsStartStatus(t *testing.T) {
+	cloud, s := newMockServer(t, testCloudState())
+	call := func(name string, args map[string]interface{}) string {
+		result := callTool(t, s, name, args)
+		if result.IsError {
+			t.Fatalf("%s failed: %s", name, getTextFromResult(result))
+		}
+		return getTextFromResult(result)
+	}
+
+	// The server is still ACTIVE as the reboot starts, so ACTIVE doesn't finish it
+	call("bizflycloud_reboot_server", map[string]interface{}{"server_id": "srv-1"})
+	if task := getTask(t, call, "task-1"); task.Outcome != taskRunning || task.State != "ACTIVE" {
+		t.Errorf("Expected the reboot to run until the server leaves ACTIVE, got %+v", task)
+	}
+
+	// Once a poll saw the server rebooting, ACTIVE does
+	cloud.SetProvisioningReads(3)
+	call("bizflycloud_hard_reboot_server", map[string]interface{}{"server_id": "srv-1"})
+	if task := getTask(t, call, "task-1"); task.Outcome != taskRunning || task.State != "HARD_REBOOT" {
+		t.Errorf("Expected the reboot to run while the server reboots, got %+v", task)
+	}
+	if task := getTask(t, call, "task-1"); task.Outcome != taskSucceeded || task.State != "ACTIVE" {
+		t.Errorf("Expected the reboot to succeed once the server is ACTIVE again, got %+v", task)
+	}
+	if task := getTask(t, call, "task-2"); task.Outcome != taskSucceeded {
+		t.Errorf("Expected the hard reboot that started in HARD_REBOOT to succeed, got %+v", task)
+	}
+}
+
+func TestGetTaskErrors(t *testing.T) {
+	_, s := newMockServer(t, testCloudState())
+	result := callTool(t, s, "bizflycloud_get_task", map[string]interface{}{"task_id": "task-42"})
+	if !result.IsError || result.Meta[errorCodeMeta] != string(ErrorValidation) || !strings.Contains(getTextFromResult(result), "unknown task task-42") {
+		t.Errorf("Expected a validation error for an unknown task, got: %s", getTextFromResult(result))
+	}
+
+	// Without the registry middleware there are no tasks to look at
+	result = callTool(t, newFullTestServer(t), "bizflycloud_list_tasks", map[string]interface{}{})
+	if !result.IsError || !strings.Contains(getTextFromResult(result), "task tracking is disabled") {
+		t.Errorf("Expected task tracking to be disabled, got: %s", getTextFromResult(result))
+	}
+}
+
+func TestTaskRegistryEviction(t *testing.T) {
+	registry := NewTaskRegistry()
+	registry.max = 2
+	first := registry.add(&Task{Outcome: taskRunning})
+	second := registry.add(&Task{Outcome: taskSucceeded})
+	registry.add(&Task{Outcome: taskRunning})
+	if _, ok := registry.Get(second.ID); ok {
+		t.Error("Expected the finished task to be forgotten first")
+	}
+	registry.add(&Task{Outcome: taskRunning})
+	if _, ok := registry.Get(first.ID); ok {
+		t.Error("Expected the oldest running task to be forgotten once none has finished")
+	}
+	if got := len(registry.List()); got != 2 {
+		t.Errorf("Expected 2 tasks, got %d", got)
+	}
+}
+
+// taskListOutput is the JSON output of bizflycloud_list_tasks
+type taskListOutput struct {
+	Tasks      []TaskOutput `json:"tasks"`
+	Total      int          `json:"total"`
+	NextCursor string       `json:"next_cursor"`
+}
+
+// newTaskTestServer returns a server with the task tools and a function
+// starting tasks in the session and profile of a context
+func newTaskTestServer(t *testing.T) (*TaskRegistry, *server.MCPServer, func(ctx context.Context) string) {
+	t.Helper()
+	registry := NewTaskRegistry()
+	s := server.NewMCPServer("BizflyCloud MCP Test", "1.0.0", server.WithToolHandlerMiddleware(registry.Middleware()))
+	RegisterTaskTools(s)
+	start := func(ctx context.Context) string {
+		call := &taskCall{registry: registry, request: createTestMCPRequest("bizflycloud_stop_server", nil)}
+		recordTask(context.WithValue(ctx, taskContextKey{}, call), nil, TaskSpec{Action: "stop", ResourceType: "server", ResourceID: "srv-1"})
+		return call.started[0]
+	}
+	return registry, s, start
+}
+
+// scopedContext returns the context of a call from the session with the profile
+func scopedContext(s *server.MCPServer, session, profile string) context.Context {
+	ctx := s.WithContext(context.Background(), &httpSession{id: session, notifications: make(chan mcp.JSONRPCNotification, 1)})
+	return context.WithValue(ctx, profileContextKey{}, &Profile{Name: profile})
+}
+
+// callTaskTool calls a task tool in JSON mode from the context's session and profile
+func callTaskTool(t *testing.T, s *server.MCPServer, ctx context.Context, name string, args map[string]interface{}) (string, bool) {
+	t.Helper()
+	args["format"] = "json"
+	message, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0", "id": 1, "method": "tools/call",
+		"params": map[string]interface{}{"name": name, "arguments": args},
+	})
+	response, ok := s.HandleMessage(ctx, message).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("Expected a result from %s", name)
+	}
+	result := response.Result.(mcp.CallToolResult)
+	return getTextFromResult(&result), result.IsError
+}
+
+func TestListTasksPages(t *testing.T) {
+	registry, s, start := newTaskTestServer(t)
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		start(ctx)
+	}
+
+	var ids []string
+	listed := make(map[string]bool)
+	args := map[string]interface{}{"limit": float64(2)}
+	for page := 0; page < 3; page++ {
+		text, _ := callTaskTool(t, s, ctx, "bizflycloud_list_tasks", args)
+		var output taskListOutput
+		if err := json.Unmarshal([]byte(text), &output); err != nil {
+			t.Fatalf("Expected JSON output, got %s", text)
+		}
+		if output.Total != 5 {
+			t.Errorf("Expected 5 tasks in total, got %d", output.Total)
+		}
+		for _, task := range output.Tasks {
+			ids = append(ids, task.ID)
+			listed[task.ID] = true
+		}
+
+		// Only the tasks of the pages listed so far have been polled
+		for _, task := range registry.List() {
+			if polled := !task.CheckedAt.IsZero(); polled != listed[task.ID] {
+				t.Errorf("Page %d: expected %s to be polled only once listed, polled: %v", page, task.ID, polled)
+			}
+		}
+		if output.NextCursor == "" {
+			break
+		}
+		args = map[string]interface{}{"limit": float64(2), "cursor": output.NextCursor}
+	}
+	if want := []string{"task-5", "task-4", "task-3", "task-2", "task-1"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expected the pages to list %v, got %v", want, ids)
+	}
+}
+
+func TestTasksAreScoped(t *testing.T) {
+	_, s, start := newTaskTestServer(t)
+	mine := scopedContext(s, "session-a", "prod")
+	task := start(mine)
+	start(scopedContext(s, "session-b", "prod"))
+
+	for _, tt := range []struct {
+		name string
+		ctx  context.Context
+		want int
+	}{
+		{"the starting session and profile", mine, 1},
+		{"another session", scopedContext(s, "session-c", "prod"), 0},
+		{"another profile", scopedContext(s, "session-a", "staging"), 0},
+	} {
+		text, _ := callTaskTool(t, s, tt.ctx, "bizflycloud_list_tasks", map[string]interface{}{})
+		var listed taskListOutput
+		if err := json.Unmarshal([]byte(text), &listed); err != nil {
+			t.Fatalf("Expected JSON output, got %s", text)
+		}
+		if listed.Total != tt.want {
+			t.Errorf("%s: expected %d tasks, got %d", tt.name, tt.want, listed.Total)
+		}
+	}
+
+	if text, failed := callTaskTool(t, s, mine, "bizflycloud_get_task", map[string]interface{}{"task_id": task}); failed {
+		t.Errorf("Expected the session to get its task, got %s", text)
+	}
+	if text, failed := callTaskTool(t, s, scopedContext(s, "session-c", "prod"), "bizflycloud_get_task", map[string]interface{}{"task_id": task}); !failed || !strings.Contains(text, "unknown task") {
+		t.Errorf("Expected another session's task to be unknown, got %s", text)
+	}
+}
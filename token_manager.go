@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bizflycloud/gobizfly"
+)
+
+const (
+	// defaultTokenRefreshWindow is how long before expiry the token is renewed
+	defaultTokenRefreshWindow = 5 * time.Minute
+
+	authTokenHeader = "X-Auth-Token"
+)
+
+// tokenExpiryLayouts are the timestamp formats Keystone uses for expire_at
+var tokenExpiryLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000000Z",
+	"2006-01-02T15:04:05.999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// TokenManager keeps the Keystone token of a gobizfly client fresh. It is
+// installed as the client's HTTP transport, so every API call made by the tool
+// handlers goes through it: the token is renewed shortly before it expires,
+// and a request rejected with 401 is retried once after re-authenticating.
+type TokenManager struct {
+	mu            sync.Mutex
+	client        *gobizfly.Client
+	request       *gobizfly.TokenCreateRequest
+	tokenFile     string
+	projectID     string
+	token         string
+	expiresAt     time.Time
+	refreshWindow time.Duration
+	next          http.RoundTripper
+	now           func() time.Time
+}
+
+// NewTokenManager creates a token manager that authenticates with the given credentials
+func NewTokenManager(creds *Credentials) *TokenManager {
+	m := &TokenManager{
+		request:       creds.TokenRequest(),
+		projectID:     creds.ProjectID,
+		refreshWindow: defaultTokenRefreshWindow,
+		next:          http.DefaultTransport,
+		now:           time.Now,
+	}
+	if creds.Method == authMethodTokenFile {
+		m.tokenFile = creds.TokenFile
+	}
+	return m
+}
+
+// HTTPClient returns an HTTP client that routes requests through the token manager.
+// Pass it to gobizfly.NewClient with gobizfly.WithHTTPClient.
+func (m *TokenManager) HTTPClient() *http.Client {
+	return &http.Client{Transport: m}
+}
+
+// Authenticate obtains the initial token for the client and starts managing it
+func (m *TokenManager) Authenticate(ctx context.Context, client *gobizfly.Client) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.client = client
+	return m.authenticateLocked(ctx)
+}
+
+// ExpiresAt returns the expiry of the current token, or the zero time if unknown
+func (m *TokenManager) ExpiresAt() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.expiresAt
+}
+
+// RoundTrip implements http.RoundTripper. The status of a failed response is
+// recorded in the request context, so tool errors can be classified by it.
+func (m *TokenManager) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := m.roundTrip(req)
+	if resp != nil {
+		recordAPIStatus(req.Context(), resp.StatusCode)
+	}
+	return resp, err
+}
+
+func (m *TokenManager) roundTrip(req *http.Request) (*http.Response, error) {
+	// Token and service catalog requests are issued by the re-authentication
+	// itself and must not recurse into it
+	if isAuthRequest(req) {
+		if m.tokenFile != "" && isTokenRequest(req) {
+			return m.tokenFileResponse(req)
+		}
+		return m.next.RoundTrip(req)
+	}
+
+	token, err := m.validToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := m.next.RoundTrip(withAuthToken(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// Only retry when the request body can be replayed
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	log.Printf("[INFO] Bizfly API returned 401, re-authenticating and retrying %s %s", req.Method, req.URL.Path)
+	freshToken, authErr := m.reauthenticate(req.Context(), token)
+	if authErr != nil {
+		log.Printf("[ERROR] Re-authentication failed: %v", authErr)
+		return resp, nil
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	retry := withAuthToken(req, freshToken)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	return m.next.RoundTrip(retry)
+}
+
+// validToken returns the current token, renewing it first when it is about to expire
+func (m *TokenManager) validToken(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.client != nil && !m.expiresAt.IsZero() && m.now().Add(m.refreshWindow).After(m.expiresAt) {
+		log.Printf("[INFO] Keystone token expires at %s, refreshing", m.expiresAt.Format(time.RFC3339))
+		if err := m.authenticateLocked(ctx); err != nil {
+			return "", fmt.Errorf("failed to refresh token: %w", err)
+		}
+	}
+	return m.token, nil
+}
+
+// reauthenticate renews the token after a 401. Concurrent callers that saw the
+// same stale token share a single re-authentication.
+func (m *TokenManager) reauthenticate(ctx context.Context, staleToken string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.client == nil {
+		return "", fmt.Errorf("token manager is not attached to a client")
+	}
+	if m.token != staleToken {
+		return m.token, nil
+	}
+	if err := m.authenticateLocked(ctx); err != nil {
+		return "", err
+	}
+	return m.token, nil
+}
+
+func (m *TokenManager) authenticateLocked(ctx context.Context) error {
+	token, err := m.client.Token.Init(ctx, m.request)
+	if err != nil {
+		return err
+	}
+	m.client.SetKeystoneToken(token)
+	m.token = token.KeystoneToken
+	m.expiresAt = parseTokenExpiry(token.ExpiresAt)
+	return nil
+}
+
+// tokenFileResponse answers a token request from the pre-issued token file
+// instead of Keystone. gobizfly still loads the service catalog through its
+// usual Token.Init path, and re-authenticating picks up a rotated file.
+func (m *TokenManager) tokenFileResponse(req *http.Request) (*http.Response, error) {
+	token, err := readTokenFile(m.tokenFile, m.projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	body, err := json.Marshal(token)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// isTokenRequest reports whether the request targets the token endpoint
+func isTokenRequest(req *http.Request) bool {
+	return strings.HasSuffix(strings.TrimSuffix(req.URL.Path, "/"), "/api/token")
+}
+
+// isAuthRequest reports whether the request targets the token or service catalog endpoints
+func isAuthRequest(req *http.Request) bool {
+	return isTokenRequest(req) || strings.HasSuffix(strings.TrimSuffix(req.URL.Path, "/"), "/api/auth/service")
+}
+
+// withAuthToken returns a copy of the request carrying the given token
+func withAuthToken(req *http.Request, token string) *http.Request {
+	clone := req.Clone(req.Context())
+	if token != "" {
+		clone.Header.Set(authTokenHeader, token)
+	}
+	return clone
+}
+
+// parseTokenExpiry parses Keystone's expire_at, returning the zero time if it is unknown
+func parseTokenExpiry(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range tokenExpiryLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	log.Printf("[WARN] Could not parse token expiry %q, relying on 401 responses to refresh", value)
+	return time.Time{}
+}
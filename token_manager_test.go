@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bizflycloud/gobizfly"
+)
+
+// fakeKeystone issues numbered tokens and only accepts the latest one
+type fakeKeystone struct {
+	mu        sync.Mutex
+	issued    int
+	valid     string
+	expiresIn time.Duration
+}
+
+func (k *fakeKeystone) revoke() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.valid = ""
+}
+
+func (k *fakeKeystone) issuedCount() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.issued
+}
+
+func newFakeKeystoneServer(t *testing.T, k *fakeKeystone) *httptest.Server {
+	t.Helper()
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/token":
+			k.mu.Lock()
+			k.issued++
+			k.valid = fmt.Sprintf("token-%d", k.issued)
+			resp := map[string]string{
+				"token":      k.valid,
+				"project_id": "project-1",
+				"expire_at":  time.Now().Add(k.expiresIn).UTC().Format(time.RFC3339),
+			}
+			k.mu.Unlock()
+			json.NewEncoder(w).Encode(resp)
+		case "/api/auth/service":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"services": []map[string]interface{}{
+					{"canonical_name": "cloud_server", "region": "HaNoi", "enabled": true, "service_url": ts.URL + "/iaas-cloud/api"},
+					{"canonical_name": "cloud_server", "region": "HoChiMinh", "enabled": true, "service_url": ts.URL + "/iaas-cloud/api"},
+				},
+			})
+		case "/iaas-cloud/api/servers":
+			k.mu.Lock()
+			ok := k.valid != "" && r.Header.Get(authTokenHeader) == k.valid
+			k.mu.Unlock()
+			if !ok {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"message":"token expired"}`))
+				return
+			}
+			w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return ts
+}
+
+func newManagedTestClient(t *testing.T, url string) (*gobizfly.Client, *TokenManager) {
+	t.Helper()
+	tokens := NewTokenManager(&Credentials{
+		Method:   authMethodPassword,
+		Username: "user",
+		Password: "secret",
+	})
+	client, err := gobizfly.NewClient(
+		gobizfly.WithAPIURL(url),
+		gobizfly.WithRegionName("HaNoi"),
+		gobizfly.WithHTTPClient(tokens.HTTPClient()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if err := tokens.Authenticate(context.Background(), client); err != nil {
+		t.Fatalf("Failed to authenticate: %v", err)
+	}
+	return client, tokens
+}
+
+func TestTokenManagerRetriesAfterUnauthorized(t *testing.T) {
+	keystone := &fakeKeystone{expiresIn: time.Hour}
+	ts := newFakeKeystoneServer(t, keystone)
+	defer ts.Close()
+
+	client, _ := newManagedTestClient(t, ts.URL)
+
+	if _, err := client.CloudServer.List(context.Background(), &gobizfly.ServerListOptions{}); err != nil {
+		t.Fatalf("Expected first call to succeed, got %v", err)
+	}
+
+	// The token is revoked server-side; the next call must re-authenticate once
+	keystone.revoke()
+	if _, err := client.CloudServer.List(context.Background(), &gobizfly.ServerListOptions{}); err != nil {
+		t.Fatalf("Expected call to succeed after re-authentication, got %v", err)
+	}
+	if got := keystone.issuedCount(); got != 2 {
+		t.Errorf("Expected 2 tokens to be issued, got %d", got)
+	}
+}
+
+func TestTokenManagerRefreshesBeforeExpiry(t *testing.T) {
+	keystone := &fakeKeystone{expiresIn: time.Minute}
+	ts := newFakeKeystoneServer(t, keystone)
+	defer ts.Close()
+
+	client, tokens := newManagedTestClient(t, ts.URL)
+	if tokens.ExpiresAt().IsZero() {
+		t.Fatal("Expected token expiry to be tracked")
+	}
+
+	// The token expires inside the refresh window, so it is renewed up front
+	if _, err := client.CloudServer.List(context.Background(), &gobizfly.ServerListOptions{}); err != nil {
+		t.Fatalf("Expected call to succeed, got %v", err)
+	}
+	if got := keystone.issuedCount(); got != 2 {
+		t.Errorf("Expected token to be refreshed before the call, got %d tokens issued", got)
+	}
+}
+
+func TestParseTokenExpiry(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Time
+	}{
+		{"rfc3339", "2025-01-02T03:04:05Z", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"keystone microseconds", "2025-01-02T03:04:05.000000Z", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"space separated", "2025-01-02 03:04:05", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"empty", "", time.Time{}},
+		{"garbage", "tomorrow", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseTokenExpiry(tt.value); !got.Equal(tt.want) {
+				t.Errorf("parseTokenExpiry(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Tool access levels
+const (
+	// accessRead tools only look at infrastructure
+	accessRead = "read"
+	// accessWrite tools create or change infrastructure
+	accessWrite = "write"
+	// accessDestructive tools permanently delete infrastructure and need confirmation
+	accessDestructive = "destructive"
+)
+
+// Service groups tools belong to
+const (
+	serviceServer            = "server"
+	serviceVolume            = "volume"
+	serviceKubernetes        = "kubernetes"
+	serviceDatabase          = "database"
+	serviceLoadBalancer      = "loadbalancer"
+	serviceDNS               = "dns"
+	serviceCDN               = "cdn"
+	serviceKMS               = "kms"
+	serviceContainerRegistry = "container_registry"
+	serviceAutoScaling       = "autoscaling"
+	serviceAlert             = "alert"
+	serviceSummary           = "summary"
+	serviceStatus            = "status"
+	serviceAccount           = "account"
+)
+
+// toolSpec classifies a tool by the service it belongs to and how it affects infrastructure
+type toolSpec struct {
+	service string
+	access  string
+}
+
+// toolCatalog classifies every tool the server registers. A tool missing from
+// the catalog is treated as mutating, so new tools must be added here.
+var toolCatalog = map[string]toolSpec{
+	// Servers
+	"bizflycloud_list_servers":       {serviceServer, accessRead},
+	"bizflycloud_get_server":         {serviceServer, accessRead},
+	"bizflycloud_list_flavors":       {serviceServer, accessRead},
+	"bizflycloud_refresh_catalog":    {serviceServer, accessRead},
+	"bizflycloud_create_server":      {serviceServer, accessWrite},
+	"bizflycloud_start_server":       {serviceServer, accessWrite},
+	"bizflycloud_stop_server":        {serviceServer, accessWrite},
+	"bizflycloud_reboot_server":      {serviceServer, accessWrite},
+	"bizflycloud_hard_reboot_server": {serviceServer, accessWrite},
+	"bizflycloud_resize_server":      {serviceServer, accessWrite},
+	"bizflycloud_delete_server":      {serviceServer, accessDestructive},
+
+	// Volumes and snapshots
+	"bizflycloud_list_volumes":      {serviceVolume, accessRead},
+	"bizflycloud_get_volume":        {serviceVolume, accessRead},
+	"bizflycloud_list_volume_types": {serviceVolume, accessRead},
+	"bizflycloud_list_snapshots":    {serviceVolume, accessRead},
+	"bizflycloud_create_volume":     {serviceVolume, accessWrite},
+	"bizflycloud_resize_volume":     {serviceVolume, accessWrite},
+	"bizflycloud_attach_volume":     {serviceVolume, accessWrite},
+	"bizflycloud_detach_volume":     {serviceVolume, accessWrite},
+	"bizflycloud_delete_volume":     {serviceVolume, accessDestructive},
+	"bizflycloud_create_snapshot":   {serviceVolume, accessWrite},
+	"bizflycloud_delete_snapshot":   {serviceVolume, accessDestructive},
+
+	// Kubernetes
+	"bizflycloud_list_kubernetes_clusters":  {serviceKubernetes, accessRead},
+	"bizflycloud_get_kubernetes_cluster":    {serviceKubernetes, accessRead},
+	"bizflycloud_list_kubernetes_nodes":     {serviceKubernetes, accessRead},
+	"bizflycloud_create_kubernetes_cluster": {serviceKubernetes, accessWrite},
+	"bizflycloud_delete_kubernetes_cluster": {serviceKubernetes, accessDestructive},
+	"bizflycloud_update_kubernetes_pool":    {serviceKubernetes, accessWrite},
+	"bizflycloud_resize_kubernetes_pool":    {serviceKubernetes, accessWrite},
+	"bizflycloud_delete_kubernetes_pool":    {serviceKubernetes, accessDestructive},
+
+	// Databases
+	"bizflycloud_list_databases":         {serviceDatabase, accessRead},
+	"bizflycloud_get_database":           {serviceDatabase, accessRead},
+	"bizflycloud_list_datastores":        {serviceDatabase, accessRead},
+	"bizflycloud_list_database_nodes":    {serviceDatabase, accessRead},
+	"bizflycloud_list_database_backups":  {serviceDatabase, accessRead},
+	"bizflycloud_create_database":        {serviceDatabase, accessWrite},
+	"bizflycloud_delete_database":        {serviceDatabase, accessDestructive},
+	"bizflycloud_create_database_backup": {serviceDatabase, accessWrite},
+
+	// Load balancers
+	"bizflycloud_list_loadbalancers":  {serviceLoadBalancer, accessRead},
+	"bizflycloud_get_loadbalancer":    {serviceLoadBalancer, accessRead},
+	"bizflycloud_create_loadbalancer": {serviceLoadBalancer, accessWrite},
+	"bizflycloud_update_loadbalancer": {serviceLoadBalancer, accessWrite},
+	"bizflycloud_delete_loadbalancer": {serviceLoadBalancer, accessDestructive},
+
+	// DNS
+	"bizflycloud_list_dns_zones":    {serviceDNS, accessRead},
+	"bizflycloud_get_dns_zone":      {serviceDNS, accessRead},
+	"bizflycloud_get_dns_record":    {serviceDNS, accessRead},
+	"bizflycloud_create_dns_zone":   {serviceDNS, accessWrite},
+	"bizflycloud_delete_dns_zone":   {serviceDNS, accessDestructive},
+	"bizflycloud_create_dns_record": {serviceDNS, accessWrite},
+	"bizflycloud_delete_dns_record": {serviceDNS, accessDestructive},
+
+	// CDN
+	"bizflycloud_list_cdn_domains":  {serviceCDN, accessRead},
+	"bizflycloud_get_cdn_domain":    {serviceCDN, accessRead},
+	"bizflycloud_create_cdn_domain": {serviceCDN, accessWrite},
+	"bizflycloud_update_cdn_domain": {serviceCDN, accessWrite},
+	"bizflycloud_delete_cdn_domain": {serviceCDN, accessDestructive},
+	"bizflycloud_delete_cdn_cache":  {serviceCDN, accessWrite},
+
+	// KMS
+	"bizflycloud_list_kms_certificates":  {serviceKMS, accessRead},
+	"bizflycloud_get_kms_certificate":    {serviceKMS, accessRead},
+	"bizflycloud_create_kms_certificate": {serviceKMS, accessWrite},
+	"bizflycloud_delete_kms_certificate": {serviceKMS, accessDestructive},
+
+	// Container registry
+	"bizflycloud_list_container_registries":     {serviceContainerRegistry, accessRead},
+	"bizflycloud_list_container_registry_tags":  {serviceContainerRegistry, accessRead},
+	"bizflycloud_get_container_registry_tag":    {serviceContainerRegistry, accessRead},
+	"bizflycloud_create_container_registry":     {serviceContainerRegistry, accessWrite},
+	"bizflycloud_update_container_registry":     {serviceContainerRegistry, accessWrite},
+	"bizflycloud_delete_container_registry":     {serviceContainerRegistry, accessDestructive},
+	"bizflycloud_delete_container_registry_tag": {serviceContainerRegistry, accessDestructive},
+
+	// AutoScaling
+	"bizflycloud_list_autoscaling_groups":  {serviceAutoScaling, accessRead},
+	"bizflycloud_get_autoscaling_group":    {serviceAutoScaling, accessRead},
+	"bizflycloud_create_autoscaling_group": {serviceAutoScaling, accessWrite},
+	"bizflycloud_delete_autoscaling_group": {serviceAutoScaling, accessDestructive},
+
+	// Alerts
+	"bizflycloud_list_alarms":    {serviceAlert, accessRead},
+	"bizflycloud_get_alarm":      {serviceAlert, accessRead},
+	"bizflycloud_list_receivers": {serviceAlert, accessRead},
+	"bizflycloud_get_receiver":   {serviceAlert, accessRead},
+
+	// Resource summary
+	"bizflycloud_list_all_resources": {serviceSummary, accessRead},
+
+	// Resource status
+	"bizflycloud_wait_for_status": {serviceStatus, accessRead},
+	"bizflycloud_list_tasks":      {serviceStatus, accessRead},
+	"bizflycloud_get_task":        {serviceStatus, accessRead},
+
+	// Profiles, regions and truncated output
+	"bizflycloud_list_profiles": {serviceAccount, accessRead},
+	"bizflycloud_list_regions":  {serviceAccount, accessRead},
+	"bizflycloud_fetch_more":    {serviceAccount, accessRead},
+}
+
+// isReadOnlyTool reports whether the tool is classified as non-mutating
+func isReadOnlyTool(name string) bool {
+	spec, ok := toolCatalog[name]
+	return ok && spec.access == accessRead
+}
+
+// isDestructiveTool reports whether the tool permanently deletes infrastructure
+func isDestructiveTool(name string) bool {
+	return toolCatalog[name].access == accessDestructive
+}
+
+// ApplyReadOnly unregisters every mutating tool so it doesn't appear in tools/list
+func ApplyReadOnly(s *server.MCPServer) []string {
+	var removed []string
+	for _, name := range registeredToolNames(s) {
+		if !isReadOnlyTool(name) {
+			removed = append(removed, name)
+		}
+	}
+	s.DeleteTools(removed...)
+	return removed
+}
+
+// readOnlyMiddleware refuses mutating tool calls. It backs up ApplyReadOnly so
+// a write tool can never run in read-only mode, even if it is registered later.
+func readOnlyMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !isReadOnlyTool(request.Params.Name) {
+				return mcp.NewToolResultError(fmt.Sprintf("Tool %s is not allowed: the server is running in read-only mode and this tool can change infrastructure", request.Params.Name)), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// registeredToolNames returns the sorted names of the tools registered on the server
+func registeredToolNames(s *server.MCPServer) []string {
+	response := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil
+	}
+	var list struct {
+		Result mcp.ListToolsResult `json:"result"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(list.Result.Tools))
+	for _, tool := range list.Result.Tools {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newFullTestServer creates a test server with every tool registered
+func newFullTestServer(t *testing.T) *server.MCPServer {
+	t.Helper()
+	s := createTestMCPServer()
+	client, _ := gobizfly.NewClient()
+	registerTools(s, client, NewClientPool(&Config{}), nil)
+	return s
+}
+
+func TestToolCatalogCoversEveryTool(t *testing.T) {
+	s := newFullTestServer(t)
+	registered := make(map[string]bool)
+	for _, name := range registeredToolNames(s) {
+		registered[name] = true
+		if _, ok := toolCatalog[name]; !ok {
+			t.Errorf("Tool %s is not classified in toolCatalog", name)
+		}
+	}
+	for name := range toolCatalog {
+		if !registered[name] {
+			t.Errorf("toolCatalog lists %s, which is not registered", name)
+		}
+	}
+}
+
+func TestToolCatalogClassification(t *testing.T) {
+	for name, spec := range toolCatalog {
+		switch {
+		case strings.HasPrefix(name, "bizflycloud_list_") || strings.HasPrefix(name, "bizflycloud_get_") || name == fetchMoreTool || name == refreshCatalogTool || name == waitForStatusTool:
+			if spec.access != accessRead {
+				t.Errorf("Expected %s to be classified as read", name)
+			}
+		case strings.HasPrefix(name, "bizflycloud_delete_") && name != "bizflycloud_delete_cdn_cache":
+			if spec.access != accessDestructive {
+				t.Errorf("Expected %s to be classified as destructive", name)
+			}
+		default:
+			if spec.access != accessWrite {
+				t.Errorf("Expected %s to be classified as write", name)
+			}
+		}
+	}
+}
+
+func TestApplyReadOnly(t *testing.T) {
+	s := newFullTestServer(t)
+	removed := ApplyReadOnly(s)
+	if len(removed) == 0 {
+		t.Fatal("Expected mutating tools to be removed")
+	}
+
+	remaining := registeredToolNames(s)
+	for _, name := range remaining {
+		if !isReadOnlyTool(name) {
+			t.Errorf("Expected %s to be removed in read-only mode", name)
+		}
+	}
+	for _, want := range []string{"bizflycloud_list_servers", "bizflycloud_get_server", "bizflycloud_list_all_resources"} {
+		found := false
+		for _, name := range remaining {
+			found = found || name == want
+		}
+		if !found {
+			t.Errorf("Expected %s to stay registered", want)
+		}
+	}
+}
+
+func TestReadOnlyMiddleware(t *testing.T) {
+	called := false
+	handler := readOnlyMiddleware()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = true
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	tests := []struct {
+		name    string
+		tool    string
+		allowed bool
+	}{
+		{"read tool", "bizflycloud_list_servers", true},
+		{"delete tool", "bizflycloud_delete_server", false},
+		{"resize tool", "bizflycloud_resize_volume", false},
+		{"unclassified tool", "bizflycloud_unknown", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = false
+			result, err := handler(context.Background(), createTestMCPRequest(tt.tool, map[string]interface{}{}))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if called != tt.allowed {
+				t.Errorf("Expected handler called=%v, got %v", tt.allowed, called)
+			}
+			if !tt.allowed {
+				verifyToolError(t, result, "read-only mode")
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bizflycloud/gobizfly"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// serviceRegistration registers the tools of one service group
+type serviceRegistration struct {
+	service  string
+	register func(s *server.MCPServer, client *gobizfly.Client, pool *ClientPool)
+}
+
+// serviceRegistrations lists every service group in registration order
+var serviceRegistrations = []serviceRegistration{
+	{serviceServer, func(s *server.MCPServer, client *gobizfly.Client, _ *ClientPool) {
+		RegisterServerTools(s, client)
+		RegisterCatalogTools(s, client)
+	}},
+	{serviceVolume, func(s *server.MCPServer, client *gobizfly.Client, _ *ClientPool) { RegisterVolumeTools(s, client) }},
+	{serviceKubernetes, func(s *server.MCPServer, client *gobizfly.Client, _ *ClientPool) { RegisterKubernetesTools(s, client) }},
+	{serviceDatabase, func(s *server.MCPServer, client *gobizfly.Client, _ *ClientPool) { RegisterDatabaseTools(s, client) }},
+	{serviceLoadBalancer, func(s *server.MCPServer, client *gobizfly.Client, _ *ClientPool) {
+		RegisterLoadBalancerTools(s, client)
+	}},
+	{serviceDNS, func(s *server.MCPServer, client *gobizfly.Client, _ *ClientPool) { RegisterDNSTools(s, client) }},
+	{serviceCDN, func(s *server.MCPServer, client *gobizfly.Client, _ *ClientPool) { RegisterCDNTools(s, client) }},
+	{serviceKMS, func(s *server.MCPServer, client *gobizfly.Client, _ *ClientPool) { RegisterKMSTools(s, client) }},
+	{serviceContainerRegistry, func(s *server.MCPServer, client *gobizfly.Client, _ *ClientPool) {
+		RegisterContainerRegistryTools(s, client)
+	}},
+	{serviceAutoScaling, func(s *server.MCPServer, client *gobizfly.Client, _ *ClientPool) { RegisterAutoScalingTools(s, client) }},
+	{serviceAlert, func(s *server.MCPServer, client *gobizfly.Client, _ *ClientPool) { RegisterAlertTools(s, client) }},
+	{serviceSummary, func(s *server.MCPServer, client *gobizfly.Client, _ *ClientPool) {
+		RegisterResourceSummaryTools(s, client)
+	}},
+	{serviceStatus, func(s *server.MCPServer, client *gobizfly.Client, _ *ClientPool) {
+		RegisterStatusTools(s, client)
+		RegisterTaskTools(s)
+	}},
+	{serviceAccount, func(s *server.MCPServer, _ *gobizfly.Client, pool *ClientPool) {
+		RegisterRegionTools(s, pool)
+		RegisterProfileTools(s, pool)
+		RegisterOutputTools(s)
+	}},
+}
+
+// serviceAliases maps the other names operators use for a service group to its canonical name
+var serviceAliases = map[string]string{
+	"servers":              serviceServer,
+	"volumes":              serviceVolume,
+	"snapshots":            serviceVolume,
+	"k8s":                  serviceKubernetes,
+	"databases":            serviceDatabase,
+	"loadbalancers":        serviceLoadBalancer,
+	"lb":                   serviceLoadBalancer,
+	"container_registries": serviceContainerRegistry,
+	"registry":             serviceContainerRegistry,
+	"alerts":               serviceAlert,
+	"cloudwatcher":         serviceAlert,
+	"resource_summary":     serviceSummary,
+	"accounts":             serviceAccount,
+}
+
+// ToolFilter selects which tools the server registers. Services enables only
+// the listed service groups (all when empty) and DisableServices turns groups
+// off; Allow and Deny are glob patterns such as bizflycloud_delete_* matched
+// against tool names, with Deny taking precedence.
+type ToolFilter struct {
+	Services        []string `yaml:"services" toml:"services"`
+	DisableServices []string `yaml:"disable_services" toml:"disable_services"`
+	Allow           []string `yaml:"allow" toml:"allow"`
+	Deny            []string `yaml:"deny" toml:"deny"`
+}
+
+// Validate normalises service names and checks that every service and pattern is usable
+func (f *ToolFilter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	for _, services := range []*[]string{&f.Services, &f.DisableServices} {
+		for i, name := range *services {
+			service, ok := normalizeService(name)
+			if !ok {
+				return fmt.Errorf("unknown service %q (available: %s)", name, strings.Join(ServiceNames(), ", "))
+			}
+			(*services)[i] = service
+		}
+	}
+	for _, pattern := range append(append([]string{}, f.Allow...), f.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// ServiceEnabled reports whether the service group's tools should be registered
+func (f *ToolFilter) ServiceEnabled(service string) bool {
+	if f == nil {
+		return true
+	}
+	for _, disabled := range f.DisableServices {
+		if disabled == service {
+			return false
+		}
+	}
+	// The account tools only describe the server's own profiles and regions and
+	// page through its output, so they stay available unless they are disabled explicitly
+	if len(f.Services) == 0 || service == serviceAccount {
+		return true
+	}
+	for _, enabled := range f.Services {
+		if enabled == service {
+			return true
+		}
+	}
+	return false
+}
+
+// ToolAllowed reports whether the tool name passes the allow and deny patterns
+func (f *ToolFilter) ToolAllowed(name string) bool {
+	if f == nil {
+		return true
+	}
+	if matchesAny(f.Deny, name) {
+		return false
+	}
+	return len(f.Allow) == 0 || matchesAny(f.Allow, name)
+}
+
+// ServiceNames returns the canonical names of the service groups
+func ServiceNames() []string {
+	names := make([]string, 0, len(serviceRegistrations))
+	for _, registration := range serviceRegistrations {
+		names = append(names, registration.service)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// registerTools registers the tools of every enabled service group, then drops
+// the individual tools the filter excludes so they don't appear in tools/list
+func registerTools(s *server.MCPServer, client *gobizfly.Client, pool *ClientPool, filter *ToolFilter) {
+	for _, registration := range serviceRegistrations {
+		if filter.ServiceEnabled(registration.service) {
+			registration.register(s, client, pool)
+		}
+	}
+
+	var excluded []string
+	for _, name := range registeredToolNames(s) {
+		if !filter.ToolAllowed(name) {
+			excluded = append(excluded, name)
+		}
+	}
+	s.DeleteTools(excluded...)
+}
+
+// normalizeService returns the canonical name of a service group
+func normalizeService(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.ReplaceAll(name, "-", "_")
+	if alias, ok := serviceAliases[name]; ok {
+		return alias, true
+	}
+	for _, registration := range serviceRegistrations {
+		if registration.service == name {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a comma separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/bizflycloud/gobizfly"
+)
+
+func registeredWithFilter(t *testing.T, filter *ToolFilter) map[string]bool {
+	t.Helper()
+	if err := filter.Validate(); err != nil {
+		t.Fatalf("Unexpected validation error: %v", err)
+	}
+	s := createTestMCPServer()
+	client, _ := gobizfly.NewClient()
+	registerTools(s, client, NewClientPool(&Config{}), filter)
+
+	names := make(map[string]bool)
+	for _, name := range registeredToolNames(s) {
+		names[name] = true
+	}
+	return names
+}
+
+func TestRegisterToolsByService(t *testing.T) {
+	names := registeredWithFilter(t, &ToolFilter{Services: []string{"servers", "volume", "DNS"}})
+
+	for name := range names {
+		switch toolCatalog[name].service {
+		case serviceServer, serviceVolume, serviceDNS, serviceAccount:
+		default:
+			t.Errorf("Expected %s to be filtered out", name)
+		}
+	}
+	for _, want := range []string{"bizflycloud_list_servers", "bizflycloud_create_volume", "bizflycloud_list_dns_zones", "bizflycloud_list_regions"} {
+		if !names[want] {
+			t.Errorf("Expected %s to be registered", want)
+		}
+	}
+}
+
+func TestRegisterToolsDisableServices(t *testing.T) {
+	names := registeredWithFilter(t, &ToolFilter{DisableServices: []string{"k8s", "account"}})
+
+	if names["bizflycloud_list_kubernetes_clusters"] || names["bizflycloud_list_regions"] {
+		t.Error("Expected disabled services to be filtered out")
+	}
+	if !names["bizflycloud_list_servers"] {
+		t.Error("Expected other services to stay registered")
+	}
+}
+
+func TestRegisterToolsByPattern(t *testing.T) {
+	t.Run("deny", func(t *testing.T) {
+		names := registeredWithFilter(t, &ToolFilter{Deny: []string{"bizflycloud_delete_*"}})
+		for name := range names {
+			if contains(name, "_delete_") {
+				t.Errorf("Expected %s to be denied", name)
+			}
+		}
+		if !names["bizflycloud_create_server"] {
+			t.Error("Expected create tools to stay registered")
+		}
+	})
+
+	t.Run("allow with deny taking precedence", func(t *testing.T) {
+		names := registeredWithFilter(t, &ToolFilter{
+			Allow: []string{"bizflycloud_list_*", "bizflycloud_get_server"},
+			Deny:  []string{"bizflycloud_list_all_resources"},
+		})
+		if !names["bizflycloud_list_volumes"] || !names["bizflycloud_get_server"] {
+			t.Error("Expected allowed tools to be registered")
+		}
+		if names["bizflycloud_get_volume"] || names["bizflycloud_list_all_resources"] {
+			t.Error("Expected tools outside the allowlist or in the denylist to be filtered out")
+		}
+	})
+}
+
+func TestToolFilterValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  ToolFilter
+		wantErr string
+	}{
+		{"unknown service", ToolFilter{Services: []string{"mainframe"}}, "unknown service"},
+		{"unknown disabled service", ToolFilter{DisableServices: []string{"mainframe"}}, "unknown service"},
+		{"bad pattern", ToolFilter{Deny: []string{"bizflycloud_[delete"}}, "invalid tool pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if err == nil || !contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigToolFilter(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+profiles:
+  prod:
+    username: user
+    password: secret
+tools:
+  services: [servers, dns]
+  deny: ["bizflycloud_delete_*"]
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !cfg.Tools.ServiceEnabled(serviceServer) || cfg.Tools.ServiceEnabled(serviceVolume) {
+		t.Errorf("Unexpected services: %v", cfg.Tools.Services)
+	}
+	if cfg.Tools.ToolAllowed("bizflycloud_delete_server") {
+		t.Error("Expected delete tools to be denied")
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	got := splitList(" server, volume,,dns ")
+	if len(got) != 3 || got[0] != "server" || got[2] != "dns" {
+		t.Errorf("Unexpected split: %v", got)
+	}
+}
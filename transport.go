@@ -0,0 +1,515 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	transportStdio = "stdio"
+	transportSSE   = "sse"
+	transportHTTP  = "http"
+
+	defaultListenAddr      = ":8080"
+	defaultShutdownTimeout = 10 * time.Second
+
+	// httpEndpoint is the path the streamable HTTP transport is served on
+	httpEndpoint = "/mcp"
+	// healthEndpoint answers liveness probes for HTTP based transports
+	healthEndpoint = "/healthz"
+
+	sessionIDHeader = "Mcp-Session-Id"
+	maxMessageBytes = 4 << 20
+
+	// defaultSessionIdleTimeout is how long a streamable HTTP session lives without requests
+	defaultSessionIdleTimeout = 30 * time.Minute
+
+	// methodNotificationCancelled is sent by a client that no longer wants the
+	// result of one of its requests; the SDK doesn't handle it itself
+	methodNotificationCancelled = "notifications/cancelled"
+)
+
+// errRequestCancelled ends the context of a request the client cancelled
+var errRequestCancelled = errors.New("the client cancelled the request")
+
+// TransportConfig holds the options used to expose the MCP server
+type TransportConfig struct {
+	Mode            string
+	ListenAddr      string
+	BaseURL         string
+	TLSCertFile     string
+	TLSKeyFile      string
+	AuthToken       string
+	ShutdownTimeout time.Duration
+}
+
+// Validate checks that the transport configuration is usable
+func (c TransportConfig) Validate() error {
+	switch c.Mode {
+	case transportStdio, transportSSE, transportHTTP:
+	default:
+		return fmt.Errorf("unknown transport %q (expected stdio, sse or http)", c.Mode)
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return errors.New("both --tls-cert and --tls-key must be set to enable TLS")
+	}
+	return nil
+}
+
+// Serve exposes the MCP server over the configured transport and blocks until
+// the transport stops. HTTP based transports shut down gracefully on SIGINT/SIGTERM.
+// Resource subscriptions are only served over stdio, the one transport that can
+// push notifications outside of a request; subscriptions may be nil.
+func Serve(s *server.MCPServer, subscriptions *ResourceSubscriptions, cfg TransportConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.Mode == transportStdio {
+		return serveStdio(ctx, s, subscriptions, os.Stdin, os.Stdout)
+	}
+
+	if cfg.ListenAddr == "" {
+		cfg.ListenAddr = defaultListenAddr
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = defaultShutdownTimeout
+	}
+
+	httpServer := &http.Server{
+		Addr:              cfg.ListenAddr,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	var shutdown func(context.Context) error
+	mux := http.NewServeMux()
+	mux.HandleFunc(healthEndpoint, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok\n"))
+	})
+
+	switch cfg.Mode {
+	case transportSSE:
+		sseServer := server.NewSSEServer(s,
+			server.WithBaseURL(cfg.BaseURL),
+			server.WithHTTPServer(httpServer),
+			server.WithKeepAlive(true),
+		)
+		mux.Handle("/", requireBearerToken(cfg.AuthToken, sseServer))
+		shutdown = sseServer.Shutdown
+	case transportHTTP:
+		mux.Handle(httpEndpoint, requireBearerToken(cfg.AuthToken, NewStreamableHTTPHandler(s)))
+		shutdown = httpServer.Shutdown
+	}
+	httpServer.Handler = mux
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("[INFO] Serving MCP over %s on %s", cfg.Mode, cfg.ListenAddr)
+		var err error
+		if cfg.TLSCertFile != "" {
+			err = httpServer.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+		} else {
+			err = httpServer.ListenAndServe()
+		}
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("[INFO] Shutting down %s transport", cfg.Mode)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("graceful shutdown failed: %w", err)
+	}
+	return <-errCh
+}
+
+// serveStdio serves newline-delimited JSON-RPC messages read from in until in is
+// closed or ctx is done. It works like the SDK's stdio server but also answers
+// resources/subscribe and resources/unsubscribe, which the SDK doesn't handle,
+// and sends the update notifications of the subscriptions. Tool calls, which
+// may wait for a resource for a long time, run apart from the loop reading
+// messages, and a notifications/cancelled for one cancels its context.
+func serveStdio(ctx context.Context, s *server.MCPServer, subscriptions *ResourceSubscriptions, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	session := &stdioSession{notifications: make(chan mcp.JSONRPCNotification, 100)}
+	if err := s.RegisterSession(ctx, session); err != nil {
+		return fmt.Errorf("register session: %w", err)
+	}
+	defer s.UnregisterSession(session.SessionID())
+	ctx = s.WithContext(ctx, session)
+
+	var mu sync.Mutex
+	write := func(message any) {
+		data, err := json.Marshal(message)
+		if err != nil {
+			log.Printf("[ERROR] Failed to encode message: %v", err)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if _, err := fmt.Fprintf(out, "%s\n", data); err != nil {
+			log.Printf("[ERROR] Failed to write message: %v", err)
+		}
+	}
+
+	go func() {
+		for {
+			select {
+			case notification := <-session.notifications:
+				write(notification)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	if subscriptions != nil {
+		go subscriptions.Run(ctx, session.notifications)
+	}
+
+	// Reading blocks, so it runs apart from the loop that watches ctx
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadString('\n')
+			if strings.TrimSpace(line) != "" {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	// The tool calls still running when in is closed are answered before
+	// returning; when ctx is done, they are cancelled with it
+	var calls sync.WaitGroup
+	var callsMu sync.Mutex
+	running := make(map[string]context.CancelCauseFunc)
+	defer calls.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case line := <-lines:
+			var message json.RawMessage
+			if err := json.Unmarshal([]byte(line), &message); err != nil {
+				write(mcp.NewJSONRPCError(nil, mcp.PARSE_ERROR, "Parse error", nil))
+				continue
+			}
+			var request struct {
+				ID     json.RawMessage `json:"id"`
+				Method string          `json:"method"`
+				Params struct {
+					RequestID json.RawMessage `json:"requestId"`
+				} `json:"params"`
+			}
+			_ = json.Unmarshal(message, &request)
+			switch request.Method {
+			case string(mcp.MethodToolsCall):
+				id := string(request.ID)
+				callCtx, cancelCall := context.WithCancelCause(ctx)
+				callsMu.Lock()
+				running[id] = cancelCall
+				callsMu.Unlock()
+				calls.Add(1)
+				go func() {
+					defer calls.Done()
+					defer cancelCall(nil)
+					response := s.HandleMessage(callCtx, message)
+					callsMu.Lock()
+					delete(running, id)
+					callsMu.Unlock()
+					// A cancelled request isn't answered
+					if response != nil && !errors.Is(context.Cause(callCtx), errRequestCancelled) {
+						write(response)
+					}
+				}()
+				continue
+			case methodNotificationCancelled:
+				callsMu.Lock()
+				if cancelCall, ok := running[string(request.Params.RequestID)]; ok {
+					cancelCall(errRequestCancelled)
+				}
+				callsMu.Unlock()
+				continue
+			}
+			if subscriptions != nil {
+				if response, handled := subscriptions.HandleMessage(ctx, message); handled {
+					write(response)
+					continue
+				}
+			}
+			if response := s.HandleMessage(ctx, message); response != nil {
+				write(response)
+			}
+		}
+	}
+}
+
+// stdioSession is the client session of the stdio transport, which has a single client
+type stdioSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *stdioSession) SessionID() string { return "stdio" }
+
+func (s *stdioSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func (s *stdioSession) Initialize() {}
+
+func (s *stdioSession) Initialized() bool { return true }
+
+// requireBearerToken rejects requests that do not carry the shared token.
+// An empty token disables the check.
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="bizflycloud-mcp"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// StreamableHTTPHandler serves the MCP streamable HTTP transport: every
+// JSON-RPC message is POSTed to a single endpoint and answered either with a
+// JSON body or, when the client accepts it, with an SSE stream that carries the
+// notifications emitted while the request runs followed by the response.
+// Every message but initialize must carry the session initialize assigned;
+// sessions end when deleted or after idleTimeout without requests.
+type StreamableHTTPHandler struct {
+	server      *server.MCPServer
+	sessions    sync.Map // session ID -> time of its last request
+	idleTimeout time.Duration
+	now         func() time.Time
+}
+
+// NewStreamableHTTPHandler creates a streamable HTTP handler for the MCP server
+func NewStreamableHTTPHandler(s *server.MCPServer) *StreamableHTTPHandler {
+	return &StreamableHTTPHandler{server: s, idleTimeout: defaultSessionIdleTimeout, now: time.Now}
+}
+
+// ServeHTTP implements http.Handler
+func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.handlePost(w, r)
+	case http.MethodDelete:
+		sessionID := r.Header.Get(sessionIDHeader)
+		if _, ok := h.sessions.LoadAndDelete(sessionID); !ok {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *StreamableHTTPHandler) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageBytes+1))
+	if err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, nil, mcp.PARSE_ERROR, "Failed to read request body")
+		return
+	}
+	if len(body) > maxMessageBytes {
+		writeJSONRPCError(w, http.StatusRequestEntityTooLarge, nil, mcp.INVALID_REQUEST, "Message too large")
+		return
+	}
+
+	var base struct {
+		Method string `json:"method"`
+		ID     any    `json:"id"`
+	}
+	if err := json.Unmarshal(body, &base); err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, nil, mcp.PARSE_ERROR, "Failed to parse message")
+		return
+	}
+
+	sessionID := r.Header.Get(sessionIDHeader)
+	switch {
+	case base.Method == string(mcp.MethodInitialize):
+		h.evictIdleSessions()
+		sessionID = newSessionID()
+		h.sessions.Store(sessionID, h.now())
+	case sessionID == "":
+		writeJSONRPCError(w, http.StatusBadRequest, base.ID, mcp.INVALID_REQUEST, "Missing "+sessionIDHeader+" header; initialize a session first")
+		return
+	case !h.touchSession(sessionID):
+		writeJSONRPCError(w, http.StatusNotFound, base.ID, mcp.INVALID_REQUEST, "Unknown or expired session")
+		return
+	}
+	w.Header().Set(sessionIDHeader, sessionID)
+
+	session := &httpSession{
+		id:            sessionID,
+		notifications: make(chan mcp.JSONRPCNotification, 100),
+	}
+	ctx := h.server.WithContext(r.Context(), session)
+
+	// Notifications and client responses don't produce a reply
+	if base.ID == nil {
+		h.server.HandleMessage(ctx, body)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	flusher, canStream := w.(http.Flusher)
+	if !canStream || !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		response := h.server.HandleMessage(ctx, body)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	done := make(chan mcp.JSONRPCMessage, 1)
+	go func() {
+		done <- h.server.HandleMessage(ctx, body)
+	}()
+
+	for {
+		select {
+		case notification := <-session.notifications:
+			writeSSEEvent(w, notification)
+			flusher.Flush()
+		case response := <-done:
+			// Flush anything emitted right before the handler returned
+			for len(session.notifications) > 0 {
+				writeSSEEvent(w, <-session.notifications)
+			}
+			writeSSEEvent(w, response)
+			flusher.Flush()
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// touchSession records a request of the session, reporting false when the
+// session is unknown or has been idle too long
+func (h *StreamableHTTPHandler) touchSession(sessionID string) bool {
+	lastSeen, ok := h.sessions.Load(sessionID)
+	if !ok {
+		return false
+	}
+	if h.now().Sub(lastSeen.(time.Time)) > h.idleTimeout {
+		h.sessions.Delete(sessionID)
+		return false
+	}
+	h.sessions.Store(sessionID, h.now())
+	return true
+}
+
+// evictIdleSessions forgets the sessions that have been idle too long. It runs
+// whenever a session starts, so abandoned sessions don't pile up.
+func (h *StreamableHTTPHandler) evictIdleSessions() {
+	now := h.now()
+	h.sessions.Range(func(id, lastSeen any) bool {
+		if now.Sub(lastSeen.(time.Time)) > h.idleTimeout {
+			h.sessions.Delete(id)
+		}
+		return true
+	})
+}
+
+// httpSession is the per-request client session handed to the MCP server so
+// tool handlers can emit notifications over the streamable HTTP transport
+type httpSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *httpSession) SessionID() string { return s.id }
+
+func (s *httpSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func (s *httpSession) Initialize() {}
+
+func (s *httpSession) Initialized() bool { return true }
+
+func writeSSEEvent(w io.Writer, message any) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[ERROR] Failed to encode SSE event: %v", err)
+		return
+	}
+	fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+}
+
+func writeJSONRPCError(w http.ResponseWriter, status int, id any, code int, message string) {
+	response := mcp.NewJSONRPCError(id, code, message, nil)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const initializeMessage = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}`
+
+func postMCP(t *testing.T, url, sessionID, accept, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if sessionID != "" {
+		req.Header.Set(sessionIDHeader, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	return resp
+}
+
+func TestTransportConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     TransportConfig
+		wantErr bool
+	}{
+		{"stdio", TransportConfig{Mode: transportStdio}, false},
+		{"sse", TransportConfig{Mode: transportSSE}, false},
+		{"http with tls", TransportConfig{Mode: transportHTTP, TLSCertFile: "cert.pem", TLSKeyFile: "key.pem"}, false},
+		{"unknown transport", TransportConfig{Mode: "websocket"}, true},
+		{"tls cert without key", TransportConfig{Mode: transportHTTP, TLSCertFile: "cert.pem"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStreamableHTTPHandler(t *testing.T) {
+	s := createTestMCPServer()
+	ts := httptest.NewServer(NewStreamableHTTPHandler(s))
+	defer ts.Close()
+
+	t.Run("initialize assigns a session", func(t *testing.T) {
+		resp := postMCP(t, ts.URL, "", "application/json", initializeMessage)
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get(sessionIDHeader) == "" {
+			t.Error("Expected a session ID header")
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if _, ok := body["result"]; !ok {
+			t.Errorf("Expected a result, got %v", body)
+		}
+	})
+
+	initialized := postMCP(t, ts.URL, "", "", initializeMessage)
+	initialized.Body.Close()
+	session := initialized.Header.Get(sessionIDHeader)
+
+	t.Run("notification is accepted", func(t *testing.T) {
+		resp := postMCP(t, ts.URL, session, "", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusAccepted {
+			t.Errorf("Expected status 202, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("messages without a session are rejected", func(t *testing.T) {
+		for _, body := range []string{`{"jsonrpc":"2.0","id":2,"method":"ping"}`, `{"jsonrpc":"2.0","method":"notifications/initialized"}`} {
+			resp := postMCP(t, ts.URL, "", "", body)
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", body, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("unknown session is rejected", func(t *testing.T) {
+		resp := postMCP(t, ts.URL, "does-not-exist", "", `{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("event stream response", func(t *testing.T) {
+		resp := postMCP(t, ts.URL, session, "application/json, text/event-stream", `{"jsonrpc":"2.0","id":3,"method":"ping"}`)
+		defer resp.Body.Close()
+
+		if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+			t.Fatalf("Expected an event stream, got %s", resp.Header.Get("Content-Type"))
+		}
+		scanner := bufio.NewScanner(resp.Body)
+		found := false
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.HasPrefix(line, "data: ") && strings.Contains(line, `"id":3`) {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("Expected the ping response in the event stream")
+		}
+	})
+
+	t.Run("session can be deleted", func(t *testing.T) {
+		resp := postMCP(t, ts.URL, "", "", initializeMessage)
+		resp.Body.Close()
+		sessionID := resp.Header.Get(sessionIDHeader)
+
+		req, _ := http.NewRequest(http.MethodDelete, ts.URL, nil)
+		req.Header.Set(sessionIDHeader, sessionID)
+		delResp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		delResp.Body.Close()
+		if delResp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", delResp.StatusCode)
+		}
+		resp = postMCP(t, ts.URL, sessionID, "", `{"jsonrpc":"2.0","id":4,"method":"ping"}`)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected a deleted session to be rejected with 404, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestStreamableHTTPSessionExpiry(t *testing.T) {
+	handler := NewStreamableHTTPHandler(createTestMCPServer())
+	now := time.Now()
+	handler.now = func() time.Time { return now }
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+	start := func() string {
+		resp := postMCP(t, ts.URL, "", "", initializeMessage)
+		resp.Body.Close()
+		return resp.Header.Get(sessionIDHeader)
+	}
+	ping := func(sessionID string) int {
+		resp := postMCP(t, ts.URL, sessionID, "", `{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	active, idle := start(), start()
+	// Requests keep a session alive
+	for i := 0; i < 3; i++ {
+		now = now.Add(defaultSessionIdleTimeout / 2)
+		if status := ping(active); status != http.StatusOK {
+			t.Fatalf("Expected the active session to be kept, got %d", status)
+		}
+	}
+	if status := ping(idle); status != http.StatusNotFound {
+		t.Errorf("Expected the idle session to expire, got %d", status)
+	}
+
+	// Starting a session forgets the idle ones nobody uses again
+	abandoned := start()
+	now = now.Add(defaultSessionIdleTimeout + time.Second)
+	start()
+	if _, ok := handler.sessions.Load(abandoned); ok {
+		t.Error("Expected the abandoned session to be evicted")
+	}
+}
+
+func TestRequireBearerToken(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		want   int
+	}{
+		{"no token configured", "", "", http.StatusOK},
+		{"missing header", "secret", "", http.StatusUnauthorized},
+		{"wrong token", "secret", "Bearer nope", http.StatusUnauthorized},
+		{"valid token", "secret", "Bearer secret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			requireBearerToken(tt.token, ok).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+// stdioConn is the client end of a serveStdio connection, sending and reading raw lines
+type stdioConn struct {
+	in        *io.PipeWriter
+	responses chan map[string]interface{}
+}
+
+// startStdio serves s over pipes until the test ends
+func startStdio(t *testing.T, s *server.MCPServer) *stdioConn {
+	t.Helper()
+	serverReader, clientWriter := io.Pipe()
+	clientReader, serverWriter := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		_ = serveStdio(ctx, s, nil, serverReader, serverWriter)
+		serverWriter.Close()
+	}()
+	conn := &stdioConn{in: clientWriter, responses: make(chan map[string]interface{}, 10)}
+	go func() {
+		scanner := bufio.NewScanner(clientReader)
+		for scanner.Scan() {
+			var response map[string]interface{}
+			if json.Unmarshal(scanner.Bytes(), &response) == nil {
+				conn.responses <- response
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		cancel()
+		clientWriter.Close()
+		<-stopped
+	})
+	return conn
+}
+
+// send writes a message to the server
+func (c *stdioConn) send(t *testing.T, message string) {
+	t.Helper()
+	if _, err := io.WriteString(c.in, message+"\n"); err != nil {
+		t.Fatalf("Failed to send %s: %v", message, err)
+	}
+}
+
+// next returns the next response, failing after a second
+func (c *stdioConn) next(t *testing.T) map[string]interface{} {
+	t.Helper()
+	select {
+	case response := <-c.responses:
+		return response
+	case <-time.After(time.Second):
+		t.Fatal("Expected a response")
+		return nil
+	}
+}
+
+func TestStdioToolCallsRunConcurrently(t *testing.T) {
+	started := make(chan struct{}, 1)
+	stopped := make(chan error, 1)
+	s := server.NewMCPServer("BizflyCloud MCP Test", "1.0.0")
+	s.AddTool(mcp.NewTool("slow"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		stopped <- context.Cause(ctx)
+		return mcp.NewToolResultError("cancelled"), nil
+	})
+	conn := startStdio(t, s)
+	conn.send(t, initializeMessage)
+	conn.next(t)
+
+	conn.send(t, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"slow","arguments":{}}}`)
+	<-started
+	// The running call holds up no other request
+	conn.send(t, `{"jsonrpc":"2.0","id":3,"method":"ping"}`)
+	if response := conn.next(t); response["id"] != float64(3) {
+		t.Fatalf("Expected the ping to be answered while the call runs, got %v", response)
+	}
+
+	conn.send(t, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":2,"reason":"no longer needed"}}`)
+	select {
+	case cause := <-stopped:
+		if !errors.Is(cause, errRequestCancelled) {
+			t.Errorf("Expected the call to be cancelled by the client, got %v", cause)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected notifications/cancelled to cancel the call")
+	}
+	// The cancelled call isn't answered
+	conn.send(t, `{"jsonrpc":"2.0","id":4,"method":"ping"}`)
+	if response := conn.next(t); response["id"] != float64(4) {
+		t.Errorf("Expected no response to the cancelled call, got %v", response)
+	}
+}
@@ -16,19 +16,19 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 	// List volume types tool
 	listVolumeTypesTool := mcp.NewTool("bizflycloud_list_volume_types",
 		mcp.WithDescription("List all available Bizfly Cloud volume types"),
+		withCommonOptions(),
 	)
 	s.AddTool(listVolumeTypesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		volumes, err := client.CloudServer.Volumes().List(ctx, &gobizfly.VolumeListOptions{})
+		services := servicesFromContext(ctx, client)
+		volumeTypes, err := catalogLookup(ctx, catalogFromContext(ctx), clientFromContext(ctx, client), catalogVolumeTypes, volumeTypesInUse(services.Volumes))
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list volumes: %v", err)), nil
+			return errorResult(ctx, "Failed to list volumes", err), nil
 		}
 
 		// Collect unique volume types from existing volumes
 		volumeTypesMap := make(map[string]bool)
-		for _, volume := range volumes {
-			if volume.VolumeType != "" {
-				volumeTypesMap[volume.VolumeType] = true
-			}
+		for _, vType := range volumeTypes {
+			volumeTypesMap[vType] = true
 		}
 
 		// Common volume types (in case no volumes exist yet)
@@ -50,29 +50,42 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 		}
 
 		// Display SSD types first
+		items := []VolumeTypeOutput{}
 		for _, vType := range ssdTypes {
+			items = append(items, VolumeTypeOutput{Name: vType, SSD: true, Default: vType == "PREMIUM-SSD1"})
 			result += fmt.Sprintf("  - %s (SSD)\n", vType)
 		}
 		for _, vType := range otherTypes {
+			items = append(items, VolumeTypeOutput{Name: vType})
 			result += fmt.Sprintf("  - %s\n", vType)
 		}
 
 		result += fmt.Sprintf("\nDefault: PREMIUM-SSD1 (SSD)\n")
-		return mcp.NewToolResultText(result), nil
+		return listResult(request, result, "volume_types", items), nil
 	})
 
 	// List volumes tool
 	listVolumesTool := mcp.NewTool("bizflycloud_list_volumes",
 		mcp.WithDescription("List all Bizfly Cloud volumes"),
+		withCommonOptions(),
+		withListOptions(),
 	)
 	s.AddTool(listVolumesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		volumes, err := client.CloudServer.Volumes().List(ctx, &gobizfly.VolumeListOptions{})
+		services := servicesFromContext(ctx, client)
+		query, err := parseListQuery(request.Params.Arguments)
+		if err != nil {
+			return errorResult(ctx, "Failed to list volumes", err), nil
+		}
+		volumes, err := services.Volumes.List(ctx, &gobizfly.VolumeListOptions{})
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list volumes: %v", err)), nil
+			return errorResult(ctx, "Failed to list volumes", err), nil
 		}
+		volumes, page := pageItems(query, volumes, volumeListFields)
 
 		result := "Available volumes:\n\n"
+		items := []VolumeOutput{}
 		for _, volume := range volumes {
+			items = append(items, newVolumeOutput(volume))
 			result += fmt.Sprintf("Volume: %s\n", volume.Name)
 			result += fmt.Sprintf("  ID: %s\n", volume.ID)
 			result += fmt.Sprintf("  Status: %s\n", volume.Status)
@@ -83,12 +96,14 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 			result += fmt.Sprintf("  Updated At: %s\n", volume.UpdatedAt)
 			result += "\n"
 		}
-		return mcp.NewToolResultText(result), nil
+		return pageResult(request, result, "volumes", items, page), nil
 	})
 
 	// Create volume tool
 	createVolumeTool := mcp.NewTool("bizflycloud_create_volume",
 		mcp.WithDescription("Create a new Bizfly Cloud volume"),
+		withCommonOptions(),
+		withDryRun(),
 		mcp.WithString("name",
 			mcp.Required(),
 			mcp.Description("Name of the volume"),
@@ -103,6 +118,7 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(createVolumeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		name, ok := request.Params.Arguments["name"].(string)
 		if !ok {
 			return nil, errors.New("name must be a string")
@@ -116,26 +132,33 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 			return nil, errors.New("volume_type must be a string")
 		}
 
-		volume, err := client.CloudServer.Volumes().Create(ctx, &gobizfly.VolumeCreateRequest{
+		createReq := &gobizfly.VolumeCreateRequest{
 			Name:       name,
 			Size:       int(size),
 			VolumeType: volumeType,
-		})
+		}
+		if isDryRun(request) {
+			return dryRunResult(request, "bizflycloud_create_volume", "", createReq, validateVolumeCreate(createReq)), nil
+		}
+
+		volume, err := services.Volumes.Create(ctx, createReq)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create volume: %v", err)), nil
+			return errorResult(ctx, "Failed to create volume", err), nil
 		}
+		recordTask(ctx, services, TaskSpec{Action: "create", ResourceType: "volume", ResourceID: volume.ID, ResourceName: volume.Name, Done: []string{"available"}})
 
 		result := fmt.Sprintf("Volume created successfully:\n")
 		result += fmt.Sprintf("  Name: %s\n", volume.Name)
 		result += fmt.Sprintf("  ID: %s\n", volume.ID)
 		result += fmt.Sprintf("  Size: %d GB\n", volume.Size)
 		result += fmt.Sprintf("  Type: %s\n", volume.VolumeType)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newVolumeOutput(volume)), nil
 	})
 
 	// Resize volume tool
 	resizeVolumeTool := mcp.NewTool("bizflycloud_resize_volume",
 		mcp.WithDescription("Resize a Bizfly Cloud volume"),
+		withCommonOptions(),
 		mcp.WithString("volume_id",
 			mcp.Required(),
 			mcp.Description("ID of the volume to resize"),
@@ -146,6 +169,7 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(resizeVolumeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		volumeID, ok := request.Params.Arguments["volume_id"].(string)
 		if !ok {
 			return nil, errors.New("volume_id must be a string")
@@ -155,46 +179,64 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 			return nil, errors.New("new_size must be a number")
 		}
 
-		_, err := client.CloudServer.Volumes().ExtendVolume(ctx, volumeID, int(newSize))
+		task, err := services.Volumes.ExtendVolume(ctx, volumeID, int(newSize))
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to resize volume: %v", err)), nil
+			return errorResult(ctx, "Failed to resize volume", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Volume %s resized to %d GB successfully", volumeID, int(newSize))), nil
+		recordTask(ctx, services, TaskSpec{Action: "resize", ResourceType: "volume", ResourceID: volumeID, APITaskID: task.TaskID, Done: []string{"available", "in-use"}})
+		return actionResult(request, fmt.Sprintf("Volume %s resized to %d GB successfully", volumeID, int(newSize)), ActionOutput{
+			Action: "resize", ResourceType: "volume", ResourceID: volumeID,
+			Details: map[string]string{"size_gb": fmt.Sprint(int(newSize))},
+		}), nil
 	})
 
 	// Delete volume tool
 	deleteVolumeTool := mcp.NewTool("bizflycloud_delete_volume",
 		mcp.WithDescription("Delete a Bizfly Cloud volume"),
+		withCommonOptions(),
+		withConfirmation(),
 		mcp.WithString("volume_id",
 			mcp.Required(),
 			mcp.Description("ID of the volume to delete"),
 		),
 	)
 	s.AddTool(deleteVolumeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		volumeID, ok := request.Params.Arguments["volume_id"].(string)
 		if !ok {
 			return nil, errors.New("volume_id must be a string")
 		}
-		err := client.CloudServer.Volumes().Delete(ctx, volumeID)
+		err := services.Volumes.Delete(ctx, volumeID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete volume: %v", err)), nil
+			return errorResult(ctx, "Failed to delete volume", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Volume %s deleted successfully", volumeID)), nil
+		recordTask(ctx, services, TaskSpec{Action: "delete", ResourceType: "volume", ResourceID: volumeID, Done: []string{statusDeleted}})
+		return actionResult(request, fmt.Sprintf("Volume %s deleted successfully", volumeID), ActionOutput{Action: "delete", ResourceType: "volume", ResourceID: volumeID}), nil
 	})
 
 	// List snapshots tool
 	listSnapshotsTool := mcp.NewTool("bizflycloud_list_snapshots",
 		mcp.WithDescription("List all Bizfly Cloud volume snapshots"),
+		withCommonOptions(),
+		withListOptions(),
 	)
 	s.AddTool(listSnapshotsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
+		query, err := parseListQuery(request.Params.Arguments)
+		if err != nil {
+			return errorResult(ctx, "Failed to list snapshots", err), nil
+		}
 		opts := &gobizfly.ListSnasphotsOptions{}
-		snapshots, err := client.CloudServer.Snapshots().List(ctx, opts)
+		snapshots, err := services.Snapshots.List(ctx, opts)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list snapshots: %v", err)), nil
+			return errorResult(ctx, "Failed to list snapshots", err), nil
 		}
+		snapshots, page := pageItems(query, snapshots, snapshotListFields)
 
 		result := "Available snapshots:\n\n"
+		items := []SnapshotOutput{}
 		for _, snapshot := range snapshots {
+			items = append(items, newSnapshotOutput(snapshot))
 			result += fmt.Sprintf("Snapshot: %s\n", snapshot.Name)
 			result += fmt.Sprintf("  ID: %s\n", snapshot.ID)
 			result += fmt.Sprintf("  Status: %s\n", snapshot.Status)
@@ -202,12 +244,13 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 			result += fmt.Sprintf("  Size: %d GB\n", snapshot.Size)
 			result += "\n"
 		}
-		return mcp.NewToolResultText(result), nil
+		return pageResult(request, result, "snapshots", items, page), nil
 	})
 
 	// Create snapshot tool
 	createSnapshotTool := mcp.NewTool("bizflycloud_create_snapshot",
 		mcp.WithDescription("Create a snapshot of a Bizfly Cloud volume"),
+		withCommonOptions(),
 		mcp.WithString("volume_id",
 			mcp.Required(),
 			mcp.Description("ID of the volume to snapshot"),
@@ -218,6 +261,7 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(createSnapshotTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		volumeID, ok := request.Params.Arguments["volume_id"].(string)
 		if !ok {
 			return nil, errors.New("volume_id must be a string")
@@ -227,13 +271,14 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 			return nil, errors.New("name must be a string")
 		}
 
-		snapshot, err := client.CloudServer.Snapshots().Create(ctx, &gobizfly.SnapshotCreateRequest{
+		snapshot, err := services.Snapshots.Create(ctx, &gobizfly.SnapshotCreateRequest{
 			VolumeID: volumeID,
 			Name:     name,
 		})
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create snapshot: %v", err)), nil
+			return errorResult(ctx, "Failed to create snapshot", err), nil
 		}
+		recordTask(ctx, services, TaskSpec{Action: "create", ResourceType: "snapshot", ResourceID: snapshot.ID, ResourceName: snapshot.Name, Done: []string{"available"}})
 
 		result := fmt.Sprintf("Snapshot created successfully:\n")
 		result += fmt.Sprintf("  Name: %s\n", snapshot.Name)
@@ -241,45 +286,51 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 		result += fmt.Sprintf("  Status: %s\n", snapshot.Status)
 		result += fmt.Sprintf("  Volume ID: %s\n", snapshot.VolumeID)
 		result += fmt.Sprintf("  Size: %d GB\n", snapshot.Size)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newSnapshotOutput(snapshot)), nil
 	})
 
 	// Delete snapshot tool
 	deleteSnapshotTool := mcp.NewTool("bizflycloud_delete_snapshot",
 		mcp.WithDescription("Delete a Bizfly Cloud volume snapshot"),
+		withCommonOptions(),
+		withConfirmation(),
 		mcp.WithString("snapshot_id",
 			mcp.Required(),
 			mcp.Description("ID of the snapshot to delete"),
 		),
 	)
 	s.AddTool(deleteSnapshotTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		snapshotID, ok := request.Params.Arguments["snapshot_id"].(string)
 		if !ok {
 			return nil, errors.New("snapshot_id must be a string")
 		}
-		err := client.CloudServer.Snapshots().Delete(ctx, snapshotID)
+		err := services.Snapshots.Delete(ctx, snapshotID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete snapshot: %v", err)), nil
+			return errorResult(ctx, "Failed to delete snapshot", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Snapshot %s deleted successfully", snapshotID)), nil
+		recordTask(ctx, services, TaskSpec{Action: "delete", ResourceType: "snapshot", ResourceID: snapshotID, Done: []string{statusDeleted}})
+		return actionResult(request, fmt.Sprintf("Snapshot %s deleted successfully", snapshotID), ActionOutput{Action: "delete", ResourceType: "snapshot", ResourceID: snapshotID}), nil
 	})
 
 	// Get volume tool
 	getVolumeTool := mcp.NewTool("bizflycloud_get_volume",
 		mcp.WithDescription("Get details of a Bizfly Cloud volume"),
+		withCommonOptions(),
 		mcp.WithString("volume_id",
 			mcp.Required(),
 			mcp.Description("ID of the volume to get details for"),
 		),
 	)
 	s.AddTool(getVolumeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		volumeID, ok := request.Params.Arguments["volume_id"].(string)
 		if !ok {
 			return nil, errors.New("volume_id must be a string")
 		}
-		volume, err := client.CloudServer.Volumes().Get(ctx, volumeID)
+		volume, err := services.Volumes.Get(ctx, volumeID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get volume: %v", err)), nil
+			return errorResult(ctx, "Failed to get volume", err), nil
 		}
 
 		result := fmt.Sprintf("Volume Details:\n\n")
@@ -298,12 +349,13 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 		}
 		result += fmt.Sprintf("Created At: %s\n", volume.CreatedAt)
 		result += fmt.Sprintf("Updated At: %s\n", volume.UpdatedAt)
-		return mcp.NewToolResultText(result), nil
+		return formatResult(request, result, newVolumeOutput(volume)), nil
 	})
 
 	// Attach volume tool
 	attachVolumeTool := mcp.NewTool("bizflycloud_attach_volume",
 		mcp.WithDescription("Attach a Bizfly Cloud volume to a server"),
+		withCommonOptions(),
 		mcp.WithString("volume_id",
 			mcp.Required(),
 			mcp.Description("ID of the volume to attach"),
@@ -314,6 +366,7 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(attachVolumeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		volumeID, ok := request.Params.Arguments["volume_id"].(string)
 		if !ok {
 			return nil, errors.New("volume_id must be a string")
@@ -322,16 +375,21 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 		if !ok {
 			return nil, errors.New("server_id must be a string")
 		}
-		_, err := client.CloudServer.Volumes().Attach(ctx, volumeID, serverID)
+		_, err := services.Volumes.Attach(ctx, volumeID, serverID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to attach volume: %v", err)), nil
+			return errorResult(ctx, "Failed to attach volume", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Volume %s attached to server %s successfully", volumeID, serverID)), nil
+		recordTask(ctx, services, TaskSpec{Action: "attach", ResourceType: "volume", ResourceID: volumeID, Done: []string{"in-use"}})
+		return actionResult(request, fmt.Sprintf("Volume %s attached to server %s successfully", volumeID, serverID), ActionOutput{
+			Action: "attach", ResourceType: "volume", ResourceID: volumeID,
+			Details: map[string]string{"server_id": serverID},
+		}), nil
 	})
 
 	// Detach volume tool
 	detachVolumeTool := mcp.NewTool("bizflycloud_detach_volume",
 		mcp.WithDescription("Detach a Bizfly Cloud volume from a server"),
+		withCommonOptions(),
 		mcp.WithString("volume_id",
 			mcp.Required(),
 			mcp.Description("ID of the volume to detach"),
@@ -342,6 +400,7 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 		),
 	)
 	s.AddTool(detachVolumeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		services := servicesFromContext(ctx, client)
 		volumeID, ok := request.Params.Arguments["volume_id"].(string)
 		if !ok {
 			return nil, errors.New("volume_id must be a string")
@@ -350,10 +409,76 @@ func RegisterVolumeTools(s *server.MCPServer, client *gobizfly.Client) {
 		if !ok {
 			return nil, errors.New("server_id must be a string")
 		}
-		_, err := client.CloudServer.Volumes().Detach(ctx, volumeID, serverID)
+		_, err := services.Volumes.Detach(ctx, volumeID, serverID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to detach volume: %v", err)), nil
+			return errorResult(ctx, "Failed to detach volume", err), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Volume %s detached from server %s successfully", volumeID, serverID)), nil
+		recordTask(ctx, services, TaskSpec{Action: "detach", ResourceType: "volume", ResourceID: volumeID, Done: []string{"available"}})
+		return actionResult(request, fmt.Sprintf("Volume %s detached from server %s successfully", volumeID, serverID), ActionOutput{
+			Action: "detach", ResourceType: "volume", ResourceID: volumeID,
+			Details: map[string]string{"server_id": serverID},
+		}), nil
 	})
-} 
\ No newline at end of file
+}
+
+// previewVolumeDeletion describes the volume and the servers it is attached to
+func previewVolumeDeletion(ctx context.Context, services *Services, args map[string]interface{}) (string, error) {
+	volumeID, err := stringArg(args, "volume_id")
+	if err != nil {
+		return "", err
+	}
+	volume, err := services.Volumes.Get(ctx, volumeID)
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("Volume: %s\n", volume.Name)
+	result += fmt.Sprintf("  ID: %s\n", volume.ID)
+	result += fmt.Sprintf("  Status: %s\n", volume.Status)
+	result += fmt.Sprintf("  Size: %d GB\n", volume.Size)
+	result += fmt.Sprintf("  Type: %s\n", volume.VolumeType)
+	for _, attachment := range volume.Attachments {
+		result += fmt.Sprintf("  Attached To Server: %s (%s)\n", attachment.ServerID, attachment.Device)
+	}
+	result += fmt.Sprintf("  Created At: %s\n", volume.CreatedAt)
+	return result, nil
+}
+
+// previewSnapshotDeletion describes the snapshot and the volume it was taken from
+func previewSnapshotDeletion(ctx context.Context, services *Services, args map[string]interface{}) (string, error) {
+	snapshotID, err := stringArg(args, "snapshot_id")
+	if err != nil {
+		return "", err
+	}
+	snapshot, err := services.Snapshots.Get(ctx, snapshotID)
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("Snapshot: %s\n", snapshot.Name)
+	result += fmt.Sprintf("  ID: %s\n", snapshot.ID)
+	result += fmt.Sprintf("  Status: %s\n", snapshot.Status)
+	result += fmt.Sprintf("  Size: %d GB\n", snapshot.Size)
+	result += fmt.Sprintf("  Volume ID: %s\n", snapshot.VolumeID)
+	result += fmt.Sprintf("  Created At: %s\n", snapshot.CreateAt)
+	return result, nil
+}
+
+// validateVolumeCreate checks a volume create request before it is sent
+func validateVolumeCreate(req *gobizfly.VolumeCreateRequest) *requestValidation {
+	v := &requestValidation{}
+	v.check(req.Name != "", "name is set")
+	v.check(req.Size > 0, "size is positive")
+	v.check(req.VolumeType != "", "volume type is set")
+	return v
+}
+
+// volumeListFields are the fields list_volumes filters and sorts on
+func volumeListFields(volume *gobizfly.Volume) listFields {
+	return listFields{Name: volume.Name, Status: volume.Status, CreatedAt: volume.CreatedAt}
+}
+
+// snapshotListFields are the fields list_snapshots filters and sorts on
+func snapshotListFields(snapshot *gobizfly.Snapshot) listFields {
+	return listFields{Name: snapshot.Name, Status: snapshot.Status, CreatedAt: snapshot.CreateAt}
+}
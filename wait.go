@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Arguments of the create tools that can wait for what they create to be ready
+const (
+	waitArg        = "wait"
+	waitTimeoutArg = "wait_timeout"
+)
+
+const (
+	defaultWaitTimeout = 10 * time.Minute
+	maxWaitTimeout     = time.Hour
+)
+
+// waitPollInterval is how often a waiting create tool polls the resource
+var waitPollInterval = 5 * time.Second
+
+// withWait adds the wait and wait_timeout arguments
+func withWait() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithBoolean(waitArg,
+			mcp.Description("Wait until the resource is ready or has failed, sending progress notifications, and return its IPs and endpoints (default: false)"),
+		)(t)
+		mcp.WithNumber(waitTimeoutArg,
+			mcp.Description(fmt.Sprintf("Seconds to wait when wait is set (default: %d, at most %d)", int(defaultWaitTimeout.Seconds()), int(maxWaitTimeout.Seconds()))),
+		)(t)
+	}
+}
+
+// waitTimeout returns how long the tool call asked to wait, or 0 if it didn't ask to
+func waitTimeout(request mcp.CallToolRequest) (time.Duration, error) {
+	if wait, _ := request.Params.Arguments[waitArg].(bool); !wait {
+		return 0, nil
+	}
+	value, ok := request.Params.Arguments[waitTimeoutArg]
+	if !ok {
+		return defaultWaitTimeout, nil
+	}
+	seconds, ok := value.(float64)
+	if !ok || seconds <= 0 || time.Duration(seconds)*time.Second > maxWaitTimeout {
+		return 0, invalidArgument(fmt.Errorf("%s must be a number of seconds between 1 and %d", waitTimeoutArg, int(maxWaitTimeout.Seconds())))
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// statusPoll polls a resource's status until it is one of the ready or failed
+// statuses, or the timeout passes. Statuses are compared case-insensitively.
+type statusPoll struct {
+	Kind     string // e.g. "server", used in progress messages
+	Name     string
+	Get      func(ctx context.Context) (string, error)
+	Ready    []string
+	Failed   []string
+	Timeout  time.Duration
+	Interval time.Duration
+}
+
+// WaitOutput is the JSON schema of how a wait for a resource ended
+type WaitOutput struct {
+	Status         string                   `json:"status"`
+	Ready          bool                     `json:"ready"`
+	Failed         bool                     `json:"failed"`
+	TimedOut       bool                     `json:"timed_out"`
+	ElapsedSeconds float64                  `json:"elapsed_seconds"`
+	Transitions    []StatusTransitionOutput `json:"transitions"`
+}
+
+// StatusTransitionOutput is the JSON schema of a status a wait observed and when it first saw it
+type StatusTransitionOutput struct {
+	Status string `json:"status"`
+	At     string `json:"at"`
+}
+
+// Run polls until the wait is over. It only returns an error when the status
+// couldn't be read; a timeout is reported in the output.
+func (p statusPoll) Run(ctx context.Context, progress *progressReporter) (WaitOutput, error) {
+	out := WaitOutput{Transitions: []StatusTransitionOutput{}}
+	start := time.Now()
+	deadline := start.Add(p.Timeout)
+	for {
+		status, err := p.Get(ctx)
+		if err != nil {
+			return out, err
+		}
+		now := time.Now()
+		out.ElapsedSeconds = now.Sub(start).Round(time.Millisecond).Seconds()
+		if status != out.Status || len(out.Transitions) == 0 {
+			out.Transitions = append(out.Transitions, StatusTransitionOutput{Status: status, At: now.UTC().Format(time.RFC3339)})
+		}
+		out.Status = status
+		out.Ready = statusIn(status, p.Ready)
+		out.Failed = statusIn(status, p.Failed)
+		progress.Report(fmt.Sprintf("%s %s is %s after %s", p.Kind, p.Name, status, now.Sub(start).Round(time.Second)))
+		if out.Ready || out.Failed {
+			return out, nil
+		}
+		if !now.Add(p.Interval).Before(deadline) {
+			out.TimedOut = true
+			return out, nil
+		}
+
+		timer := time.NewTimer(p.Interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return out, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// statusIn reports whether status is one of statuses, ignoring case
+func statusIn(status string, statuses []string) bool {
+	for _, s := range statuses {
+		if strings.EqualFold(status, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeWait summarizes how a wait ended for the text result
+func describeWait(kind string, wait WaitOutput) string {
+	result := describeTransitions(wait)
+	switch {
+	case wait.Ready:
+		result += fmt.Sprintf("The %s is ready.\n", kind)
+	case wait.Failed:
+		result += fmt.Sprintf("The %s failed with status %s.\n", kind, wait.Status)
+	case wait.TimedOut:
+		result += fmt.Sprintf("Stopped waiting while the %s is still %s; it keeps provisioning.\n", kind, wait.Status)
+	}
+	return result
+}
+
+// describeTransitions lists the statuses a wait saw and how long it took
+func describeTransitions(wait WaitOutput) string {
+	var statuses []string
+	for _, transition := range wait.Transitions {
+		statuses = append(statuses, transition.Status)
+	}
+	return fmt.Sprintf("\nWaited %.0fs: %s\n", wait.ElapsedSeconds, strings.Join(statuses, " -> "))
+}
+
+// progressReporter sends notifications/progress for a tool call whose client
+// asked for them by giving a progress token. It does nothing otherwise.
+type progressReporter struct {
+	ctx      context.Context
+	server   *server.MCPServer
+	token    mcp.ProgressToken
+	progress float64
+}
+
+func newProgressReporter(ctx context.Context, request mcp.CallToolRequest) *progressReporter {
+	r := &progressReporter{ctx: ctx, server: server.ServerFromContext(ctx)}
+	if request.Params.Meta != nil {
+		r.token = request.Params.Meta.ProgressToken
+	}
+	return r
+}
+
+// Report sends the next progress notification with the message. Delivery is
+// best effort: a client that went away doesn't fail the tool call.
+func (r *progressReporter) Report(message string) {
+	if r == nil || r.server == nil || r.token == nil {
+		return
+	}
+	r.progress++
+	_ = r.server.SendNotificationToClient(r.ctx, "notifications/progress", map[string]any{
+		"progressToken": r.token,
+		"progress":      r.progress,
+		"message":       message,
+	})
+}
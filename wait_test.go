@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// fastWaits makes waiting tools poll without delay for the rest of the test
+func fastWaits(t *testing.T) {
+	t.Helper()
+	previous := waitPollInterval
+	waitPollInterval = time.Millisecond
+	t.Cleanup(func() { waitPollInterval = previous })
+}
+
+func TestWaitTimeout(t *testing.T) {
+	tests := []struct {
+		args    map[string]interface{}
+		want    time.Duration
+		wantErr bool
+	}{
+		{map[string]interface{}{}, 0, false},
+		{map[string]interface{}{"wait": false, "wait_timeout": float64(30)}, 0, false},
+		{map[string]interface{}{"wait": true}, defaultWaitTimeout, false},
+		{map[string]interface{}{"wait": true, "wait_timeout": float64(30)}, 30 * time.Second, false},
+		{map[string]interface{}{"wait": true, "wait_timeout": float64(0)}, 0, true},
+		{map[string]interface{}{"wait": true, "wait_timeout": float64(7200)}, 0, true},
+		{map[string]interface{}{"wait": true, "wait_timeout": "soon"}, 0, true},
+	}
+	for _, tt := range tests {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = tt.args
+		got, err := waitTimeout(request)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("waitTimeout(%v): unexpected error %v", tt.args, err)
+			continue
+		}
+		if err != nil && classifyError(context.Background(), err).Code != ErrorValidation {
+			t.Errorf("waitTimeout(%v): expected a validation error, got %v", tt.args, err)
+		}
+		if got != tt.want {
+			t.Errorf("waitTimeout(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestStatusPollRun(t *testing.T) {
+	statuses := []string{"BUILD", "BUILD", "active"}
+	poll := statusPoll{
+		Kind: "server", Name: "web-1",
+		Ready: []string{"ACTIVE"}, Failed: []string{"ERROR"},
+		Timeout: time.Second, Interval: time.Millisecond,
+		Get: func(ctx context.Context) (string, error) {
+			status := statuses[0]
+			if len(statuses) > 1 {
+				statuses = statuses[1:]
+			}
+			return status, nil
+		},
+	}
+	wait, err := poll.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !wait.Ready || wait.Failed || wait.TimedOut || wait.Status != "active" {
+		t.Errorf("Expected the poll to end ready, got %+v", wait)
+	}
+	if len(wait.Transitions) != 2 || wait.Transitions[0].Status != "BUILD" || wait.Transitions[1].Status != "active" {
+		t.Errorf("Expected the transitions BUILD -> active, got %+v", wait.Transitions)
+	}
+}
+
+func TestStatusPollTimeoutAndErrors(t *testing.T) {
+	poll := statusPoll{
+		Kind: "server", Name: "web-1",
+		Ready: []string{"ACTIVE"}, Failed: []string{"ERROR"},
+		Timeout: 20 * time.Millisecond, Interval: 5 * time.Millisecond,
+		Get: func(ctx context.Context) (string, error) { return "BUILD", nil },
+	}
+	wait, err := poll.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("A timeout should not be an error: %v", err)
+	}
+	if !wait.TimedOut || wait.Ready || wait.Status != "BUILD" {
+		t.Errorf("Expected the poll to time out in BUILD, got %+v", wait)
+	}
+
+	poll.Get = func(ctx context.Context) (string, error) { return "", errors.New("boom") }
+	if _, err := poll.Run(context.Background(), nil); err == nil {
+		t.Error("Expected the error of the status lookup")
+	}
+
+	poll.Get = func(ctx context.Context) (string, error) { return "BUILD", nil }
+	poll.Timeout = time.Minute
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := poll.Run(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the poll to stop when the call is cancelled, got %v", err)
+	}
+}
+
+// callWithProgress calls a tool through the harness with a progress token
+func callWithProgress(t *testing.T, h *mcpHarness, name string, token string, args map[string]interface{}) *mcp.CallToolResult {
+	t.Helper()
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+	request.Params.Meta = &struct {
+		ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
+	}{ProgressToken: token}
+	result, err := h.CallTool(h.context(t), request)
+	if err != nil {
+		t.Fatalf("tools/call %s failed: %v", name, err)
+	}
+	return result
+}
+
+// progressMessages waits briefly for the progress notifications of a token to arrive
+func progressMessages(t *testing.T, h *mcpHarness, token string, want int) []string {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		var messages []string
+		for _, notification := range h.receivedNotifications() {
+			if notification.Method != "notifications/progress" || notification.Params.AdditionalFields["progressToken"] != token {
+				continue
+			}
+			message, _ := notification.Params.AdditionalFields["message"].(string)
+			messages = append(messages, message)
+		}
+		if len(messages) >= want || time.Now().After(deadline) {
+			return messages
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCreateServerWait(t *testing.T) {
+	fastWaits(t)
+	cloud, s := newMockServer(t, testCloudState())
+	cloud.SetProvisioningReads(3)
+	h := startHarness(t, s)
+
+	result := callWithProgress(t, h, "bizflycloud_create_server", "create-web-2", map[string]interface{}{
+		"name": "web-2", "flavor_name": "nix.2c_4g", "image_id": "image-1", "wait": true, "format": "json",
+	})
+	if result.IsError {
+		t.Fatalf("create_server failed: %s", getTextFromResult(result))
+	}
+	var output ServerCreationOutput
+	if err := json.Unmarshal([]byte(getTextFromResult(result)), &output); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if output.ID == "" || output.Status != "ACTIVE" || len(output.WANIPv4) == 0 || len(output.LANIPs) == 0 {
+		t.Errorf("Expected the ID, ACTIVE status and IPs of the server, got %+v", output)
+	}
+	if output.Wait == nil || !output.Wait.Ready || len(output.Wait.Transitions) != 2 || output.Wait.Transitions[0].Status != "BUILD" {
+		t.Errorf("Expected the wait to see BUILD then ACTIVE, got %+v", output.Wait)
+	}
+
+	messages := progressMessages(t, h, "create-web-2", 3)
+	if len(messages) < 3 {
+		t.Fatalf("Expected a progress notification per poll, got %v", messages)
+	}
+	if !strings.Contains(messages[0], "web-2 is BUILD") || !strings.Contains(messages[len(messages)-1], "web-2 is ACTIVE") {
+		t.Errorf("Unexpected progress messages: %v", messages)
+	}
+}
+
+func TestCreateServerWithoutWait(t *testing.T) {
+	cloud, s := newMockServer(t, testCloudState())
+	cloud.SetProvisioningReads(3)
+
+	result := callTool(t, s, "bizflycloud_create_server", map[string]interface{}{
+		"name": "web-2", "flavor_name": "nix.2c_4g", "image_id": "image-1",
+	})
+	if result.IsError {
+		t.Fatalf("create_server failed: %s", getTextFromResult(result))
+	}
+	if text := getTextFromResult(result); strings.Contains(text, "Waited") || !strings.Contains(text, "bizflycloud_list_servers") {
+		t.Errorf("Expected the create to return without waiting, got:\n%s", text)
+	}
+	if got := cloud.State().Servers[1].Status; got != "BUILD" {
+		t.Errorf("Expected the new server to still be building, got %s", got)
+	}
+}
+
+func TestCreateKubernetesClusterWait(t *testing.T) {
+	fastWaits(t)
+	cloud, s := newMockServer(t, testCloudState())
+	cloud.SetProvisioningReads(2)
+
+	result := callTool(t, s, "bizflycloud_create_kubernetes_cluster", map[string]interface{}{
+		"name": "staging", "version": "v1.30.4", "worker_flavor": "nix.4c_8g", "worker_count": float64(2), "wait": true, "format": "json",
+	})
+	if result.IsError {
+		t.Fatalf("create_kubernetes_cluster failed: %s", getTextFromResult(result))
+	}
+	var output ClusterCreationOutput
+	if err := json.Unmarshal([]byte(getTextFromResult(result)), &output); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if output.ProvisionStatus != "PROVISIONED" || !strings.HasPrefix(output.APIEndpoint, "https://") {
+		t.Errorf("Expected a provisioned cluster with its API endpoint, got %+v", output)
+	}
+	if len(output.WorkerPools) != 1 || len(output.WorkerPools[0].Nodes) != 2 || len(output.WorkerPools[0].Nodes[0].IPAddresses) == 0 {
+		t.Errorf("Expected the two worker nodes and their IPs, got %+v", output.WorkerPools)
+	}
+}
+
+func TestCreateDatabaseWait(t *testing.T) {
+	fastWaits(t)
+	cloud, s := newMockServer(t, testCloudState())
+	cloud.SetProvisioningReads(2)
+
+	result := callTool(t, s, "bizflycloud_create_database", map[string]interface{}{
+		"name": "orders", "type": "MySQL", "version": "mysql-8.0", "flavor": "1c_2g", "volume_size": float64(20),
+		"availability_zone": "HN1", "wait": true,
+	})
+	if result.IsError {
+		t.Fatalf("create_database failed: %s", getTextFromResult(result))
+	}
+	text := getTextFromResult(result)
+	for _, want := range []string{"Private Endpoint: ", ".db.fake.bizflycloud.vn", "BUILD -> ACTIVE", "The database is ready"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestCreateDatabaseWaitFailure(t *testing.T) {
+	fastWaits(t)
+	cloud, s := newMockServer(t, testCloudState())
+	cloud.SetProvisioningReads(1)
+	cloud.FailProvisioning("broken")
+
+	result := callTool(t, s, "bizflycloud_create_database", map[string]interface{}{
+		"name": "broken", "type": "MySQL", "version": "mysql-8.0", "flavor": "1c_2g", "volume_size": float64(20),
+		"availability_zone": "HN1", "wait": true,
+	})
+	if !result.IsError {
+		t.Fatalf("Expected a database that failed to provision to be an error, got:\n%s", getTextFromResult(result))
+	}
+	if text := getTextFromResult(result); !strings.Contains(text, "failed with status ERROR") {
+		t.Errorf("Expected the failure status in:\n%s", text)
+	}
+}
+
+func TestCreateWaitTimesOut(t *testing.T) {
+	fastWaits(t)
+	waitPollInterval = 400 * time.Millisecond
+	cloud, s := newMockServer(t, testCloudState())
+	cloud.SetProvisioningReads(100)
+
+	result := callTool(t, s, "bizflycloud_create_kubernetes_cluster", map[string]interface{}{
+		"name": "slow", "version": "v1.30.4", "worker_flavor": "nix.4c_8g", "worker_count": float64(1), "wait": true, "wait_timeout": float64(1),
+	})
+	if result.IsError {
+		t.Fatalf("A timed out wait should not be an error: %s", getTextFromResult(result))
+	}
+	if text := getTextFromResult(result); !strings.Contains(text, "still PROVISIONING") {
+		t.Errorf("Expected the wait to stop while provisioning, got:\n%s", text)
+	}
+}